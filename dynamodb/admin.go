@@ -0,0 +1,397 @@
+package dy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TableAdmin defines the dynamodb client managing the tables.
+type TableAdmin interface {
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+	DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+}
+
+// TableOptions the table provisioning options.
+type TableOptions struct {
+	// BillingMode the table billing mode. Defaults to types.BillingModePayPerRequest.
+	BillingMode types.BillingMode
+	// ReadCapacity the read capacity units of the table and of its global indexes, in provisioned billing mode.
+	ReadCapacity int64
+	// WriteCapacity the write capacity units of the table and of its global indexes, in provisioned billing mode.
+	WriteCapacity int64
+	// TTLAttribute the time to live attribute name. The time to live is disabled if empty.
+	TTLAttribute string
+	// StreamViewType the stream view type. The stream is disabled if empty.
+	StreamViewType types.StreamViewType
+	// WaitTimeout the maximum duration to wait for the table and its indexes to be active. Defaults to 5m.
+	WaitTimeout time.Duration
+	// PollInterval the interval between the table status checks. Defaults to 1s.
+	PollInterval time.Duration
+}
+
+func (o TableOptions) billingMode() types.BillingMode {
+	if o.BillingMode == "" {
+		return types.BillingModePayPerRequest
+	}
+
+	return o.BillingMode
+}
+
+func (o TableOptions) throughput() *types.ProvisionedThroughput {
+	if o.billingMode() != types.BillingModeProvisioned {
+		return nil
+	}
+
+	return &types.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(o.ReadCapacity),
+		WriteCapacityUnits: aws.Int64(o.WriteCapacity),
+	}
+}
+
+func (o TableOptions) waitTimeout() time.Duration {
+	if o.WaitTimeout <= 0 {
+		return 5 * time.Minute
+	}
+
+	return o.WaitTimeout
+}
+
+func (o TableOptions) pollInterval() time.Duration {
+	if o.PollInterval <= 0 {
+		return time.Second
+	}
+
+	return o.PollInterval
+}
+
+// NewCreateTableInput builds the input creating the table described by the config.
+// The indexes project all the attributes.
+func NewCreateTableInput(conf DBConfig, opts TableOptions) (*dynamodb.CreateTableInput, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.billingMode() == types.BillingModeProvisioned && (opts.ReadCapacity <= 0 || opts.WriteCapacity <= 0) {
+		return nil, fmt.Errorf("the provisioned billing mode requires positive read and write capacities")
+	}
+
+	definitions := make(map[string]types.ScalarAttributeType)
+	keySchema := func(keys ...*DynamoKeyMetadata) ([]types.KeySchemaElement, error) {
+		var schema []types.KeySchemaElement
+		for i, key := range keys {
+			if key == nil {
+				continue
+			}
+
+			attrType, err := scalarAttributeType(key.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%w of key %q", err, key.Name)
+			}
+
+			if defined, ok := definitions[string(key.Name)]; ok && defined != attrType {
+				return nil, fmt.Errorf("%w: key %q is defined with the types %s and %s", ErrInvalidDBKeyType, key.Name, defined, attrType)
+			}
+			definitions[string(key.Name)] = attrType
+
+			keyType := types.KeyTypeHash
+			if i > 0 {
+				keyType = types.KeyTypeRange
+			}
+			schema = append(schema, types.KeySchemaElement{AttributeName: aws.String(string(key.Name)), KeyType: keyType})
+		}
+
+		return schema, nil
+	}
+
+	primaryKey := conf.TableInfo.PrimaryKey
+	tableSchema, err := keySchema(&primaryKey.PartitionKey, primaryKey.SortKey)
+	if err != nil {
+		return nil, err
+	}
+
+	in := &dynamodb.CreateTableInput{
+		TableName:             aws.String(conf.TableInfo.TableName),
+		KeySchema:             tableSchema,
+		BillingMode:           opts.billingMode(),
+		ProvisionedThroughput: opts.throughput(),
+	}
+
+	if opts.StreamViewType != "" {
+		in.StreamSpecification = &types.StreamSpecification{StreamEnabled: aws.Bool(true), StreamViewType: opts.StreamViewType}
+	}
+
+	for _, name := range sortedIndexNames(conf.Indexes) {
+		keys := conf.Indexes[name]
+		schema, err := keySchema(&keys.PartitionKey, keys.SortKey)
+		if err != nil {
+			return nil, fmt.Errorf("index %s: %w", name, err)
+		}
+
+		in.GlobalSecondaryIndexes = append(in.GlobalSecondaryIndexes, types.GlobalSecondaryIndex{
+			IndexName:             aws.String(string(name)),
+			KeySchema:             schema,
+			Projection:            &types.Projection{ProjectionType: types.ProjectionTypeAll},
+			ProvisionedThroughput: opts.throughput(),
+		})
+	}
+
+	for _, name := range sortedIndexNames(conf.LocalIndexes) {
+		sortKey := conf.LocalIndexes[name]
+		schema, err := keySchema(&primaryKey.PartitionKey, &sortKey)
+		if err != nil {
+			return nil, fmt.Errorf("index %s: %w", name, err)
+		}
+
+		in.LocalSecondaryIndexes = append(in.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+			IndexName:  aws.String(string(name)),
+			KeySchema:  schema,
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		})
+	}
+
+	for name, attrType := range definitions {
+		in.AttributeDefinitions = append(in.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(name),
+			AttributeType: attrType,
+		})
+	}
+	sort.Slice(in.AttributeDefinitions, func(i, j int) bool {
+		return aws.ToString(in.AttributeDefinitions[i].AttributeName) < aws.ToString(in.AttributeDefinitions[j].AttributeName)
+	})
+
+	return in, nil
+}
+
+// CreateTable creates the table described by the config, waits until the table and its indexes are active,
+// then enables the time to live.
+func CreateTable(ctx context.Context, client TableAdmin, conf DBConfig, opts TableOptions) error {
+	in, err := NewCreateTableInput(conf, opts)
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.CreateTable(ctx, in); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", conf.TableInfo.TableName, mapError(err))
+	}
+
+	return setupTable(ctx, client, conf, opts)
+}
+
+// EnsureTable creates the table described by the config if it does not exist. An existing table is verified
+// with VerifyTable. It waits until the table and its indexes are active, then enables the time to live.
+func EnsureTable(ctx context.Context, client TableAdmin, conf DBConfig, opts TableOptions) error {
+	err := VerifyTable(ctx, client, conf)
+
+	var notFound *types.ResourceNotFoundException
+	if errors.As(err, &notFound) {
+		return CreateTable(ctx, client, conf, opts)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return setupTable(ctx, client, conf, opts)
+}
+
+// VerifyTable validates the config and checks it against the live table, to be called at startup.
+// The key schemas of the table and of the configured indexes must match the config, a *TableMismatchError
+// listing the differences is returned otherwise.
+func VerifyTable(ctx context.Context, client TableAdmin, conf DBConfig) error {
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(conf.TableInfo.TableName)})
+	if err != nil {
+		return fmt.Errorf("failed to describe table %s: %w", conf.TableInfo.TableName, mapError(err))
+	}
+
+	if diffs := tableDiff(conf, out.Table); len(diffs) > 0 {
+		return &TableMismatchError{Table: conf.TableInfo.TableName, Differences: diffs}
+	}
+
+	return nil
+}
+
+// WaitForTable waits until the table and its global indexes are active.
+func WaitForTable(ctx context.Context, client TableAdmin, tableName string, opts TableOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, opts.waitTimeout())
+	defer cancel()
+
+	for {
+		out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("failed to describe table %s: %w", tableName, mapError(err))
+		}
+
+		if err == nil && isTableActive(out.Table) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("table %s is not active: %w", tableName, ctx.Err())
+		case <-time.After(opts.pollInterval()):
+		}
+	}
+}
+
+func isTableActive(desc *types.TableDescription) bool {
+	if desc == nil || desc.TableStatus != types.TableStatusActive {
+		return false
+	}
+
+	for _, gsi := range desc.GlobalSecondaryIndexes {
+		if gsi.IndexStatus != types.IndexStatusActive {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setupTable waits until the table is active and enables the time to live.
+func setupTable(ctx context.Context, client TableAdmin, conf DBConfig, opts TableOptions) error {
+	table := conf.TableInfo.TableName
+	if err := WaitForTable(ctx, client, table, opts); err != nil {
+		return err
+	}
+
+	if opts.TTLAttribute == "" {
+		return nil
+	}
+
+	out, err := client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(table)})
+	if err != nil {
+		return fmt.Errorf("failed to describe the time to live of table %s: %w", table, mapError(err))
+	}
+
+	if ttl := out.TimeToLiveDescription; ttl != nil {
+		switch ttl.TimeToLiveStatus {
+		case types.TimeToLiveStatusEnabled, types.TimeToLiveStatusEnabling:
+			if attr := aws.ToString(ttl.AttributeName); attr != opts.TTLAttribute {
+				return &TableMismatchError{Table: table, Differences: []string{
+					fmt.Sprintf("the time to live is enabled on attribute %q instead of %q", attr, opts.TTLAttribute),
+				}}
+			}
+			return nil
+		}
+	}
+
+	_, err = client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(table),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(opts.TTLAttribute),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable the time to live of table %s: %w", table, mapError(err))
+	}
+
+	return nil
+}
+
+// tableDiff lists the differences between the key schemas and the indexes of the config and of the table.
+func tableDiff(conf DBConfig, desc *types.TableDescription) []string {
+	definitions := make(map[string]types.ScalarAttributeType)
+	for _, def := range desc.AttributeDefinitions {
+		definitions[aws.ToString(def.AttributeName)] = def.AttributeType
+	}
+
+	var diffs []string
+	diffSchema := func(what string, want DBPrimaryKeyNames, got []types.KeySchemaElement) {
+		gotKeys := make(map[types.KeyType]string, len(got))
+		for _, e := range got {
+			gotKeys[e.KeyType] = aws.ToString(e.AttributeName)
+		}
+
+		for _, key := range []struct {
+			keyType types.KeyType
+			meta    *DynamoKeyMetadata
+		}{{types.KeyTypeHash, &want.PartitionKey}, {types.KeyTypeRange, want.SortKey}} {
+			gotName, ok := gotKeys[key.keyType]
+			switch {
+			case key.meta == nil && ok:
+				diffs = append(diffs, fmt.Sprintf("%s: unexpected %s key %q", what, key.keyType, gotName))
+			case key.meta == nil:
+			case !ok:
+				diffs = append(diffs, fmt.Sprintf("%s: missing %s key %q", what, key.keyType, key.meta.Name))
+			case gotName != string(key.meta.Name):
+				diffs = append(diffs, fmt.Sprintf("%s: %s key is %q instead of %q", what, key.keyType, gotName, key.meta.Name))
+			default:
+				if wantType, err := scalarAttributeType(key.meta.Type); err == nil && definitions[gotName] != wantType {
+					diffs = append(diffs, fmt.Sprintf("%s: key %q has type %s instead of %s", what, gotName, definitions[gotName], wantType))
+				}
+			}
+		}
+	}
+
+	diffSchema("table", conf.TableInfo.PrimaryKey, desc.KeySchema)
+
+	globals := make(map[string][]types.KeySchemaElement, len(desc.GlobalSecondaryIndexes))
+	for _, gsi := range desc.GlobalSecondaryIndexes {
+		globals[aws.ToString(gsi.IndexName)] = gsi.KeySchema
+	}
+
+	for _, name := range sortedIndexNames(conf.Indexes) {
+		schema, ok := globals[string(name)]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("missing global index %s", name))
+			continue
+		}
+		diffSchema("global index "+string(name), conf.Indexes[name], schema)
+	}
+
+	locals := make(map[string][]types.KeySchemaElement, len(desc.LocalSecondaryIndexes))
+	for _, lsi := range desc.LocalSecondaryIndexes {
+		locals[aws.ToString(lsi.IndexName)] = lsi.KeySchema
+	}
+
+	for _, name := range sortedIndexNames(conf.LocalIndexes) {
+		schema, ok := locals[string(name)]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("missing local index %s", name))
+			continue
+		}
+
+		sortKey := conf.LocalIndexes[name]
+		diffSchema("local index "+string(name), DBPrimaryKeyNames{PartitionKey: conf.TableInfo.PrimaryKey.PartitionKey, SortKey: &sortKey}, schema)
+	}
+
+	return diffs
+}
+
+// scalarAttributeType returns the attribute type of a key type.
+func scalarAttributeType(keyType DBKeyType) (types.ScalarAttributeType, error) {
+	switch keyType {
+	case String:
+		return types.ScalarAttributeTypeS, nil
+	case Number:
+		return types.ScalarAttributeTypeN, nil
+	case Binary:
+		return types.ScalarAttributeTypeB, nil
+	}
+
+	return "", ErrInvalidDBKeyType
+}
+
+func sortedIndexNames[V any](indexes map[DBIndexName]V) []DBIndexName {
+	names := make([]DBIndexName, 0, len(indexes))
+	for name := range indexes {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	return names
+}
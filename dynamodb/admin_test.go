@@ -0,0 +1,197 @@
+package dy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var adminConfig = dy.DBConfig{
+	TableInfo: dy.TableInfo{
+		TableName: "users",
+		PrimaryKey: dy.DBPrimaryKeyNames{
+			PartitionKey: dy.DynamoKeyMetadata{Name: "groupID", Type: dy.Number, NumberType: dy.NumberInt64},
+			SortKey:      &dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+		},
+	},
+	Indexes: map[dy.DBIndexName]dy.DBPrimaryKeyNames{
+		"byEmail": {PartitionKey: dy.DynamoKeyMetadata{Name: "email", Type: dy.String}},
+	},
+	LocalIndexes: map[dy.DBIndexName]dy.DynamoKeyMetadata{
+		"byAge": {Name: "age", Type: dy.Number},
+	},
+}
+
+// creatingClient reports the table as CREATING for the first describe calls.
+type creatingClient struct {
+	*memdb.Client
+	creating int
+}
+
+func (c *creatingClient) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	out, err := c.Client.DescribeTable(ctx, params, optFns...)
+	if err == nil && c.creating > 0 {
+		c.creating--
+		out.Table.TableStatus = types.TableStatusCreating
+	}
+
+	return out, err
+}
+
+func TestNewCreateTableInput(t *testing.T) {
+	in, err := dy.NewCreateTableInput(adminConfig, dy.TableOptions{
+		BillingMode:    types.BillingModeProvisioned,
+		ReadCapacity:   5,
+		WriteCapacity:  2,
+		StreamViewType: types.StreamViewTypeNewImage,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "users", aws.ToString(in.TableName))
+	assert.Equal(t, types.BillingModeProvisioned, in.BillingMode)
+	assert.Equal(t, int64(5), aws.ToInt64(in.ProvisionedThroughput.ReadCapacityUnits))
+	assert.True(t, aws.ToBool(in.StreamSpecification.StreamEnabled))
+	assert.Equal(t, []types.AttributeDefinition{
+		{AttributeName: aws.String("age"), AttributeType: types.ScalarAttributeTypeN},
+		{AttributeName: aws.String("email"), AttributeType: types.ScalarAttributeTypeS},
+		{AttributeName: aws.String("groupID"), AttributeType: types.ScalarAttributeTypeN},
+		{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+	}, in.AttributeDefinitions)
+
+	require.Len(t, in.GlobalSecondaryIndexes, 1)
+	assert.Equal(t, int64(2), aws.ToInt64(in.GlobalSecondaryIndexes[0].ProvisionedThroughput.WriteCapacityUnits))
+
+	require.Len(t, in.LocalSecondaryIndexes, 1)
+	assert.Equal(t, []types.KeySchemaElement{
+		{AttributeName: aws.String("groupID"), KeyType: types.KeyTypeHash},
+		{AttributeName: aws.String("age"), KeyType: types.KeyTypeRange},
+	}, in.LocalSecondaryIndexes[0].KeySchema)
+
+	in, err = dy.NewCreateTableInput(adminConfig, dy.TableOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, types.BillingModePayPerRequest, in.BillingMode)
+	assert.Nil(t, in.ProvisionedThroughput)
+	assert.Nil(t, in.StreamSpecification)
+
+	_, err = dy.NewCreateTableInput(adminConfig, dy.TableOptions{BillingMode: types.BillingModeProvisioned})
+	assert.Error(t, err)
+
+	conf := adminConfig
+	conf.Indexes = map[dy.DBIndexName]dy.DBPrimaryKeyNames{
+		"byID": {PartitionKey: dy.DynamoKeyMetadata{Name: "id", Type: dy.Number}},
+	}
+	_, err = dy.NewCreateTableInput(conf, dy.TableOptions{})
+	assert.ErrorIs(t, err, dy.ErrInvalidDBKeyType)
+
+	conf.Indexes = map[dy.DBIndexName]dy.DBPrimaryKeyNames{
+		"byEnabled": {PartitionKey: dy.DynamoKeyMetadata{Name: "enabled", Type: dy.Boolean}},
+	}
+	_, err = dy.NewCreateTableInput(conf, dy.TableOptions{})
+	assert.ErrorIs(t, err, dy.ErrInvalidDBKeyType)
+}
+
+func TestEnsureTable(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+	opts := dy.TableOptions{TTLAttribute: "expiresAt", StreamViewType: types.StreamViewTypeNewAndOldImages}
+
+	require.NoError(t, dy.EnsureTable(ctx, client, adminConfig, opts))
+
+	out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String("users")})
+	require.NoError(t, err)
+	assert.Len(t, out.Table.GlobalSecondaryIndexes, 1)
+	assert.Len(t, out.Table.LocalSecondaryIndexes, 1)
+	assert.NotEmpty(t, aws.ToString(out.Table.LatestStreamArn))
+
+	ttl, err := client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String("users")})
+	require.NoError(t, err)
+	assert.Equal(t, types.TimeToLiveStatusEnabled, ttl.TimeToLiveDescription.TimeToLiveStatus)
+	assert.Equal(t, "expiresAt", aws.ToString(ttl.TimeToLiveDescription.AttributeName))
+
+	// the existing table matching the config is kept
+	require.NoError(t, dy.EnsureTable(ctx, client, adminConfig, opts))
+
+	opts.TTLAttribute = "ttl"
+	assert.ErrorIs(t, dy.EnsureTable(ctx, client, adminConfig, opts), dy.ErrTableMismatch)
+
+	conf := adminConfig
+	conf.Indexes = map[dy.DBIndexName]dy.DBPrimaryKeyNames{
+		"byEmail": {PartitionKey: dy.DynamoKeyMetadata{Name: "mail", Type: dy.String}},
+		"byName":  {PartitionKey: dy.DynamoKeyMetadata{Name: "name", Type: dy.String}},
+	}
+	err = dy.EnsureTable(ctx, client, conf, dy.TableOptions{})
+	assert.ErrorIs(t, err, dy.ErrTableMismatch)
+	assert.ErrorContains(t, err, `global index byEmail: HASH key is "email" instead of "mail"`)
+	assert.ErrorContains(t, err, "missing global index byName")
+
+	conf = adminConfig
+	conf.TableInfo.PrimaryKey = dy.DBPrimaryKeyNames{PartitionKey: dy.DynamoKeyMetadata{Name: "groupID", Type: dy.String}}
+	conf.LocalIndexes = nil
+	err = dy.EnsureTable(ctx, client, conf, dy.TableOptions{})
+	assert.ErrorIs(t, err, dy.ErrTableMismatch)
+	assert.ErrorContains(t, err, `table: unexpected RANGE key "id"`)
+	assert.ErrorContains(t, err, `table: key "groupID" has type N instead of S`)
+}
+
+func TestCreateTable(t *testing.T) {
+	ctx := context.Background()
+	client := &creatingClient{Client: memdb.New(), creating: 2}
+
+	require.NoError(t, dy.CreateTable(ctx, client, adminConfig, dy.TableOptions{PollInterval: time.Millisecond}))
+	assert.Zero(t, client.creating)
+
+	var inUse *types.ResourceInUseException
+	assert.True(t, errors.As(dy.CreateTable(ctx, client, adminConfig, dy.TableOptions{}), &inUse))
+}
+
+func TestWaitForTable(t *testing.T) {
+	ctx := context.Background()
+	client := &creatingClient{Client: memdb.New(), creating: 1000}
+	require.NoError(t, client.CreateTableFromConfig(ctx, adminConfig))
+
+	err := dy.WaitForTable(ctx, client, "users", dy.TableOptions{WaitTimeout: 20 * time.Millisecond, PollInterval: time.Millisecond})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	err = dy.WaitForTable(ctx, client, "unknown", dy.TableOptions{})
+	var notFound *types.ResourceNotFoundException
+	assert.True(t, errors.As(err, &notFound))
+}
+
+func TestVerifyTable(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+
+	var notFound *types.ResourceNotFoundException
+	assert.True(t, errors.As(dy.VerifyTable(ctx, client, adminConfig), &notFound))
+
+	require.NoError(t, client.CreateTableFromConfig(ctx, adminConfig))
+	require.NoError(t, dy.VerifyTable(ctx, client, adminConfig))
+
+	conf := adminConfig
+	conf.TableInfo.PrimaryKey.SortKey = &dy.DynamoKeyMetadata{Name: "name", Type: dy.String}
+	conf.LocalIndexes = map[dy.DBIndexName]dy.DynamoKeyMetadata{"byAge": {Name: "age", Type: dy.Number}, "byName": {Name: "name", Type: dy.String}}
+
+	var mismatch *dy.TableMismatchError
+	require.True(t, errors.As(dy.VerifyTable(ctx, client, conf), &mismatch))
+	assert.Equal(t, "users", mismatch.Table)
+	assert.Equal(t, []string{
+		`table: RANGE key is "id" instead of "name"`,
+		"missing local index byName",
+	}, mismatch.Differences)
+
+	// the config is validated before describing the table
+	conf.TableInfo.PrimaryKey.SortKey.Type = dy.Boolean
+	err := dy.VerifyTable(ctx, client, conf)
+	assert.ErrorIs(t, err, dy.ErrInvalidConfig)
+	assert.ErrorIs(t, err, dy.ErrInvalidDBKeyType)
+}
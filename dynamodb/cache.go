@@ -0,0 +1,336 @@
+package dy
+
+import (
+	"container/list"
+	"context"
+	"encoding/base64"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CacheEntry represents a cached item.
+// A nil Item marks a negative entry, i.e. an item known to be missing.
+type CacheEntry struct {
+	Item map[string]types.AttributeValue
+}
+
+// Cache defines the storage behind the read-through cache.
+type Cache interface {
+	// Get returns the entry stored for key, found is false if there is no (valid) entry.
+	Get(ctx context.Context, key string) (entry CacheEntry, found bool, err error)
+	// Set stores the entry for key. A ttl <= 0 means the entry never expires.
+	Set(ctx context.Context, key string, entry CacheEntry, ttl time.Duration) error
+	// Delete removes the entry stored for key.
+	Delete(ctx context.Context, key string) error
+}
+
+// CacheStats the read-through cache statistics.
+type CacheStats struct {
+	// Hits the number of lookups served from the cache (including negative hits).
+	Hits uint64
+	// NegativeHits the number of lookups served from a negative entry.
+	NegativeHits uint64
+	// Misses the number of lookups forwarded to dynamodb.
+	Misses uint64
+}
+
+// LRUCache a bounded, in-memory, least-recently-used Cache.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+type lruEntry struct {
+	key       string
+	entry     CacheEntry
+	expiresAt time.Time
+}
+
+var _ Cache = (*LRUCache)(nil)
+
+// NewLRUCache creates a new LRU cache holding at most capacity entries.
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get returns the entry stored for key.
+func (c *LRUCache) Get(_ context.Context, key string) (CacheEntry, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return CacheEntry{}, false, nil
+	}
+
+	e := elem.Value.(*lruEntry)
+	if !e.expiresAt.IsZero() && !c.now().Before(e.expiresAt) {
+		c.remove(elem)
+		return CacheEntry{}, false, nil
+	}
+
+	c.order.MoveToFront(elem)
+	return e.entry, true, nil
+}
+
+// Set stores the entry for key, evicting the least recently used entry if the cache is full.
+func (c *LRUCache) Set(_ context.Context, key string, entry CacheEntry, ttl time.Duration) error {
+	if c.capacity <= 0 {
+		return nil
+	}
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*lruEntry)
+		e.entry = entry
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{
+		key:       key,
+		entry:     entry,
+		expiresAt: expiresAt,
+	})
+
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+// Delete removes the entry stored for key.
+func (c *LRUCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+
+	return nil
+}
+
+// Len returns the number of stored entries (including the expired ones not yet evicted).
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRUCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*lruEntry).key)
+}
+
+// itemCache wraps the configured Cache with the TTLs and the statistics.
+// A nil itemCache disables caching.
+//
+// The entries are versioned: the readers take the version before reading dynamodb, and their entries are dropped
+// if an item was invalidated meanwhile, as they may be stale.
+type itemCache struct {
+	cache        Cache
+	mu           sync.RWMutex
+	version      uint64
+	ttl          time.Duration
+	negativeTTL  time.Duration
+	hits         atomic.Uint64
+	negativeHits atomic.Uint64
+	misses       atomic.Uint64
+}
+
+func newItemCache(opts options) *itemCache {
+	if opts.cache == nil {
+		return nil
+	}
+
+	return &itemCache{
+		cache:       opts.cache,
+		ttl:         opts.cacheTTL,
+		negativeTTL: opts.cacheNegativeTTL,
+	}
+}
+
+// get looks up the entry for key. Cache errors are handled as misses.
+func (c *itemCache) get(ctx context.Context, key string) (CacheEntry, bool) {
+	if c == nil {
+		return CacheEntry{}, false
+	}
+
+	entry, found, err := c.cache.Get(ctx, key)
+	if err != nil || !found {
+		c.misses.Add(1)
+		return CacheEntry{}, false
+	}
+
+	c.hits.Add(1)
+	if entry.Item == nil {
+		c.negativeHits.Add(1)
+	}
+
+	return entry, true
+}
+
+// current returns the version of the entries, to be taken before reading dynamodb.
+func (c *itemCache) current() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version
+}
+
+// set refreshes the entry for key read at version. Population errors are ignored as the cache is best effort.
+func (c *itemCache) set(ctx context.Context, version uint64, key string, item map[string]types.AttributeValue) {
+	if c == nil {
+		return
+	}
+
+	c.store(ctx, version, key, CacheEntry{Item: item}, c.ttl)
+}
+
+// setMissing stores a negative entry for key read at version if negative caching is enabled.
+func (c *itemCache) setMissing(ctx context.Context, version uint64, key string) {
+	if c == nil || c.negativeTTL <= 0 {
+		return
+	}
+
+	c.store(ctx, version, key, CacheEntry{}, c.negativeTTL)
+}
+
+// store stores the entry unless an item was invalidated since version.
+func (c *itemCache) store(ctx context.Context, version uint64, key string, entry CacheEntry, ttl time.Duration) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.version != version {
+		return
+	}
+
+	_ = c.cache.Set(ctx, key, entry, ttl)
+}
+
+// invalidate removes the entry for key, and drops the entries being stored by the concurrent readers.
+func (c *itemCache) invalidate(ctx context.Context, key string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	return c.cache.Delete(ctx, key)
+}
+
+func (c *itemCache) stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+
+	return CacheStats{
+		Hits:         c.hits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+	}
+}
+
+// CacheStats returns the read-through cache statistics.
+func (d *DB[T]) CacheStats() CacheStats {
+	return d.cache.stats()
+}
+
+// cacheKeyOf returns the cache key of the item identified by primaryKey.
+func (d *DB[T]) cacheKeyOf(primaryKey DynamoPrimaryKey) (string, bool) {
+	if d.cache == nil {
+		return "", false
+	}
+
+	partKey, sortKey, err := preparePartSortKey(primaryKey)
+	if err != nil {
+		return "", false
+	}
+
+	return cacheKey(d.conf.TableInfo.TableName, prepareDynamoKeys(partKey, sortKey)), true
+}
+
+// itemCacheKey returns the cache key of a raw item using the provided key metadata.
+func itemCacheKey(table string, item map[string]types.AttributeValue, partKey DynamoKeyMetadata, sortKey *DynamoKeyMetadata) (string, bool) {
+	keys := make(map[string]types.AttributeValue, 2)
+
+	v, ok := item[string(partKey.Name)]
+	if !ok {
+		return "", false
+	}
+	keys[string(partKey.Name)] = v
+
+	if sortKey != nil {
+		v, ok := item[string(sortKey.Name)]
+		if !ok {
+			return "", false
+		}
+		keys[string(sortKey.Name)] = v
+	}
+
+	return cacheKey(table, keys), true
+}
+
+// cacheKey builds a deterministic cache key from the table name and the item key attributes.
+func cacheKey(table string, keys map[string]types.AttributeValue) string {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(strconv.Quote(table))
+	for _, name := range names {
+		sb.WriteByte('|')
+		sb.WriteString(strconv.Quote(name))
+		sb.WriteByte('=')
+		sb.WriteString(keyValueString(keys[name]))
+	}
+
+	return sb.String()
+}
+
+func keyValueString(av types.AttributeValue) string {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return "S:" + strconv.Quote(v.Value)
+	case *types.AttributeValueMemberN:
+		return "N:" + v.Value
+	case *types.AttributeValueMemberB:
+		return "B:" + base64.StdEncoding.EncodeToString(v.Value)
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL:" + strconv.FormatBool(v.Value)
+	}
+
+	return "?"
+}
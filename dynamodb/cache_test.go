@@ -0,0 +1,317 @@
+package dy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+	entry := dy.CacheEntry{Item: getItemAttributeValuesTestData()[0]}
+
+	t.Run("get and set", func(t *testing.T) {
+		c := dy.NewLRUCache(2)
+		_, found, err := c.Get(ctx, "k1")
+		assert.NoError(t, err)
+		assert.False(t, found)
+
+		assert.NoError(t, c.Set(ctx, "k1", entry, 0))
+		e, found, err := c.Get(ctx, "k1")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, entry, e)
+
+		assert.NoError(t, c.Delete(ctx, "k1"))
+		_, found, _ = c.Get(ctx, "k1")
+		assert.False(t, found)
+	})
+
+	t.Run("evicts the least recently used entry", func(t *testing.T) {
+		c := dy.NewLRUCache(2)
+		_ = c.Set(ctx, "k1", entry, 0)
+		_ = c.Set(ctx, "k2", entry, 0)
+
+		// touch k1 so k2 becomes the least recently used entry
+		_, _, _ = c.Get(ctx, "k1")
+		_ = c.Set(ctx, "k3", entry, 0)
+
+		assert.Equal(t, 2, c.Len())
+		_, found, _ := c.Get(ctx, "k2")
+		assert.False(t, found)
+		_, found, _ = c.Get(ctx, "k1")
+		assert.True(t, found)
+		_, found, _ = c.Get(ctx, "k3")
+		assert.True(t, found)
+	})
+
+	t.Run("expires entries", func(t *testing.T) {
+		c := dy.NewLRUCache(2)
+		_ = c.Set(ctx, "k1", entry, time.Millisecond)
+		time.Sleep(5 * time.Millisecond)
+
+		_, found, _ := c.Get(ctx, "k1")
+		assert.False(t, found)
+		assert.Equal(t, 0, c.Len())
+	})
+
+	t.Run("with zero capacity", func(t *testing.T) {
+		c := dy.NewLRUCache(0)
+		_ = c.Set(ctx, "k1", entry, 0)
+		assert.Equal(t, 0, c.Len())
+	})
+}
+
+func TestDynamodb_GetItem_WithCache(t *testing.T) {
+	key := dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+		SortKey:      &dy.DynamoAttribute{KeyName: "id", Type: dy.String, Value: "123"},
+	}
+
+	t.Run("serves the second read from the cache", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{
+			Item: getItemAttributeValuesTestData()[0],
+		}, nil).Once()
+
+		db := dy.NewClient[entity](m, dbConfig, dy.WithCache(dy.NewLRUCache(10), time.Minute, time.Minute))
+		for i := 0; i < 2; i++ {
+			e, err := db.GetItem(context.Background(), key)
+			assert.NoError(t, err)
+			assert.Equal(t, "123", e.Id)
+		}
+
+		assert.Equal(t, dy.CacheStats{Hits: 1, Misses: 1}, db.CacheStats())
+	})
+
+	t.Run("caches not found items", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+
+		db := dy.NewClient[entity](m, dbConfig, dy.WithCache(dy.NewLRUCache(10), time.Minute, time.Minute))
+		for i := 0; i < 2; i++ {
+			_, err := db.GetItem(context.Background(), key)
+			assert.ErrorIs(t, err, dy.ErrNotFound)
+		}
+
+		assert.Equal(t, dy.CacheStats{Hits: 1, NegativeHits: 1, Misses: 1}, db.CacheStats())
+	})
+
+	t.Run("without negative caching", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Twice()
+
+		db := dy.NewClient[entity](m, dbConfig, dy.WithCache(dy.NewLRUCache(10), time.Minute, 0))
+		for i := 0; i < 2; i++ {
+			_, err := db.GetItem(context.Background(), key)
+			assert.ErrorIs(t, err, dy.ErrNotFound)
+		}
+
+		assert.Equal(t, dy.CacheStats{Misses: 2}, db.CacheStats())
+	})
+
+	t.Run("is invalidated by update and delete", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{
+			Item: getItemAttributeValuesTestData()[0],
+		}, nil).Times(3)
+		m.On("UpdateItem", mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+		m.On("DeleteItem", mock.Anything, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+		db := dy.NewClient[entity](m, dbConfig, dy.WithCache(dy.NewLRUCache(10), time.Minute, time.Minute))
+		_, err := db.GetItem(context.Background(), key)
+		assert.NoError(t, err)
+
+		err = db.Update(context.Background(), key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("firstName", "new")})
+		assert.NoError(t, err)
+
+		_, err = db.GetItem(context.Background(), key)
+		assert.NoError(t, err)
+
+		err = db.Delete(context.Background(), key)
+		assert.NoError(t, err)
+
+		_, err = db.GetItem(context.Background(), key)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(3), db.CacheStats().Misses)
+	})
+
+	t.Run("ignores the invalidation errors of the successful writes", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("UpdateItem", mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+		m.On("DeleteItem", mock.Anything, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+
+		db := dy.NewClient[entity](m, dbConfig, dy.WithCache(failingCache{dy.NewLRUCache(10)}, time.Minute, time.Minute))
+		err := db.Update(context.Background(), key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("firstName", "new")})
+		assert.NoError(t, err)
+
+		assert.NoError(t, db.Delete(context.Background(), key))
+	})
+
+	t.Run("does not cache the items read before a concurrent invalidation", func(t *testing.T) {
+		var db *dy.DB[entity]
+		m := mocks.NewDynamoClient(t)
+		m.On("UpdateItem", mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+		m.On("GetItem", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+			// the item is updated once read, before being cached
+			err := db.Update(context.Background(), key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("firstName", "new")})
+			assert.NoError(t, err)
+		}).Return(&dynamodb.GetItemOutput{
+			Item: getItemAttributeValuesTestData()[0],
+		}, nil).Once()
+		m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{
+			Item: getItemAttributeValuesTestData()[0],
+		}, nil).Once()
+
+		db = dy.NewClient[entity](m, dbConfig, dy.WithCache(dy.NewLRUCache(10), time.Minute, time.Minute))
+		for i := 0; i < 2; i++ {
+			_, err := db.GetItem(context.Background(), key)
+			assert.NoError(t, err)
+		}
+
+		assert.Equal(t, dy.CacheStats{Misses: 2}, db.CacheStats())
+	})
+
+	t.Run("is refreshed by create", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("PutItem", mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+		db := dy.NewClient[entity](m, dy.DBConfig{
+			TableInfo: dy.TableInfo{
+				TableName: "tableName",
+				PrimaryKey: dy.DBPrimaryKeyNames{
+					PartitionKey: dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+				},
+			},
+		}, dy.WithCache(dy.NewLRUCache(10), time.Minute, time.Minute))
+
+		key, err := db.Create(context.Background(), entity{Id: "id-1", FirstName: "f1"})
+		assert.NoError(t, err)
+
+		e, err := db.GetItem(context.Background(), key)
+		assert.NoError(t, err)
+		assert.Equal(t, "f1", e.FirstName)
+		assert.Equal(t, dy.CacheStats{Hits: 1}, db.CacheStats())
+	})
+}
+
+func TestDynamodb_GetItems_WithCache(t *testing.T) {
+	keyOf := func(groupID, id string) dy.DynamoPrimaryKey {
+		return dy.DynamoPrimaryKey{
+			PartitionKey: *dy.NewDynamoNumberAttrib("groupID", groupID),
+			SortKey:      &dy.DynamoAttribute{KeyName: "id", Type: dy.String, Value: id},
+		}
+	}
+
+	m := mocks.NewDynamoClient(t)
+	m.On("BatchGetItem", mock.Anything, mock.MatchedBy(func(in *dynamodb.BatchGetItemInput) bool {
+		return len(in.RequestItems[dbConfig.TableInfo.TableName].Keys) == 3
+	})).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]types.AttributeValue{
+			dbConfig.TableInfo.TableName: getItemAttributeValuesTestData(),
+		},
+		UnprocessedKeys: map[string]types.KeysAndAttributes{
+			dbConfig.TableInfo.TableName: {
+				Keys: []map[string]types.AttributeValue{
+					{
+						"id":      &types.AttributeValueMemberS{Value: "125"},
+						"groupID": &types.AttributeValueMemberN{Value: "1234"},
+					},
+				},
+			},
+		},
+	}, nil).Once()
+	m.On("BatchGetItem", mock.Anything, mock.MatchedBy(func(in *dynamodb.BatchGetItemInput) bool {
+		return len(in.RequestItems[dbConfig.TableInfo.TableName].Keys) == 1
+	})).Return(&dynamodb.BatchGetItemOutput{}, nil).Once()
+
+	db := dy.NewClient[entity](m, dbConfig, dy.WithCache(dy.NewLRUCache(10), time.Minute, time.Minute))
+	keys := []dy.DynamoPrimaryKey{keyOf("1234", "123"), keyOf("1234", "124"), keyOf("1234", "125")}
+
+	// the first call loads all the keys: 123 is found, 124 is missing and 125 is unprocessed
+	items, unprocessed, err := db.GetItems(context.Background(), keys)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Len(t, unprocessed, 1)
+
+	// the second call only loads the previously unprocessed key
+	items, unprocessed, err = db.GetItems(context.Background(), keys)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Len(t, unprocessed, 0)
+	assert.Equal(t, dy.CacheStats{Hits: 2, NegativeHits: 1, Misses: 4}, db.CacheStats())
+}
+
+func TestDynamodb_GetItems_WithCache_Invalidated(t *testing.T) {
+	key := dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+		SortKey:      &dy.DynamoAttribute{KeyName: "id", Type: dy.String, Value: "123"},
+	}
+
+	var db *dy.DB[entity]
+	m := mocks.NewDynamoClient(t)
+	m.On("DeleteItem", mock.Anything, mock.Anything).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+	m.On("BatchGetItem", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		// the item is deleted once read, before being cached
+		assert.NoError(t, db.Delete(context.Background(), key))
+	}).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]types.AttributeValue{
+			dbConfig.TableInfo.TableName: getItemAttributeValuesTestData()[:1],
+		},
+	}, nil).Once()
+	m.On("BatchGetItem", mock.Anything, mock.Anything).Return(&dynamodb.BatchGetItemOutput{}, nil).Once()
+
+	db = dy.NewClient[entity](m, dbConfig, dy.WithCache(dy.NewLRUCache(10), time.Minute, time.Minute))
+	items, _, err := db.GetItems(context.Background(), []dy.DynamoPrimaryKey{key})
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	items, _, err = db.GetItems(context.Background(), []dy.DynamoPrimaryKey{key})
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+	assert.Equal(t, dy.CacheStats{Misses: 2}, db.CacheStats())
+}
+
+func TestDynamodb_GetItems_Error_NoLeak(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("BatchGetItem", mock.Anything, mock.Anything).Return(nil, errors.New("unavailable"))
+
+	keys := make([]dy.DynamoPrimaryKey, 0, 60)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, dy.DynamoPrimaryKey{
+			PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+			SortKey:      &dy.DynamoAttribute{KeyName: "id", Type: dy.String, Value: fmt.Sprint(i)},
+		})
+	}
+
+	// the batches failing after the first error do not block
+	before := runtime.NumGoroutine()
+	db := dy.NewClient[entity](m, dbConfig)
+	_, _, err := db.GetItems(context.Background(), keys)
+	assert.Error(t, err)
+
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+}
+
+// failingCache a cache failing to delete its entries.
+type failingCache struct {
+	*dy.LRUCache
+}
+
+func (failingCache) Delete(context.Context, string) error {
+	return errors.New("unavailable")
+}
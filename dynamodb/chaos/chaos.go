@@ -0,0 +1,226 @@
+// Package chaos provides a DynamoClient decorator injecting faults (throttling, latency, timeouts,
+// conditional failures, unprocessed keys...) in the calls, to test the retry and the fallback logic.
+//
+// The faults are injected by rules matching the calls per operation and table, either by probability
+// or by a deterministic schedule:
+//
+//	client := chaos.New(dynamoClient, []chaos.Rule{
+//		{Operation: dy.OperationQuery, Fault: chaos.Throttle(), Schedule: chaos.OnCalls(1, 2)},
+//		{Table: "users", Fault: chaos.Latency(50 * time.Millisecond), Probability: 0.1},
+//	})
+package chaos
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Schedule decides whether to inject a fault in the nth (1-based) call matching a rule.
+type Schedule func(n int) bool
+
+// OnCalls injects the fault in the listed matching calls (e.g. OnCalls(1, 2) fails the first two calls).
+func OnCalls(calls ...int) Schedule {
+	set := make(map[int]bool, len(calls))
+	for _, n := range calls {
+		set[n] = true
+	}
+
+	return func(n int) bool {
+		return set[n]
+	}
+}
+
+// FirstCalls injects the fault in the first count matching calls.
+func FirstCalls(count int) Schedule {
+	return func(n int) bool {
+		return n <= count
+	}
+}
+
+// EveryNth injects the fault in every nth matching call.
+func EveryNth(every int) Schedule {
+	return func(n int) bool {
+		return every > 0 && n%every == 0
+	}
+}
+
+// Rule injects a fault in the matching calls.
+type Rule struct {
+	// Operation the matched operation (e.g. dy.OperationQuery), empty to match all the operations.
+	Operation string
+	// Table the matched table, empty to match all the tables.
+	Table string
+	// Fault the injected fault.
+	Fault Fault
+	// Schedule the matching calls to inject the fault in. Overrides Probability when set.
+	Schedule Schedule
+	// Probability the probability to inject the fault in a matching call, from 0 to 1.
+	Probability float64
+}
+
+func (r *Rule) matches(op string, tables []string) bool {
+	if r.Operation != "" && r.Operation != op {
+		return false
+	}
+
+	if r.Table == "" {
+		return true
+	}
+
+	for _, table := range tables {
+		if table == r.Table {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Option configures the chaos client.
+type Option func(*Client)
+
+// WithRand sets the random source of the probabilistic rules, e.g. a seeded one for reproducible runs.
+func WithRand(rnd *rand.Rand) Option {
+	return func(c *Client) {
+		c.rnd = rnd
+	}
+}
+
+// Client a DynamoClient injecting faults in the calls to the wrapped client. It is safe for concurrent use.
+//
+// For every call, the rules are evaluated in order and the first triggered rule injects its fault.
+// Every rule counts the calls it matches, whether or not a previous rule was triggered.
+type Client struct {
+	client dy.DynamoClient
+	rules  []Rule
+
+	mu       sync.Mutex
+	rnd      *rand.Rand
+	calls    []int
+	injected int
+}
+
+var _ dy.DynamoClient = (*Client)(nil)
+
+// New creates a new fault-injecting client.
+func New(client dy.DynamoClient, rules []Rule, opts ...Option) *Client {
+	c := &Client{
+		client: client,
+		rules:  rules,
+		rnd:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		calls:  make([]int, len(rules)),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Injected returns the number of injected faults.
+func (c *Client) Injected() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.injected
+}
+
+// fault returns the fault to inject in a call, nil if none.
+func (c *Client) fault(op string, tables []string) Fault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var fault Fault
+	for i := range c.rules {
+		rule := &c.rules[i]
+		if !rule.matches(op, tables) {
+			continue
+		}
+		c.calls[i]++
+
+		if fault != nil || rule.Fault == nil {
+			continue
+		}
+
+		if rule.Schedule != nil {
+			if rule.Schedule(c.calls[i]) {
+				fault = rule.Fault
+			}
+		} else if rule.Probability > 0 && c.rnd.Float64() < rule.Probability {
+			fault = rule.Fault
+		}
+	}
+
+	if fault != nil {
+		c.injected++
+	}
+
+	return fault
+}
+
+func inject[In, Out any](ctx context.Context, c *Client, op string, tables []string, in *In, optFns []func(*dynamodb.Options),
+	call func(context.Context, *In, ...func(*dynamodb.Options)) (*Out, error)) (*Out, error) {
+	fault := c.fault(op, tables)
+	if fault == nil {
+		return call(ctx, in, optFns...)
+	}
+
+	out, err := fault.inject(ctx, op, in, func(ctx context.Context, in interface{}) (interface{}, error) {
+		return call(ctx, in.(*In), optFns...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// a fault may return no output
+	o, _ := out.(*Out)
+	return o, nil
+}
+
+// Scan calls the wrapped client Scan, or injects a fault.
+func (c *Client) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return inject(ctx, c, dy.OperationScan, []string{aws.ToString(params.TableName)}, params, optFns, c.client.Scan)
+}
+
+// Query calls the wrapped client Query, or injects a fault.
+func (c *Client) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return inject(ctx, c, dy.OperationQuery, []string{aws.ToString(params.TableName)}, params, optFns, c.client.Query)
+}
+
+// GetItem calls the wrapped client GetItem, or injects a fault.
+func (c *Client) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return inject(ctx, c, dy.OperationGetItem, []string{aws.ToString(params.TableName)}, params, optFns, c.client.GetItem)
+}
+
+// BatchGetItem calls the wrapped client BatchGetItem, or injects a fault.
+func (c *Client) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	tables := make([]string, 0, len(params.RequestItems))
+	for table := range params.RequestItems {
+		tables = append(tables, table)
+	}
+
+	return inject(ctx, c, dy.OperationBatchGetItem, tables, params, optFns, c.client.BatchGetItem)
+}
+
+// PutItem calls the wrapped client PutItem, or injects a fault.
+func (c *Client) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return inject(ctx, c, dy.OperationPutItem, []string{aws.ToString(params.TableName)}, params, optFns, c.client.PutItem)
+}
+
+// UpdateItem calls the wrapped client UpdateItem, or injects a fault.
+func (c *Client) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return inject(ctx, c, dy.OperationUpdateItem, []string{aws.ToString(params.TableName)}, params, optFns, c.client.UpdateItem)
+}
+
+// DeleteItem calls the wrapped client DeleteItem, or injects a fault.
+func (c *Client) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	return inject(ctx, c, dy.OperationDeleteItem, []string{aws.ToString(params.TableName)}, params, optFns, c.client.DeleteItem)
+}
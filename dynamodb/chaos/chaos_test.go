@@ -0,0 +1,152 @@
+package chaos_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/chaos"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID   string `dynamodbav:"id"`
+	Name string `dynamodbav:"name"`
+}
+
+func (u user) IsEmpty() bool {
+	return u.ID == ""
+}
+
+var conf = dy.DBConfig{
+	TableInfo: dy.TableInfo{
+		TableName: "users",
+		PrimaryKey: dy.DBPrimaryKeyNames{
+			PartitionKey: dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+		},
+	},
+}
+
+func key(id string) dy.DynamoPrimaryKey {
+	return dy.DynamoPrimaryKey{PartitionKey: dy.NewDynamoStringAttrib("id", id)}
+}
+
+func newBackend(t *testing.T, count int) *memdb.Client {
+	backend := memdb.New()
+	require.NoError(t, backend.CreateTableFromConfig(context.Background(), conf))
+
+	for i := 0; i < count; i++ {
+		_, err := backend.PutItem(context.Background(), &dynamodb.PutItemInput{
+			TableName: aws.String("users"),
+			Item: map[string]types.AttributeValue{
+				"id":   &types.AttributeValueMemberS{Value: fmt.Sprintf("u%d", i)},
+				"name": &types.AttributeValueMemberS{Value: fmt.Sprintf("name%d", i)},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	return backend
+}
+
+func getItem(id string) *dynamodb.GetItemInput {
+	return &dynamodb.GetItemInput{
+		TableName: aws.String("users"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}},
+	}
+}
+
+func TestClient_Schedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule chaos.Schedule
+		want     []bool
+	}{
+		{name: "on calls", schedule: chaos.OnCalls(2, 4), want: []bool{false, true, false, true, false}},
+		{name: "first calls", schedule: chaos.FirstCalls(2), want: []bool{true, true, false, false, false}},
+		{name: "every nth", schedule: chaos.EveryNth(2), want: []bool{false, true, false, true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := chaos.New(newBackend(t, 1), []chaos.Rule{{Fault: chaos.Throttle(), Schedule: tt.schedule}})
+
+			for i, want := range tt.want {
+				_, err := client.GetItem(context.Background(), getItem("u0"))
+
+				var throttled *types.ProvisionedThroughputExceededException
+				assert.Equal(t, want, errors.As(err, &throttled), "call %d", i+1)
+			}
+		})
+	}
+}
+
+func TestClient_Matching(t *testing.T) {
+	ctx := context.Background()
+	fault := errors.New("fault")
+
+	client := chaos.New(newBackend(t, 1), []chaos.Rule{
+		{Operation: dy.OperationQuery, Fault: chaos.Error(fault), Schedule: chaos.FirstCalls(10)},
+		{Table: "other", Fault: chaos.Error(fault), Schedule: chaos.FirstCalls(10)},
+		{Operation: dy.OperationGetItem, Table: "users", Fault: chaos.Error(fault), Schedule: chaos.OnCalls(2)},
+	})
+
+	_, err := client.GetItem(ctx, getItem("u0"))
+	assert.NoError(t, err)
+
+	_, err = client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("users")})
+	assert.NoError(t, err)
+
+	_, err = client.GetItem(ctx, getItem("u0"))
+	assert.ErrorIs(t, err, fault)
+
+	_, err = client.Query(ctx, &dynamodb.QueryInput{TableName: aws.String("users")})
+	assert.ErrorIs(t, err, fault)
+
+	assert.Equal(t, 2, client.Injected())
+}
+
+func TestClient_Probability(t *testing.T) {
+	run := func(seed int64) []bool {
+		client := chaos.New(newBackend(t, 1), []chaos.Rule{{Fault: chaos.Throttle(), Probability: 0.5}}, chaos.WithRand(rand.New(rand.NewSource(seed))))
+
+		var failed []bool
+		for i := 0; i < 100; i++ {
+			_, err := client.GetItem(context.Background(), getItem("u0"))
+			failed = append(failed, err != nil)
+		}
+
+		assert.InDelta(t, 50, client.Injected(), 20)
+		return failed
+	}
+
+	assert.Equal(t, run(42), run(42))
+
+	client := chaos.New(newBackend(t, 1), []chaos.Rule{{Fault: chaos.Throttle()}})
+	_, err := client.GetItem(context.Background(), getItem("u0"))
+	assert.NoError(t, err)
+}
+
+func TestClient_Retry(t *testing.T) {
+	ctx := context.Background()
+	client := chaos.New(newBackend(t, 1), []chaos.Rule{
+		{Operation: dy.OperationGetItem, Fault: chaos.Throttle(), Schedule: chaos.FirstCalls(2)},
+	})
+
+	db := dy.NewClient[user](client, conf, dy.WithRetryPolicy(dy.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+
+	u, err := db.GetItem(ctx, key("u0"))
+	require.NoError(t, err)
+	assert.Equal(t, "name0", u.Name)
+	assert.Equal(t, 2, client.Injected())
+}
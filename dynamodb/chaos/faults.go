@@ -0,0 +1,146 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Fault a fault injected in a call. The faults not applying to an operation (e.g. a conditional failure
+// in a Query) let the call through.
+type Fault interface {
+	// inject injects the fault in a call, next calling the wrapped client.
+	inject(ctx context.Context, op string, in interface{}, next func(context.Context, interface{}) (interface{}, error)) (interface{}, error)
+}
+
+// faultFunc adapts a function to a Fault.
+type faultFunc func(ctx context.Context, op string, in interface{}, next func(context.Context, interface{}) (interface{}, error)) (interface{}, error)
+
+func (f faultFunc) inject(ctx context.Context, op string, in interface{}, next func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
+	return f(ctx, op, in, next)
+}
+
+// Error fails the call with the provided error, without calling the wrapped client. A nil error lets the call through.
+func Error(err error) Fault {
+	return faultFunc(func(ctx context.Context, _ string, in interface{}, next func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
+		if err == nil {
+			return next(ctx, in)
+		}
+
+		return nil, err
+	})
+}
+
+// Throttle fails the call with a ProvisionedThroughputExceededException.
+func Throttle() Fault {
+	return faultFunc(func(context.Context, string, interface{}, func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
+		return nil, &types.ProvisionedThroughputExceededException{
+			Message: aws.String("chaos: the level of configured provisioned throughput for the table was exceeded"),
+		}
+	})
+}
+
+// ConditionalFailure fails the PutItem, UpdateItem and DeleteItem calls with a ConditionalCheckFailedException.
+func ConditionalFailure() Fault {
+	return faultFunc(func(ctx context.Context, _ string, in interface{}, next func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
+		switch in.(type) {
+		case *dynamodb.PutItemInput, *dynamodb.UpdateItemInput, *dynamodb.DeleteItemInput:
+			return nil, &types.ConditionalCheckFailedException{Message: aws.String("chaos: the conditional request failed")}
+		}
+
+		return next(ctx, in)
+	})
+}
+
+// Latency delays the call by d.
+func Latency(d time.Duration) Fault {
+	return faultFunc(func(ctx context.Context, _ string, in interface{}, next func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
+		if err := sleep(ctx, d); err != nil {
+			return nil, err
+		}
+
+		return next(ctx, in)
+	})
+}
+
+// Timeout hangs for d, or until the context is done, then fails the call with an error wrapping
+// context.DeadlineExceeded. The wrapped client is not called.
+func Timeout(d time.Duration) Fault {
+	return faultFunc(func(ctx context.Context, op string, _ interface{}, _ func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
+		if err := sleep(ctx, d); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("chaos: %s timed out after %s: %w", op, d, context.DeadlineExceeded)
+	})
+}
+
+// UnprocessedKeys returns the provided fraction (from 0 to 1) of the keys of the BatchGetItem calls as
+// unprocessed, per table. The first keys are processed, the last ones are returned in the UnprocessedKeys.
+func UnprocessedKeys(fraction float64) Fault {
+	return faultFunc(func(ctx context.Context, _ string, in interface{}, next func(context.Context, interface{}) (interface{}, error)) (interface{}, error) {
+		params, ok := in.(*dynamodb.BatchGetItemInput)
+		if !ok {
+			return next(ctx, in)
+		}
+
+		processed := *params
+		processed.RequestItems = make(map[string]types.KeysAndAttributes, len(params.RequestItems))
+		unprocessed := make(map[string]types.KeysAndAttributes)
+
+		for table, keys := range params.RequestItems {
+			count := int(math.Ceil(float64(len(keys.Keys)) * math.Min(math.Max(fraction, 0), 1)))
+			split := len(keys.Keys) - count
+
+			if split > 0 {
+				kept := keys
+				kept.Keys = keys.Keys[:split]
+				processed.RequestItems[table] = kept
+			}
+
+			if count > 0 {
+				rest := keys
+				rest.Keys = keys.Keys[split:]
+				unprocessed[table] = rest
+			}
+		}
+
+		out := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]types.AttributeValue{}}
+		if len(processed.RequestItems) > 0 {
+			res, err := next(ctx, &processed)
+			if err != nil {
+				return nil, err
+			}
+			out = res.(*dynamodb.BatchGetItemOutput)
+		}
+
+		if out.UnprocessedKeys == nil {
+			out.UnprocessedKeys = make(map[string]types.KeysAndAttributes)
+		}
+
+		for table, keys := range unprocessed {
+			merged := keys
+			merged.Keys = append(append([]map[string]types.AttributeValue(nil), out.UnprocessedKeys[table].Keys...), keys.Keys...)
+			out.UnprocessedKeys[table] = merged
+		}
+
+		return out, nil
+	})
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,121 @@
+package chaos_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/chaos"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func always(fault chaos.Fault) []chaos.Rule {
+	return []chaos.Rule{{Fault: fault, Probability: 1}}
+}
+
+func TestError(t *testing.T) {
+	ctx := context.Background()
+	failure := errors.New("unavailable")
+
+	_, err := chaos.New(newBackend(t, 1), always(chaos.Error(failure))).GetItem(ctx, getItem("u0"))
+	assert.ErrorIs(t, err, failure)
+
+	// a nil error lets the call through
+	out, err := chaos.New(newBackend(t, 1), always(chaos.Error(nil))).GetItem(ctx, getItem("u0"))
+	require.NoError(t, err)
+	assert.NotNil(t, out.Item)
+}
+
+func TestConditionalFailure(t *testing.T) {
+	ctx := context.Background()
+	client := chaos.New(newBackend(t, 1), always(chaos.ConditionalFailure()))
+
+	_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String("users"),
+		Item:      map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "u1"}},
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	assert.True(t, errors.As(err, &conditionErr))
+
+	_, err = client.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String("users"), Key: getItem("u0").Key})
+	assert.True(t, errors.As(err, &conditionErr))
+
+	// the reads go through
+	out, err := client.GetItem(ctx, getItem("u0"))
+	require.NoError(t, err)
+	assert.NotNil(t, out.Item)
+}
+
+func TestLatency(t *testing.T) {
+	client := chaos.New(newBackend(t, 1), always(chaos.Latency(20*time.Millisecond)))
+
+	start := time.Now()
+	out, err := client.GetItem(context.Background(), getItem("u0"))
+	require.NoError(t, err)
+	assert.NotNil(t, out.Item)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = client.GetItem(ctx, getItem("u0"))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestTimeout(t *testing.T) {
+	client := chaos.New(newBackend(t, 1), always(chaos.Timeout(time.Millisecond)))
+
+	_, err := client.GetItem(context.Background(), getItem("u0"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	client = chaos.New(newBackend(t, 1), always(chaos.Timeout(time.Hour)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = client.GetItem(ctx, getItem("u0"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestUnprocessedKeys(t *testing.T) {
+	ctx := context.Background()
+	input := func(ids ...string) *dynamodb.BatchGetItemInput {
+		var keys []map[string]types.AttributeValue
+		for _, id := range ids {
+			keys = append(keys, getItem(id).Key)
+		}
+
+		return &dynamodb.BatchGetItemInput{RequestItems: map[string]types.KeysAndAttributes{"users": {Keys: keys}}}
+	}
+
+	client := chaos.New(newBackend(t, 4), always(chaos.UnprocessedKeys(0.5)))
+
+	in := input("u0", "u1", "u2")
+	out, err := client.BatchGetItem(ctx, in)
+	require.NoError(t, err)
+	assert.Len(t, out.Responses["users"], 1)
+	assert.Equal(t, in.RequestItems["users"].Keys[1:], out.UnprocessedKeys["users"].Keys)
+	assert.Len(t, in.RequestItems["users"].Keys, 3)
+
+	client = chaos.New(newBackend(t, 4), always(chaos.UnprocessedKeys(1)))
+	out, err = client.BatchGetItem(ctx, input("u0"))
+	require.NoError(t, err)
+	assert.Empty(t, out.Responses["users"])
+	assert.Len(t, out.UnprocessedKeys["users"].Keys, 1)
+
+	// the dy client returns the unprocessed keys
+	client = chaos.New(newBackend(t, 4), always(chaos.UnprocessedKeys(0.25)))
+	db := dy.NewClient[user](client, conf)
+
+	items, unprocessed, err := db.GetItems(ctx, []dy.DynamoPrimaryKey{key("u0"), key("u1"), key("u2"), key("u3")})
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+	assert.Equal(t, []dy.DynamoPrimaryKey{key("u3")}, unprocessed)
+}
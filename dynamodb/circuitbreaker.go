@@ -0,0 +1,268 @@
+package dy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitState the state of a circuit.
+type CircuitState int
+
+const (
+	// CircuitClosed the calls go through and the failures are counted.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen the calls fail fast with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen a limited number of probe calls go through to test the recovery.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// OperationClass groups the operations sharing the same circuit.
+type OperationClass int
+
+const (
+	// OperationClassRead Scan, Query, GetItem and BatchGetItem.
+	OperationClassRead OperationClass = iota
+	// OperationClassWrite PutItem, UpdateItem and DeleteItem.
+	OperationClassWrite
+)
+
+func operationClass(operation string) OperationClass {
+	if isWriteOperation(operation) {
+		return OperationClassWrite
+	}
+
+	return OperationClassRead
+}
+
+// CircuitBreakerConfig the configuration of the circuits of an operation class.
+type CircuitBreakerConfig struct {
+	// FailureThreshold the number of consecutive failures opening the circuit. Defaults to 5.
+	FailureThreshold int
+	// OpenTimeout the time the circuit stays open before letting probe calls through. Defaults to 30s.
+	OpenTimeout time.Duration
+	// HalfOpenCalls the number of successful probe calls closing the circuit. Defaults to 1.
+	HalfOpenCalls int
+	// IsFailure reports whether an error counts as a failure.
+	// Defaults to the throttling and transient errors (see ClassifyError).
+	IsFailure func(error) bool
+}
+
+func (c CircuitBreakerConfig) failureThreshold() int {
+	if c.FailureThreshold > 0 {
+		return c.FailureThreshold
+	}
+
+	return 5
+}
+
+func (c CircuitBreakerConfig) openTimeout() time.Duration {
+	if c.OpenTimeout > 0 {
+		return c.OpenTimeout
+	}
+
+	return 30 * time.Second
+}
+
+func (c CircuitBreakerConfig) halfOpenCalls() int {
+	if c.HalfOpenCalls > 0 {
+		return c.HalfOpenCalls
+	}
+
+	return 1
+}
+
+func (c CircuitBreakerConfig) isFailure(err error) bool {
+	if c.IsFailure != nil {
+		return c.IsFailure(err)
+	}
+
+	return ClassifyError(err) != ErrorClassPermanent
+}
+
+// CircuitBreaker fails the DynamoClient calls fast while a table is throttled or the endpoint is unhealthy.
+//
+// Each table has a read and a write circuit. A circuit opens after FailureThreshold consecutive failures,
+// then lets HalfOpenCalls probe calls through once OpenTimeout has elapsed: it closes if they all succeed
+// and opens again otherwise.
+//
+// A CircuitBreaker can be shared by several clients to share the circuits of the same table.
+type CircuitBreaker struct {
+	mu       sync.Mutex
+	configs  map[OperationClass]CircuitBreakerConfig
+	circuits map[circuitKey]*circuit
+	now      func() time.Time
+}
+
+type circuitKey struct {
+	table string
+	class OperationClass
+}
+
+type circuit struct {
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	// the probe calls in flight and succeeded while half-open
+	probes    int
+	successes int
+	// generation is incremented on every state change to ignore the results of the calls
+	// admitted in a previous state.
+	generation int
+}
+
+// NewCircuitBreaker creates a new circuit breaker applying defaults to every operation class.
+func NewCircuitBreaker(defaults CircuitBreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		configs: map[OperationClass]CircuitBreakerConfig{
+			OperationClassRead:  defaults,
+			OperationClassWrite: defaults,
+		},
+		circuits: make(map[circuitKey]*circuit),
+		now:      time.Now,
+	}
+}
+
+// WithCircuitBreaker guards the DynamoClient calls with the provided (potentially shared) circuit breaker.
+// The circuit breaker wraps the retry policy: a failed call is only counted once its retries are exhausted.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(o *options) {
+		o.circuitBreaker = breaker
+	}
+}
+
+// SetClassConfig overrides the configuration of an operation class.
+func (b *CircuitBreaker) SetClassConfig(class OperationClass, conf CircuitBreakerConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.configs[class] = conf
+}
+
+// State returns the current state of a circuit.
+func (b *CircuitBreaker) State(table string, class OperationClass) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[circuitKey{table: table, class: class}]
+	if !ok {
+		return CircuitClosed
+	}
+
+	if c.state == CircuitOpen && !b.now().Before(c.openedAt.Add(b.configs[class].openTimeout())) {
+		return CircuitHalfOpen
+	}
+
+	return c.state
+}
+
+// allow checks whether a call can go through and returns the circuit generation it was admitted in.
+func (b *CircuitBreaker) allow(key circuitKey) (int, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+
+	conf := b.configs[key.class]
+
+	if c.state == CircuitOpen && !b.now().Before(c.openedAt.Add(conf.openTimeout())) {
+		c.setState(CircuitHalfOpen)
+	}
+
+	switch c.state {
+	case CircuitOpen:
+		return 0, false
+	case CircuitHalfOpen:
+		if c.probes >= conf.halfOpenCalls()-c.successes {
+			return 0, false
+		}
+		c.probes++
+	}
+
+	return c.generation, true
+}
+
+// done records the result of a call admitted in the provided generation.
+func (b *CircuitBreaker) done(key circuitKey, generation int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuits[key]
+	if c.generation != generation {
+		return
+	}
+
+	conf := b.configs[key.class]
+
+	if c.state == CircuitHalfOpen {
+		c.probes--
+	}
+
+	// a canceled call tells nothing about the dependency health
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+
+	if err != nil && conf.isFailure(err) {
+		c.failures++
+		if c.state == CircuitHalfOpen || c.failures >= conf.failureThreshold() {
+			c.setState(CircuitOpen)
+			c.openedAt = b.now()
+		}
+		return
+	}
+
+	c.failures = 0
+	if c.state == CircuitHalfOpen {
+		c.successes++
+		if c.successes >= conf.halfOpenCalls() {
+			c.setState(CircuitClosed)
+		}
+	}
+}
+
+func (c *circuit) setState(state CircuitState) {
+	c.state = state
+	c.failures = 0
+	c.probes = 0
+	c.successes = 0
+	c.generation++
+}
+
+func (b *CircuitBreaker) middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (interface{}, error) {
+			key := circuitKey{table: call.Table, class: operationClass(call.Operation)}
+
+			generation, ok := b.allow(key)
+			if !ok {
+				return nil, fmt.Errorf("%s %s: %w", call.Operation, call.Table, ErrCircuitOpen)
+			}
+
+			out, err := next(ctx, call)
+			b.done(key, generation, err)
+
+			return out, err
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package dy_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	key := dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	}
+	table := dbConfig.TableInfo.TableName
+
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(nil, &types.ProvisionedThroughputExceededException{}).Twice()
+
+		breaker := dy.NewCircuitBreaker(dy.CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Hour})
+		db := dy.NewClient[entity](m, dbConfig, dy.WithCircuitBreaker(breaker))
+
+		for i := 0; i < 2; i++ {
+			_, err := db.GetItem(context.Background(), key)
+			assert.Error(t, err)
+		}
+		assert.Equal(t, dy.CircuitOpen, breaker.State(table, dy.OperationClassRead))
+
+		_, err := db.GetItem(context.Background(), key)
+		assert.ErrorIs(t, err, dy.ErrCircuitOpen)
+
+		// the write circuit is not affected
+		assert.Equal(t, dy.CircuitClosed, breaker.State(table, dy.OperationClassWrite))
+	})
+
+	t.Run("successes reset the failure count", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("DeleteItem", mock.Anything, mock.Anything).Return(nil, &types.InternalServerError{}).Once()
+		m.On("DeleteItem", mock.Anything, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+		m.On("DeleteItem", mock.Anything, mock.Anything).Return(nil, &types.InternalServerError{}).Once()
+
+		breaker := dy.NewCircuitBreaker(dy.CircuitBreakerConfig{})
+		breaker.SetClassConfig(dy.OperationClassWrite, dy.CircuitBreakerConfig{FailureThreshold: 2})
+		db := dy.NewClient[entity](m, dbConfig, dy.WithCircuitBreaker(breaker))
+
+		for i := 0; i < 3; i++ {
+			assert.Error(t, db.Delete(context.Background(), key))
+		}
+		assert.Equal(t, dy.CircuitClosed, breaker.State(table, dy.OperationClassWrite))
+	})
+
+	t.Run("half-open probe closes the circuit", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(nil, &types.InternalServerError{}).Once()
+		m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{
+			Item: getItemAttributeValuesTestData()[0],
+		}, nil).Once()
+
+		breaker := dy.NewCircuitBreaker(dy.CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond})
+		db := dy.NewClient[entity](m, dbConfig, dy.WithCircuitBreaker(breaker))
+
+		_, err := db.GetItem(context.Background(), key)
+		assert.Error(t, err)
+		assert.Equal(t, dy.CircuitOpen, breaker.State(table, dy.OperationClassRead))
+
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, dy.CircuitHalfOpen, breaker.State(table, dy.OperationClassRead))
+
+		_, err = db.GetItem(context.Background(), key)
+		assert.NoError(t, err)
+		assert.Equal(t, dy.CircuitClosed, breaker.State(table, dy.OperationClassRead))
+	})
+
+	t.Run("half-open probe failure opens the circuit", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(nil, &types.InternalServerError{}).Twice()
+
+		breaker := dy.NewCircuitBreaker(dy.CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond})
+		db := dy.NewClient[entity](m, dbConfig, dy.WithCircuitBreaker(breaker))
+
+		_, err := db.GetItem(context.Background(), key)
+		assert.Error(t, err)
+
+		time.Sleep(20 * time.Millisecond)
+		_, err = db.GetItem(context.Background(), key)
+		assert.Error(t, err)
+		assert.Equal(t, dy.CircuitOpen, breaker.State(table, dy.OperationClassRead))
+
+		_, err = db.GetItem(context.Background(), key)
+		assert.ErrorIs(t, err, dy.ErrCircuitOpen)
+	})
+
+	t.Run("counts a call once its retries are exhausted", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(nil, &types.ProvisionedThroughputExceededException{}).Times(3)
+
+		breaker := dy.NewCircuitBreaker(dy.CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Hour})
+		db := dy.NewClient[entity](m, dbConfig,
+			dy.WithCircuitBreaker(breaker),
+			dy.WithRetryPolicy(dy.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+		)
+
+		_, err := db.GetItem(context.Background(), key)
+		assert.Error(t, err)
+		assert.Equal(t, dy.CircuitClosed, breaker.State(table, dy.OperationClassRead))
+	})
+
+	t.Run("with custom failures", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("unreachable")).Once()
+
+		breaker := dy.NewCircuitBreaker(dy.CircuitBreakerConfig{
+			FailureThreshold: 1,
+			OpenTimeout:      time.Hour,
+			IsFailure:        func(error) bool { return true },
+		})
+		db := dy.NewClient[entity](m, dbConfig, dy.WithCircuitBreaker(breaker))
+
+		_, err := db.GetItem(context.Background(), key)
+		assert.Error(t, err)
+		assert.Equal(t, dy.CircuitOpen, breaker.State(table, dy.OperationClassRead))
+	})
+}
@@ -2,6 +2,9 @@ package dy
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 )
@@ -62,8 +65,85 @@ const (
 	String DBKeyType = iota
 	Number
 	Boolean
+	Binary
+	StringSet
+	NumberSet
+	BinarySet
+	List
+	Map
 )
 
+var keyTypeNames = map[DBKeyType]string{
+	String:    "String",
+	Number:    "Number",
+	Boolean:   "Boolean",
+	Binary:    "Binary",
+	StringSet: "StringSet",
+	NumberSet: "NumberSet",
+	BinarySet: "BinarySet",
+	List:      "List",
+	Map:       "Map",
+}
+
+// keyTypeAliases the dynamodb attribute types of the key types.
+var keyTypeAliases = map[string]DBKeyType{
+	"S":    String,
+	"N":    Number,
+	"BOOL": Boolean,
+	"B":    Binary,
+	"SS":   StringSet,
+	"NS":   NumberSet,
+	"BS":   BinarySet,
+	"L":    List,
+	"M":    Map,
+}
+
+// String returns the key type name.
+func (t DBKeyType) String() string {
+	if name, ok := keyTypeNames[t]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("DBKeyType(%d)", int(t))
+}
+
+// MarshalText encodes the key type name.
+func (t DBKeyType) MarshalText() ([]byte, error) {
+	if name, ok := keyTypeNames[t]; ok {
+		return []byte(name), nil
+	}
+
+	return nil, fmt.Errorf("%w %d", ErrInvalidDBKeyType, int(t))
+}
+
+// UnmarshalText decodes a key type name, or its dynamodb attribute type (e.g. S, N, BOOL or SS).
+func (t *DBKeyType) UnmarshalText(text []byte) error {
+	name := strings.ToUpper(string(text))
+	if keyType, ok := keyTypeAliases[name]; ok {
+		*t = keyType
+		return nil
+	}
+
+	for keyType, keyTypeName := range keyTypeNames {
+		if strings.ToUpper(keyTypeName) == name {
+			*t = keyType
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w %q", ErrInvalidDBKeyType, text)
+}
+
+// isCollection checks whether the values of the type are sets, lists or maps.
+func (t DBKeyType) isCollection() bool {
+	switch t {
+	case StringSet, NumberSet, BinarySet, List, Map:
+		return true
+	}
+
+	return false
+}
+
 // DBKey custom type for dynamo DB key name
 type DBKey string
 
@@ -85,6 +165,18 @@ func NewDynamoNumberAttrib(name, value string) *DynamoAttribute {
 	}
 }
 
+// NewDynamoNumericAttrib creates a new dynamodb number attribute from a Go number: an integer or a float (including
+// the named types such as time.Duration), a *big.Int, a *big.Float, a json.Number or a decimal type whose String
+// method returns the number.
+// The number is stored exactly, as its shortest decimal representation.
+func NewDynamoNumericAttrib(name string, value interface{}) DynamoAttribute {
+	return DynamoAttribute{
+		KeyName: DBKey(name),
+		Type:    Number,
+		Value:   value,
+	}
+}
+
 // NewDynamoBoolAttrib creates a new dynamodb boolean attribute.
 func NewDynamoBoolAttrib(name string, value bool) DynamoAttribute {
 	return DynamoAttribute{
@@ -94,11 +186,87 @@ func NewDynamoBoolAttrib(name string, value bool) DynamoAttribute {
 	}
 }
 
+// NewDynamoBinaryAttrib creates a new dynamodb binary attribute.
+func NewDynamoBinaryAttrib(name string, value []byte) DynamoAttribute {
+	return DynamoAttribute{
+		KeyName: DBKey(name),
+		Type:    Binary,
+		Value:   value,
+	}
+}
+
+// NewDynamoStringSetAttrib creates a new dynamodb string set attribute.
+func NewDynamoStringSetAttrib(name string, values ...string) DynamoAttribute {
+	return DynamoAttribute{
+		KeyName: DBKey(name),
+		Type:    StringSet,
+		Value:   values,
+	}
+}
+
+// NewDynamoNumberSetAttrib creates a new dynamodb number set attribute.
+func NewDynamoNumberSetAttrib(name string, values ...string) DynamoAttribute {
+	return DynamoAttribute{
+		KeyName: DBKey(name),
+		Type:    NumberSet,
+		Value:   values,
+	}
+}
+
+// NewDynamoBinarySetAttrib creates a new dynamodb binary set attribute.
+func NewDynamoBinarySetAttrib(name string, values ...[]byte) DynamoAttribute {
+	return DynamoAttribute{
+		KeyName: DBKey(name),
+		Type:    BinarySet,
+		Value:   values,
+	}
+}
+
+// NewDynamoListAttrib creates a new dynamodb list attribute.
+// The elements are marshaled with attributevalue, unless they are DynamoAttribute or types.AttributeValue values.
+func NewDynamoListAttrib(name string, values ...interface{}) DynamoAttribute {
+	return DynamoAttribute{
+		KeyName: DBKey(name),
+		Type:    List,
+		Value:   values,
+	}
+}
+
+// NewDynamoMapAttrib creates a new dynamodb map attribute.
+// The values are marshaled with attributevalue, unless they are DynamoAttribute or types.AttributeValue values.
+func NewDynamoMapAttrib(name string, values map[string]interface{}) DynamoAttribute {
+	return DynamoAttribute{
+		KeyName: DBKey(name),
+		Type:    Map,
+		Value:   values,
+	}
+}
+
 // NewClient creates a new dynamodb client wrapper for the entity [Entity].
 // The wrapper offers simplified ways to Create, Update, Delete, Find, GetItem and GetItems for the defined entity.
-func NewClient[T Entity](client DynamoClient, config DBConfig) *DB[T] {
+func NewClient[T Entity](client DynamoClient, config DBConfig, opts ...Option) *DB[T] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	names := newAttributeNames[T](o.naming, o.entityTagKey())
+
+	now, keys := o.now, defaultKeyGenerator
+	if now == nil {
+		now = time.Now
+	} else {
+		keys = NewDefaultKeyGenerator(WithGeneratorClock(now))
+	}
+
 	return &DB[T]{
 		conf:   config,
-		client: client,
+		client: newMiddlewareClient(client, o.chain()),
+		cache:  newItemCache(o),
+		tracer: newTracer(o),
+		names:  names,
+		codec:  newEntityCodec[T](o, names),
+		now:    now,
+		keys:   keys,
 	}
 }
@@ -35,7 +35,19 @@ func TestNewDynamoNumberAttrib(t *testing.T) {
 	assert.NotEmpty(t, a)
 }
 
+func TestNewDynamoNumericAttrib(t *testing.T) {
+	a := dy.NewDynamoNumericAttrib("Price", 9.99)
+	assert.Equal(t, dy.Number, a.Type)
+	assert.Equal(t, 9.99, a.Value)
+}
+
 func TestNewDynamoBoolAttrib(t *testing.T) {
 	a := dy.NewDynamoBoolAttrib("Enabled", true)
 	assert.NotEmpty(t, a)
 }
+
+func TestNewDynamoBinaryAttrib(t *testing.T) {
+	a := dy.NewDynamoBinaryAttrib("Hash", []byte{0xca, 0xfe})
+	assert.Equal(t, dy.Binary, a.Type)
+	assert.Equal(t, []byte{0xca, 0xfe}, a.Value)
+}
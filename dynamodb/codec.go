@@ -0,0 +1,387 @@
+package dy
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// WithTagKey marshals the entity fields after their tagKey tag (e.g. json), the dynamodbav tag being used
+// otherwise. Defaults to dynamodbav.
+func WithTagKey(tagKey string) Option {
+	return func(o *options) {
+		o.tagKey = tagKey
+	}
+}
+
+// WithEncoderOptions configures the attributevalue encoder of the written entities, e.g. its time encoding.
+// The tag key is set by WithTagKey.
+func WithEncoderOptions(optFns ...func(*attributevalue.EncoderOptions)) Option {
+	return func(o *options) {
+		o.encoderOptions = append(o.encoderOptions, optFns...)
+	}
+}
+
+// WithDecoderOptions configures the attributevalue decoder of the read entities, e.g. its number or time decoding.
+// The tag key is set by WithTagKey.
+func WithDecoderOptions(optFns ...func(*attributevalue.DecoderOptions)) Option {
+	return func(o *options) {
+		o.decoderOptions = append(o.decoderOptions, optFns...)
+	}
+}
+
+// WithOmitEmpty omits the null and empty attributes of the written entities (nil pointers, empty strings and
+// empty binaries, sets, lists and maps), as if their fields were tagged omitempty. The zero numbers and the false
+// booleans are written.
+func WithOmitEmpty() Option {
+	return func(o *options) {
+		o.omitEmpty = true
+	}
+}
+
+// WithCodec encodes the entity fields of type V (or *V) with encode, and decodes them with decode.
+// The codecs take precedence over the attributevalue encoding, and apply to the Update values of type V.
+// They apply to the fields of the entity and of its embedded structs only, not to the values nested in the struct,
+// slice or map fields. The fields tagged omitempty are omitted when they are zero values.
+func WithCodec[V any](encode func(V) (types.AttributeValue, error), decode func(types.AttributeValue) (V, error)) Option {
+	return func(o *options) {
+		if o.codecs == nil {
+			o.codecs = make(map[reflect.Type]valueCodec)
+		}
+
+		o.codecs[reflect.TypeOf((*V)(nil)).Elem()] = valueCodec{
+			encode: func(v reflect.Value) (types.AttributeValue, error) {
+				return encode(v.Interface().(V))
+			},
+			decode: func(av types.AttributeValue, v reflect.Value) error {
+				value, err := decode(av)
+				if err != nil {
+					return err
+				}
+				v.Set(reflect.ValueOf(&value).Elem())
+				return nil
+			},
+		}
+	}
+}
+
+// textUnmarshaler the pointers to V decoding text.
+type textUnmarshaler[V any] interface {
+	*V
+	encoding.TextUnmarshaler
+}
+
+// WithTextCodec encodes the entity fields of type V as strings, with their MarshalText and UnmarshalText methods
+// (e.g. uuid.UUID or netip.Addr).
+func WithTextCodec[V encoding.TextMarshaler, P textUnmarshaler[V]]() Option {
+	return WithCodec(
+		func(value V) (types.AttributeValue, error) {
+			text, err := value.MarshalText()
+			if err != nil {
+				return nil, err
+			}
+			return &types.AttributeValueMemberS{Value: string(text)}, nil
+		},
+		func(av types.AttributeValue) (V, error) {
+			return unmarshalText[V, P](av)
+		},
+	)
+}
+
+// WithNumberCodec encodes the entity fields of type V as numbers, with their MarshalText and UnmarshalText methods
+// (e.g. a decimal type).
+func WithNumberCodec[V encoding.TextMarshaler, P textUnmarshaler[V]]() Option {
+	return WithCodec(
+		func(value V) (types.AttributeValue, error) {
+			text, err := value.MarshalText()
+			if err != nil {
+				return nil, err
+			}
+
+			n, err := formatNumber(string(text))
+			if err != nil {
+				return nil, err
+			}
+			return &types.AttributeValueMemberN{Value: n}, nil
+		},
+		func(av types.AttributeValue) (V, error) {
+			return unmarshalText[V, P](av)
+		},
+	)
+}
+
+func unmarshalText[V any, P textUnmarshaler[V]](av types.AttributeValue) (V, error) {
+	var value V
+
+	var text string
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		text = v.Value
+	case *types.AttributeValueMemberN:
+		text = v.Value
+	default:
+		return value, fmt.Errorf("cannot decode %T as text", av)
+	}
+
+	err := P(&value).UnmarshalText([]byte(text))
+	return value, err
+}
+
+// valueCodec the encoding of the values of a registered type.
+type valueCodec struct {
+	encode func(v reflect.Value) (types.AttributeValue, error)
+	decode func(av types.AttributeValue, v reflect.Value) error
+}
+
+// codecField an entity field encoded by a registered codec.
+type codecField struct {
+	entityField
+	codec valueCodec
+	// pointer whether the field is a pointer to the registered type.
+	pointer bool
+	// omitEmpty whether the field is tagged omitempty.
+	omitEmpty bool
+}
+
+// entityCodec marshals and unmarshals the entities with the configured attributevalue options and codecs.
+type entityCodec struct {
+	encoder   *attributevalue.Encoder
+	decoder   *attributevalue.Decoder
+	omitEmpty bool
+	codecs    map[reflect.Type]valueCodec
+	fields    []codecField
+	// byAttribute the fields encoded by a codec by attribute name.
+	byAttribute map[string]codecField
+}
+
+func newEntityCodec[T Entity](o options, names attributeNames) entityCodec {
+	tagKey := o.entityTagKey()
+
+	c := entityCodec{
+		encoder: attributevalue.NewEncoder(append(o.encoderOptions[:len(o.encoderOptions):len(o.encoderOptions)],
+			func(eo *attributevalue.EncoderOptions) { eo.TagKey = tagKey })...),
+		decoder: attributevalue.NewDecoder(append(o.decoderOptions[:len(o.decoderOptions):len(o.decoderOptions)],
+			func(do *attributevalue.DecoderOptions) { do.TagKey = tagKey })...),
+		omitEmpty: o.omitEmpty,
+		codecs:    o.codecs,
+	}
+
+	for _, field := range entityFields[T](tagKey) {
+		attribute := names.attribute(field.Name)
+
+		// the time encoding of a field takes precedence over the codec of its type
+		var f codecField
+		if encoding := o.timeEncodingOf(field, attribute); encoding != 0 {
+			f = codecField{entityField: field, codec: timeCodec(encoding), pointer: field.Type != timeType}
+		} else if codec, ok := c.codecs[field.Type]; ok {
+			f = codecField{entityField: field, codec: codec}
+		} else if codec, ok := c.codecs[indirect(field.Type)]; ok && field.Type.Kind() == reflect.Pointer {
+			f = codecField{entityField: field, codec: codec, pointer: true}
+		} else {
+			continue
+		}
+
+		_, opts := fieldTag(field.StructField, tagKey)
+		f.omitEmpty = strings.Contains(","+opts+",", ",omitempty,")
+
+		c.fields = append(c.fields, f)
+		if c.byAttribute == nil {
+			c.byAttribute = make(map[string]codecField)
+		}
+		c.byAttribute[attribute] = f
+	}
+
+	return c
+}
+
+// entityTagKey returns the tag key of the entity fields.
+func (o options) entityTagKey() string {
+	if o.tagKey != "" {
+		return o.tagKey
+	}
+
+	return "dynamodbav"
+}
+
+// encode marshals an entity into its attributes.
+func (c entityCodec) encode(entity interface{}) (map[string]types.AttributeValue, error) {
+	av, err := c.encoder.Encode(entity)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMarshal, err)
+	}
+
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok {
+		return nil, ErrMarshal
+	}
+
+	v := reflect.ValueOf(entity)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	for _, field := range c.fields {
+		fv, err := v.FieldByIndexErr(field.index)
+		if err != nil {
+			// the field of a nil embedded struct
+			continue
+		}
+
+		// the fields tagged omitempty are omitted when the encoder did not emit them or when they are zero values
+		// (e.g. a nil pointer, a zero time or address)
+		if _, emitted := m.Value[field.marshaled]; field.omitEmpty && (!emitted || fv.IsZero()) {
+			delete(m.Value, field.marshaled)
+			continue
+		}
+
+		if field.pointer {
+			if fv.IsNil() {
+				m.Value[field.marshaled] = &types.AttributeValueMemberNULL{Value: true}
+				continue
+			}
+			fv = fv.Elem()
+		}
+
+		if m.Value[field.marshaled], err = field.codec.encode(fv); err != nil {
+			return nil, fmt.Errorf("%w: field %s: %w", ErrMarshal, field.Name, err)
+		}
+	}
+
+	return m.Value, nil
+}
+
+// omit removes the null and empty attributes of an item when configured with WithOmitEmpty.
+func (c entityCodec) omit(item map[string]types.AttributeValue) {
+	if !c.omitEmpty {
+		return
+	}
+
+	for name, value := range item {
+		if isEmptyValue(value) {
+			delete(item, name)
+		}
+	}
+}
+
+// decode unmarshals the attributes of an entity into out, a pointer to the entity.
+func (c entityCodec) decode(item map[string]types.AttributeValue, out interface{}) error {
+	plain := item
+	if len(c.fields) > 0 {
+		plain = make(map[string]types.AttributeValue, len(item))
+		for name, value := range item {
+			plain[name] = value
+		}
+		for _, field := range c.fields {
+			delete(plain, field.marshaled)
+		}
+	}
+
+	if err := c.decoder.Decode(&types.AttributeValueMemberM{Value: plain}, out); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnmarshal, err)
+	}
+
+	if len(c.fields) == 0 {
+		return nil
+	}
+
+	v := reflect.ValueOf(out).Elem()
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	for _, field := range c.fields {
+		av, ok := item[field.marshaled]
+		if _, null := av.(*types.AttributeValueMemberNULL); !ok || null {
+			continue
+		}
+
+		fv, err := v.FieldByIndexErr(field.index)
+		if err != nil {
+			continue
+		}
+
+		if field.pointer {
+			fv.Set(reflect.New(field.Type.Elem()))
+			fv = fv.Elem()
+		}
+
+		if err := field.codec.decode(av, fv); err != nil {
+			return fmt.Errorf("%w: field %s: %w", ErrUnmarshal, field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// value encodes a value of a registered type, the other values being returned unchanged.
+func (c entityCodec) value(value interface{}) (interface{}, error) {
+	if value == nil {
+		return value, nil
+	}
+
+	codec, ok := c.codecs[reflect.TypeOf(value)]
+	if !ok {
+		return value, nil
+	}
+
+	return codec.encode(reflect.ValueOf(value))
+}
+
+// fieldValue encodes a value of the field named attribute with the codec of the field, ok being false if the field
+// has no codec or the value is not of the field type.
+func (c entityCodec) fieldValue(attribute string, value interface{}) (_ types.AttributeValue, ok bool, err error) {
+	field, found := c.byAttribute[attribute]
+	if !found || value == nil {
+		return nil, false, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if field.pointer && v.Type() == field.Type {
+		if v.IsNil() {
+			return &types.AttributeValueMemberNULL{Value: true}, true, nil
+		}
+		v = v.Elem()
+	}
+
+	valueType := field.Type
+	if field.pointer {
+		valueType = field.Type.Elem()
+	}
+	if v.Type() != valueType {
+		return nil, false, nil
+	}
+
+	av, err := field.codec.encode(v)
+	return av, true, err
+}
+
+// isEmptyValue checks whether an attribute is null or empty.
+func isEmptyValue(av types.AttributeValue) bool {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberNULL:
+		return true
+	case *types.AttributeValueMemberS:
+		return v.Value == ""
+	case *types.AttributeValueMemberB:
+		return len(v.Value) == 0
+	case *types.AttributeValueMemberSS:
+		return len(v.Value) == 0
+	case *types.AttributeValueMemberNS:
+		return len(v.Value) == 0
+	case *types.AttributeValueMemberBS:
+		return len(v.Value) == 0
+	case *types.AttributeValueMemberL:
+		return len(v.Value) == 0
+	case *types.AttributeValueMemberM:
+		return len(v.Value) == 0
+	}
+
+	return false
+}
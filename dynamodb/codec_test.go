@@ -0,0 +1,181 @@
+package dy_test
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// money a decimal amount of cents.
+type money int64
+
+func (m money) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%02d", m/100, m%100)), nil
+}
+
+func (m *money) UnmarshalText(text []byte) error {
+	f, err := strconv.ParseFloat(string(text), 64)
+	*m = money(f*100 + 0.5)
+	return err
+}
+
+type device struct {
+	ID      uuid.UUID   `json:"id"`
+	Addr    netip.Addr  `json:"addr"`
+	Gateway *netip.Addr `json:"gateway"`
+	Balance money       `json:"balance"`
+	Note    string      `json:"note"`
+	SeenAt  time.Time   `json:"seenAt"`
+}
+
+func (d device) IsEmpty() bool {
+	return d.ID == uuid.Nil
+}
+
+var devicesConfig = dy.DBConfig{
+	TableInfo: dy.TableInfo{
+		TableName: "devices",
+		PrimaryKey: dy.DBPrimaryKeyNames{
+			PartitionKey: dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+		},
+	},
+}
+
+func TestDynamodb_Codecs(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, devicesConfig))
+
+	db := dy.NewClient[device](client, devicesConfig,
+		dy.WithTagKey("json"),
+		dy.WithOmitEmpty(),
+		dy.WithTextCodec[uuid.UUID](),
+		dy.WithTextCodec[netip.Addr](),
+		dy.WithNumberCodec[money](),
+		dy.WithEncoderOptions(func(o *attributevalue.EncoderOptions) {
+			o.EncodeTime = func(t time.Time) (types.AttributeValue, error) {
+				return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}, nil
+			}
+		}),
+	)
+
+	id := uuid.New()
+	seenAt := time.Unix(1700000000, 0)
+	key, err := db.Create(ctx, device{ID: id, Addr: netip.MustParseAddr("10.0.0.1"), Balance: 1234, SeenAt: seenAt})
+	require.NoError(t, err)
+	assert.Equal(t, id.String(), key.PartitionKey.Value)
+
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("devices"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id.String()}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"id":      &types.AttributeValueMemberS{Value: id.String()},
+		"addr":    &types.AttributeValueMemberS{Value: "10.0.0.1"},
+		"balance": &types.AttributeValueMemberN{Value: "12.34"},
+		"seenAt":  &types.AttributeValueMemberN{Value: "1700000000"},
+	}, out.Item)
+
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, device{ID: id, Addr: netip.MustParseAddr("10.0.0.1"), Balance: 1234, SeenAt: seenAt}, *item)
+
+	// the update values are encoded by the codecs
+	gateway := netip.MustParseAddr("10.0.0.254")
+	require.NoError(t, db.Update(ctx, key, []dy.DynamoAttribute{{KeyName: "gateway", Value: gateway}}))
+
+	items, _, err := db.GetItems(ctx, []dy.DynamoPrimaryKey{key})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.NotNil(t, items[0].Gateway)
+	assert.Equal(t, gateway, *items[0].Gateway)
+}
+
+func TestDynamodb_Codecs_Errors(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, devicesConfig))
+
+	failing := dy.WithCodec(
+		func(netip.Addr) (types.AttributeValue, error) { return nil, assert.AnError },
+		func(types.AttributeValue) (netip.Addr, error) { return netip.Addr{}, assert.AnError },
+	)
+	db := dy.NewClient[device](client, devicesConfig, dy.WithTagKey("json"), dy.WithTextCodec[uuid.UUID](), failing)
+
+	_, err := db.Create(ctx, device{ID: uuid.New()})
+	assert.ErrorIs(t, err, dy.ErrMarshal)
+	assert.ErrorIs(t, err, assert.AnError)
+
+	id := uuid.New()
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String("devices"),
+		Item: map[string]types.AttributeValue{
+			"id":   &types.AttributeValueMemberS{Value: id.String()},
+			"addr": &types.AttributeValueMemberS{Value: "10.0.0.1"},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = db.GetItem(ctx, dy.DynamoPrimaryKey{PartitionKey: dy.NewDynamoStringAttrib("id", id.String())})
+	assert.ErrorIs(t, err, dy.ErrUnmarshal)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+type router struct {
+	ID      string      `json:"id"`
+	Addr    netip.Addr  `json:"addr,omitempty"`
+	Gateway *netip.Addr `json:"gateway,omitempty"`
+	DNS     *netip.Addr `json:"dns"`
+	SeenAt  time.Time   `json:"seenAt,omitempty"`
+}
+
+func (r router) IsEmpty() bool {
+	return r.ID == ""
+}
+
+func TestDynamodb_Codecs_OmitEmpty(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, devicesConfig))
+
+	db := dy.NewClient[router](client, devicesConfig,
+		dy.WithTagKey("json"), dy.WithTextCodec[netip.Addr](), dy.WithTimeEncoding(dy.TimeEpochSeconds))
+
+	// the empty fields tagged omitempty are omitted, the other ones being written
+	_, err := db.Create(ctx, router{ID: "r1"})
+	require.NoError(t, err)
+
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("devices"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "r1"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"id":  &types.AttributeValueMemberS{Value: "r1"},
+		"dns": &types.AttributeValueMemberNULL{Value: true},
+	}, out.Item)
+
+	gateway := netip.MustParseAddr("10.0.0.254")
+	r := router{ID: "r2", Addr: netip.MustParseAddr("10.0.0.1"), Gateway: &gateway, SeenAt: time.Unix(1700000000, 0).UTC()}
+	key, err := db.Create(ctx, r)
+	require.NoError(t, err)
+
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, r, *item)
+}
@@ -5,25 +5,33 @@ import (
 	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Create inserts a new item into dynamodb table.
-func (d *DB[T]) Create(ctx context.Context, entity T) (DynamoPrimaryKey, error) {
-	dbMap, err := attributevalue.Marshal(entity)
+func (d *DB[T]) Create(ctx context.Context, entity T) (_ DynamoPrimaryKey, err error) {
+	var primaryKey *DynamoPrimaryKey
+
+	ctx, span := d.startSpan(ctx, "Create", OperationPutItem, nil)
+	defer func() {
+		err = d.opError("Create", "", primaryKey, err)
+		endSpan(span, err)
+	}()
+
+	marshaled, err := d.codec.encode(entity)
 	if err != nil {
 		return DynamoPrimaryKey{}, err
 	}
 
-	m, ok := dbMap.(*types.AttributeValueMemberM)
-	if !ok {
-		return DynamoPrimaryKey{}, fmt.Errorf("failed to marshal entity")
+	item := d.names.encode(marshaled)
+	// the timestamps are set before the keys are extracted, as they may be index keys
+	if err := d.stamp(item); err != nil {
+		return DynamoPrimaryKey{}, err
 	}
 
-	entityAsMap := toLowerCaseKeys(m.Value)
-	partKey, err := addPrimaryKey(entityAsMap, d.conf.TableInfo.PrimaryKey.PartitionKey)
+	partKey, err := addPrimaryKey(item, d.conf.TableInfo.PrimaryKey.PartitionKey,
+		d.conf.keyGenerator(d.conf.TableInfo.PrimaryKey.PartitionKey.Name, d.keys))
 
 	if err != nil {
 		return DynamoPrimaryKey{}, err
@@ -32,33 +40,53 @@ func (d *DB[T]) Create(ctx context.Context, entity T) (DynamoPrimaryKey, error)
 	var sortKey *DynamoAttribute
 	// if the table config mandate a sort key and the sort key is not provided then we create it
 	if d.conf.TableInfo.PrimaryKey.SortKey != nil {
-		sKey, err := addPrimaryKey(entityAsMap, *d.conf.TableInfo.PrimaryKey.SortKey)
+		sKey, err := addPrimaryKey(item, *d.conf.TableInfo.PrimaryKey.SortKey,
+			d.conf.keyGenerator(d.conf.TableInfo.PrimaryKey.SortKey.Name, d.keys))
 		if err != nil {
 			return DynamoPrimaryKey{}, err
 		}
 		sortKey = &sKey
 	}
 
+	primaryKey = &DynamoPrimaryKey{
+		PartitionKey: partKey,
+		SortKey:      sortKey,
+	}
+	d.codec.omit(item)
+
 	// create the put request
 	input := dynamodb.PutItemInput{
-		Item:      m.Value,
+		Item:      item,
 		TableName: aws.String(d.conf.TableInfo.TableName),
 	}
 
 	// triggering the put operation
-	_, err = d.client.PutItem(ctx, &input)
+	version := d.cache.current()
+	out, err := d.client.PutItem(ctx, &input)
 	if err != nil {
 		return DynamoPrimaryKey{}, err
 	}
 
-	return DynamoPrimaryKey{
-		PartitionKey: partKey,
-		SortKey:      sortKey,
-	}, nil
+	recordConsumedCapacity(span, out.ConsumedCapacity)
+
+	// refresh the cached item
+	if key, ok := d.cacheKeyOf(*primaryKey); ok {
+		d.cache.set(ctx, version, key, item)
+	}
+
+	return *primaryKey, nil
 }
 
 // Update updates an item.
-func (d *DB[T]) Update(ctx context.Context, primaryKey DynamoPrimaryKey, values []DynamoAttribute) error {
+func (d *DB[T]) Update(ctx context.Context, primaryKey DynamoPrimaryKey, values []DynamoAttribute) (err error) {
+	ctx, span := d.startSpan(ctx, "Update", OperationUpdateItem, nil)
+	defer func() {
+		err = d.opError("Update", "", &primaryKey, err)
+		endSpan(span, err)
+	}()
+
+	primaryKey = d.primaryKey(primaryKey)
+
 	// prepare the partition and the sort keys
 	partKey, sortKey, err := preparePartSortKey(primaryKey)
 	if err != nil {
@@ -68,8 +96,20 @@ func (d *DB[T]) Update(ctx context.Context, primaryKey DynamoPrimaryKey, values
 	// initialize the update-item input builder
 	builder := NewExpressionBuilder(d.conf.TableInfo.TableName).WithPartitionKey(partKey).WithSortKey(sortKey)
 	// populate the update data
+	updated := make(map[string]bool, len(values))
 	for _, attr := range values {
-		builder.WithUpdateField(string(attr.KeyName), attr.Value)
+		name := d.names.attribute(string(attr.KeyName))
+		value, err := d.updateValue(name, attr)
+		if err != nil {
+			return err
+		}
+		builder.WithUpdateField(name, value)
+		updated[name] = true
+	}
+
+	// refresh the timestamps
+	if err := d.stampUpdate(builder, updated); err != nil {
+		return err
 	}
 
 	// create the update item input
@@ -79,12 +119,49 @@ func (d *DB[T]) Update(ctx context.Context, primaryKey DynamoPrimaryKey, values
 	}
 
 	// trigger the update request
-	_, err = d.client.UpdateItem(ctx, req)
-	return err
+	out, err := d.client.UpdateItem(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	recordConsumedCapacity(span, out.ConsumedCapacity)
+
+	// invalidate the cached item
+	d.invalidate(ctx, span, primaryKey)
+	return nil
+}
+
+// updateValue returns the value of an updated attribute, encoded by the codec of its field if any.
+func (d *DB[T]) updateValue(name string, attr DynamoAttribute) (interface{}, error) {
+	av, ok, err := d.codec.fieldValue(name, attr.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrMarshal, attr.KeyName, err)
+	}
+	if ok {
+		return av, nil
+	}
+
+	value, err := expressionValue(attr)
+	if err != nil {
+		return nil, err
+	}
+	if value, err = d.codec.value(value); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrMarshal, attr.KeyName, err)
+	}
+
+	return value, nil
 }
 
 // Delete deletes an item.
-func (d *DB[T]) Delete(ctx context.Context, primaryKey DynamoPrimaryKey) error {
+func (d *DB[T]) Delete(ctx context.Context, primaryKey DynamoPrimaryKey) (err error) {
+	ctx, span := d.startSpan(ctx, "Delete", OperationDeleteItem, nil)
+	defer func() {
+		err = d.opError("Delete", "", &primaryKey, err)
+		endSpan(span, err)
+	}()
+
+	primaryKey = d.primaryKey(primaryKey)
+
 	// prepare the partition and the sort keys
 	partKey, sortKey, err := preparePartSortKey(primaryKey)
 	if err != nil {
@@ -101,6 +178,27 @@ func (d *DB[T]) Delete(ctx context.Context, primaryKey DynamoPrimaryKey) error {
 	}
 
 	// call dynamo delete item
-	_, err = d.client.DeleteItem(ctx, req)
-	return err
+	out, err := d.client.DeleteItem(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	recordConsumedCapacity(span, out.ConsumedCapacity)
+
+	// invalidate the cached item
+	d.invalidate(ctx, span, primaryKey)
+	return nil
+}
+
+// invalidate removes the cached item of a written item. The invalidation errors are recorded on the span only,
+// as the write succeeded.
+func (d *DB[T]) invalidate(ctx context.Context, span trace.Span, primaryKey DynamoPrimaryKey) {
+	key, ok := d.cacheKeyOf(primaryKey)
+	if !ok {
+		return
+	}
+
+	if err := d.cache.invalidate(ctx, key); err != nil {
+		span.RecordError(fmt.Errorf("invalidating the cached item: %w", err))
+	}
 }
@@ -2,17 +2,23 @@ package dy_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
 	"testing"
 
 	"github.com/AhmedBenCharrada/awsgo/mocks"
 
 	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDynamodb_Create(t *testing.T) {
@@ -360,3 +366,78 @@ func TestDynamodb_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdate_Collections(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, dbConfig))
+
+	db := dy.NewClient[entity](client, dbConfig)
+	key := dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1"),
+		SortKey:      &dy.DynamoAttribute{KeyName: "id", Type: dy.String, Value: "u1"},
+	}
+
+	err := db.Update(ctx, key, []dy.DynamoAttribute{
+		dy.NewDynamoStringSetAttrib("tags", "a", "b"),
+		dy.NewDynamoNumberSetAttrib("scores", "1", "2.5"),
+		dy.NewDynamoBinarySetAttrib("hashes", []byte{1}, []byte{2}),
+		dy.NewDynamoListAttrib("history", "created", 1, dy.NewDynamoStringSetAttrib("", "x")),
+		dy.NewDynamoMapAttrib("address", map[string]interface{}{"city": "Paris", "zip": dy.NewDynamoNumberAttrib("", "75001")}),
+		{KeyName: "list", Value: []string{"a"}},
+	})
+	require.NoError(t, err)
+
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("tableName"),
+		Key: map[string]types.AttributeValue{
+			"groupID": &types.AttributeValueMemberN{Value: "1"},
+			"id":      &types.AttributeValueMemberS{Value: "u1"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &types.AttributeValueMemberSS{Value: []string{"a", "b"}}, out.Item["tags"])
+	assert.Equal(t, &types.AttributeValueMemberNS{Value: []string{"1", "2.5"}}, out.Item["scores"])
+	assert.Equal(t, &types.AttributeValueMemberBS{Value: [][]byte{{1}, {2}}}, out.Item["hashes"])
+	assert.Equal(t, &types.AttributeValueMemberL{Value: []types.AttributeValue{
+		&types.AttributeValueMemberS{Value: "created"},
+		&types.AttributeValueMemberN{Value: "1"},
+		&types.AttributeValueMemberSS{Value: []string{"x"}},
+	}}, out.Item["history"])
+	assert.Equal(t, &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"city": &types.AttributeValueMemberS{Value: "Paris"},
+		"zip":  &types.AttributeValueMemberN{Value: "75001"},
+	}}, out.Item["address"])
+
+	// the untyped slices are still marshaled as lists
+	assert.Equal(t, &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "a"}}}, out.Item["list"])
+
+	err = db.Update(ctx, key, []dy.DynamoAttribute{dy.NewDynamoStringSetAttrib("tags")})
+	assert.ErrorContains(t, err, "StringSet cannot be empty")
+
+	// the number set elements are formatted as the number attributes
+	err = db.Update(ctx, key, []dy.DynamoAttribute{{KeyName: "scores", Type: dy.NumberSet, Value: []interface{}{
+		float32(0.1), big.NewInt(0).Lsh(big.NewInt(1), 70), big.NewFloat(1.5), json.Number("1e3"), 7,
+	}}})
+	require.NoError(t, err)
+
+	out, err = client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("tableName"),
+		Key: map[string]types.AttributeValue{
+			"groupID": &types.AttributeValueMemberN{Value: "1"},
+			"id":      &types.AttributeValueMemberS{Value: "u1"},
+		},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"0.1", "1180591620717411303424", "1.5", "1e3", "7"},
+		out.Item["scores"].(*types.AttributeValueMemberNS).Value)
+
+	for _, invalid := range []interface{}{math.NaN(), math.Inf(1), "12a"} {
+		err = db.Update(ctx, key, []dy.DynamoAttribute{{KeyName: "scores", Type: dy.NumberSet, Value: []interface{}{1, invalid}}})
+		assert.Error(t, err, "%v", invalid)
+	}
+
+	err = db.Update(ctx, key, []dy.DynamoAttribute{{KeyName: "tags", Type: dy.Map, Value: []string{"a"}}})
+	assert.Error(t, err)
+}
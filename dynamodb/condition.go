@@ -1,6 +1,10 @@
 package dy
 
-import "github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+)
 
 // Operator the conditional operator. Used to build the conditional expression for read/write operations.
 type Operator int
@@ -21,57 +25,143 @@ const (
 // Criteria ..
 type Criteria struct {
 	isEmpty bool
-	builder expression.ConditionBuilder
+	// build builds the conditions, the attribute names and the compared values being resolved by resolve.
+	build func(resolve resolveFunc) expression.ConditionBuilder
+	// resolve resolves the attribute names (e.g. the field names of the entity) and the compared values.
+	resolve resolveFunc
+	err     error
 }
 
+// resolveFunc resolves the attribute name and the compared value of a condition.
+type resolveFunc func(name string, value interface{}) (string, interface{})
+
 // NewCriteria ..
 func NewCriteria() *Criteria {
 	return &Criteria{
 		isEmpty: true,
-		builder: expression.ConditionBuilder{},
 	}
 }
 
 // GetExpression returns dynamo Filter Expressions
 func (cb *Criteria) GetExpression() expression.ConditionBuilder {
-	return cb.builder
+	if cb.empty() {
+		return expression.ConditionBuilder{}
+	}
+
+	resolve := cb.resolve
+	if resolve == nil {
+		resolve = func(name string, value interface{}) (string, interface{}) { return name, value }
+	}
+
+	return cb.build(resolve)
 }
 
-// Or applies the OR condition for the dynamo attribute
-func (cb *Criteria) Or(attribName string, value interface{}, operator Operator) *Criteria {
-	if cb.isEmpty {
-		cb.builder = create(attribName, value, operator)
-		cb.isEmpty = false
-		return cb
+// withResolver returns a copy of the criteria whose attribute names and compared values are resolved by resolve,
+// the first resolution error being reported as the criteria error.
+func (cb *Criteria) withResolver(resolve func(name string, value interface{}) (string, interface{}, error)) *Criteria {
+	resolved := *cb
+	resolved.resolve = func(name string, value interface{}) (string, interface{}) {
+		attribute, v, err := resolve(name, value)
+		if err != nil {
+			return attribute, value
+		}
+		return attribute, v
 	}
 
-	cond := cb.builder.Or(create(attribName, value, operator))
-	cb.builder = cond
-	return cb
+	if !resolved.empty() && resolved.err == nil {
+		resolved.build(func(name string, value interface{}) (string, interface{}) {
+			attribute, v, err := resolve(name, value)
+			if err != nil && resolved.err == nil {
+				resolved.err = fmt.Errorf("criteria on %s: %w", name, err)
+			}
+			return attribute, v
+		})
+	}
+
+	return &resolved
+}
+
+// Or applies the OR condition for the dynamo attribute.
+// The value can be a DynamoAttribute (e.g. NewDynamoStringSetAttrib), compared with its typed value.
+func (cb *Criteria) Or(attribName string, value interface{}, operator Operator) *Criteria {
+	return cb.combine(attribName, cb.value(value), operator, expression.ConditionBuilder.Or)
 }
 
-// And applies the AND condition for the dynamo attribute
+// And applies the AND condition for the dynamo attribute.
+// The value can be a DynamoAttribute (e.g. NewDynamoStringSetAttrib), compared with its typed value.
 func (cb *Criteria) And(attribName string, value interface{}, operator Operator) *Criteria {
-	if cb.isEmpty {
-		cb.builder = create(attribName, value, operator)
+	return cb.combine(attribName, cb.value(value), operator, expression.ConditionBuilder.And)
+}
+
+func (cb *Criteria) combine(attribName string, value interface{}, operator Operator,
+	join func(expression.ConditionBuilder, expression.ConditionBuilder, ...expression.ConditionBuilder) expression.ConditionBuilder,
+) *Criteria {
+	if cb.empty() {
+		cb.build = func(resolve resolveFunc) expression.ConditionBuilder {
+			name, v := resolve(attribName, value)
+			return create(name, v, operator)
+		}
 		cb.isEmpty = false
 		return cb
 	}
 
-	cond := cb.builder.And(create(attribName, value, operator))
-	cb.builder = cond
+	previous := cb.build
+	cb.build = func(resolve resolveFunc) expression.ConditionBuilder {
+		name, v := resolve(attribName, value)
+		return join(previous(resolve), create(name, v, operator))
+	}
 	return cb
 }
 
+func (cb *Criteria) empty() bool {
+	return cb.isEmpty || cb.build == nil
+}
+
 // Merge applies the logical And clause for all conditions.
 func (cb *Criteria) Merge(conditions ...Criteria) *Criteria {
 	for _, cond := range conditions {
-		cb.builder.And(cond.builder)
+		if cb.err == nil {
+			cb.err = cond.err
+		}
+
+		if cond.empty() {
+			continue
+		}
+
+		if cb.empty() {
+			cb.build, cb.isEmpty = cond.build, false
+			continue
+		}
+
+		previous, other := cb.build, cond.build
+		cb.build = func(resolve resolveFunc) expression.ConditionBuilder {
+			return previous(resolve).And(other(resolve))
+		}
 	}
 
 	return cb
 }
 
+// value returns the compared value, the conversion error being reported when building the expression.
+func (cb *Criteria) value(value interface{}) interface{} {
+	var attr DynamoAttribute
+	switch v := value.(type) {
+	case DynamoAttribute:
+		attr = v
+	case *DynamoAttribute:
+		attr = *v
+	default:
+		return value
+	}
+
+	v, err := expressionValue(attr)
+	if err != nil && cb.err == nil {
+		cb.err = fmt.Errorf("criteria on %s: %w", attr.KeyName, err)
+	}
+
+	return v
+}
+
 func create(attribName string, value interface{}, operator Operator) expression.ConditionBuilder {
 	switch operator {
 	case LT:
@@ -1,10 +1,17 @@
 package dy_test
 
 import (
+	"context"
 	"testing"
 
 	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateCriteria(t *testing.T) {
@@ -32,3 +39,47 @@ func TestCreateCriteria(t *testing.T) {
 	e := f.GetExpression()
 	assert.NotEmpty(t, e)
 }
+
+func TestCriteria_Collections(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, dbConfig))
+
+	for id, tags := range map[string][]string{"u1": {"a", "b"}, "u2": {"b"}} {
+		_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+			TableName: aws.String("tableName"),
+			Item: map[string]types.AttributeValue{
+				"groupID": &types.AttributeValueMemberN{Value: "1"},
+				"id":      &types.AttributeValueMemberS{Value: id},
+				"tags":    &types.AttributeValueMemberSS{Value: tags},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	db := dy.NewClient[entity](client, dbConfig)
+
+	// a []string value is compared as a list, never matching a string set
+	page, err := db.Find(ctx, dy.Request{Size: 10, Conditions: []dy.Criteria{*dy.NewCriteria().And("tags", []string{"b"}, dy.EQUAL)}})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+
+	page, err = db.Find(ctx, dy.Request{Size: 10, Conditions: []dy.Criteria{*dy.NewCriteria().And("tags", dy.NewDynamoStringSetAttrib("tags", "b"), dy.EQUAL)}})
+	require.NoError(t, err)
+	if assert.Len(t, page.Items, 1) {
+		assert.Equal(t, "u2", page.Items[0].Id)
+	}
+
+	_, err = db.Find(ctx, dy.Request{Size: 10, Conditions: []dy.Criteria{*dy.NewCriteria().Or("tags", dy.NewDynamoStringSetAttrib("tags"), dy.EQUAL)}})
+	assert.ErrorContains(t, err, "criteria on tags: StringSet cannot be empty")
+}
+
+func TestCriteria_Merge(t *testing.T) {
+	merged := dy.NewCriteria().And("firstName", "f1", dy.EQUAL).
+		Merge(*dy.NewCriteria(), *dy.NewCriteria().And("lastName", "l1", dy.EQUAL))
+
+	in, err := dy.NewExpressionBuilder("tableName").BuildScanInput(nil, merged, nil, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "(#0 = :0) AND (#1 = :1)", aws.ToString(in.FilterExpression))
+	assert.Equal(t, map[string]string{"#0": "firstName", "#1": "lastName"}, in.ExpressionAttributeNames)
+}
@@ -1,5 +1,11 @@
 package dy
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
 // DBIndexName custom type for dynamo DB index name
 type DBIndexName string
 
@@ -7,6 +13,8 @@ type DBIndexName string
 type DynamoKeyMetadata struct {
 	Name DBKey
 	Type DBKeyType
+	// NumberType the Go type of the Number key values read from dynamodb. Defaults to NumberString.
+	NumberType NumberType
 }
 
 // DBPrimaryKeyNames custom type for dynamo primary key
@@ -24,5 +32,137 @@ type TableInfo struct {
 // DBConfig the dynamo DB table config
 type DBConfig struct {
 	TableInfo TableInfo
-	Indexes   map[DBIndexName]DBPrimaryKeyNames
+	// Indexes the global secondary indexes.
+	Indexes map[DBIndexName]DBPrimaryKeyNames
+	// LocalIndexes the local secondary indexes sort keys, the partition key being the table one.
+	LocalIndexes map[DBIndexName]DynamoKeyMetadata
+	// KeyGenerators the generators of the missing table keys of the created items, by key name.
+	// The keys without generator are generated by the NewDefaultKeyGenerator generator.
+	KeyGenerators map[DBKey]KeyGenerator
+	// Timestamps the attributes holding the creation and the last update times of the items, if any.
+	Timestamps Timestamps
+}
+
+// keyGenerator returns the generator of a table key, fallback if the key has no configured generator.
+func (c DBConfig) keyGenerator(key DBKey, fallback KeyGenerator) KeyGenerator {
+	if generator, ok := c.KeyGenerators[key]; ok {
+		return generator
+	}
+
+	return fallback
+}
+
+// Validate checks that the config describes a valid table: the table and the indexes have a named partition key,
+// the keys are String, Number or Binary keys, a key name is given a single type, and the local indexes extend a table
+// having a sort key, the key generators generate table keys, and the timestamps are distinct attributes other than the
+// table keys. The problems are reported together, wrapped into ErrInvalidConfig.
+func (c DBConfig) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if n := len(c.TableInfo.TableName); n < 3 || n > 255 {
+		fail("table name %q must be between 3 and 255 characters long", c.TableInfo.TableName)
+	}
+
+	keyTypes := make(map[DBKey]DBKeyType)
+	checkKey := func(where, role string, key *DynamoKeyMetadata) {
+		if key == nil {
+			return
+		}
+
+		if key.Name == "" {
+			fail("%s: missing %s key name", where, role)
+			return
+		}
+
+		if key.Type != String && key.Type != Number && key.Type != Binary {
+			fail("%s: %s key %q: %w %v, expecting String, Number or Binary", where, role, key.Name, ErrInvalidDBKeyType, key.Type)
+			return
+		}
+
+		if _, ok := numberTypeNames[key.NumberType]; !ok || (key.NumberType != NumberString && key.Type != Number) {
+			fail("%s: %s key %q: invalid number type %v of a %v key", where, role, key.Name, key.NumberType, key.Type)
+		}
+
+		if keyType, ok := keyTypes[key.Name]; ok && keyType != key.Type {
+			fail("%s: %s key %q: %w, the key is also defined as %v", where, role, key.Name, ErrInvalidDBKeyType, keyType)
+			return
+		}
+		keyTypes[key.Name] = key.Type
+	}
+	checkKeys := func(where string, keys DBPrimaryKeyNames) {
+		checkKey(where, "partition", &keys.PartitionKey)
+		checkKey(where, "sort", keys.SortKey)
+
+		if keys.SortKey != nil && keys.SortKey.Name == keys.PartitionKey.Name {
+			fail("%s: the partition and sort keys are both %q", where, keys.PartitionKey.Name)
+		}
+	}
+
+	primaryKey := c.TableInfo.PrimaryKey
+	checkKeys("table", primaryKey)
+
+	for _, name := range sortedIndexNames(c.Indexes) {
+		if name == "" {
+			fail("missing global index name")
+		}
+		checkKeys("global index "+string(name), c.Indexes[name])
+	}
+
+	for _, name := range sortedIndexNames(c.LocalIndexes) {
+		if name == "" {
+			fail("missing local index name")
+		}
+
+		if _, ok := c.Indexes[name]; ok {
+			fail("index %s is both global and local", name)
+		}
+
+		if primaryKey.SortKey == nil {
+			fail("local index %s: the table has no sort key", name)
+		}
+
+		sortKey := c.LocalIndexes[name]
+		checkKeys("local index "+string(name), DBPrimaryKeyNames{PartitionKey: primaryKey.PartitionKey, SortKey: &sortKey})
+	}
+
+	isTableKey := func(key DBKey) bool {
+		return key == primaryKey.PartitionKey.Name || (primaryKey.SortKey != nil && key == primaryKey.SortKey.Name)
+	}
+
+	generated := make([]DBKey, 0, len(c.KeyGenerators))
+	for key := range c.KeyGenerators {
+		generated = append(generated, key)
+	}
+	sort.Slice(generated, func(i, j int) bool { return generated[i] < generated[j] })
+
+	for _, key := range generated {
+		switch {
+		case !isTableKey(key):
+			fail("key generator of %q: not a table key", key)
+		case c.KeyGenerators[key] == nil:
+			fail("key generator of %q: nil generator", key)
+		}
+	}
+
+	timestamps := c.Timestamps
+	for _, key := range []DBKey{timestamps.CreatedAt, timestamps.UpdatedAt} {
+		if key != "" && isTableKey(key) {
+			fail("timestamp %q: a table key", key)
+		}
+	}
+	if timestamps.CreatedAt != "" && timestamps.CreatedAt == timestamps.UpdatedAt {
+		fail("timestamps: createdAt and updatedAt are both %q", timestamps.CreatedAt)
+	}
+	if _, ok := timeEncodingNames[timestamps.Encoding]; !ok && timestamps.Encoding != 0 {
+		fail("timestamps: invalid time encoding %v", timestamps.Encoding)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+	}
+
+	return nil
 }
@@ -0,0 +1,97 @@
+package dy_test
+
+import (
+	"testing"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBConfig_Validate(t *testing.T) {
+	assert.NoError(t, adminConfig.Validate())
+
+	conf := dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName: "u",
+			PrimaryKey: dy.DBPrimaryKeyNames{
+				PartitionKey: dy.DynamoKeyMetadata{Name: "id", Type: dy.Boolean},
+			},
+		},
+		Indexes: map[dy.DBIndexName]dy.DBPrimaryKeyNames{
+			"byEmail": {
+				PartitionKey: dy.DynamoKeyMetadata{Name: "email", Type: dy.String},
+				SortKey:      &dy.DynamoKeyMetadata{Name: "email", Type: dy.String},
+			},
+			"byAge":  {PartitionKey: dy.DynamoKeyMetadata{Name: "age", Type: dy.Number}},
+			"byName": {PartitionKey: dy.DynamoKeyMetadata{Type: dy.String}},
+		},
+		LocalIndexes: map[dy.DBIndexName]dy.DynamoKeyMetadata{
+			"byAge": {Name: "age", Type: dy.String},
+		},
+	}
+
+	err := conf.Validate()
+	assert.ErrorIs(t, err, dy.ErrInvalidConfig)
+	assert.ErrorIs(t, err, dy.ErrInvalidDBKeyType)
+	assert.EqualError(t, err, `invalid config: table name "u" must be between 3 and 255 characters long
+table: partition key "id": invalid key type Boolean, expecting String, Number or Binary
+global index byEmail: the partition and sort keys are both "email"
+global index byName: missing partition key name
+index byAge is both global and local
+local index byAge: the table has no sort key
+local index byAge: partition key "id": invalid key type Boolean, expecting String, Number or Binary
+local index byAge: sort key "age": invalid key type, the key is also defined as Number`)
+}
+
+func TestDBConfig_Validate_NumberType(t *testing.T) {
+	conf := dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName: "users",
+			PrimaryKey: dy.DBPrimaryKeyNames{
+				PartitionKey: dy.DynamoKeyMetadata{Name: "groupID", Type: dy.Number, NumberType: dy.NumberBigInt},
+				SortKey:      &dy.DynamoKeyMetadata{Name: "id", Type: dy.String, NumberType: dy.NumberInt64},
+			},
+		},
+		Indexes: map[dy.DBIndexName]dy.DBPrimaryKeyNames{
+			"byAge": {PartitionKey: dy.DynamoKeyMetadata{Name: "age", Type: dy.Number, NumberType: dy.NumberType(42)}},
+		},
+	}
+
+	err := conf.Validate()
+	assert.ErrorIs(t, err, dy.ErrInvalidConfig)
+	assert.ErrorContains(t, err, `sort key "id": invalid number type int64 of a String key`)
+	assert.ErrorContains(t, err, `partition key "age": invalid number type NumberType(42) of a Number key`)
+	assert.NotContains(t, err.Error(), "groupID")
+}
+
+func TestDBConfig_Validate_KeyGenerators(t *testing.T) {
+	conf := adminConfig
+	conf.KeyGenerators = map[dy.DBKey]dy.KeyGenerator{
+		"id":    dy.NewULIDGenerator(),
+		"email": dy.NewULIDGenerator(),
+		"age":   nil,
+	}
+
+	err := conf.Validate()
+	assert.ErrorIs(t, err, dy.ErrInvalidConfig)
+	assert.EqualError(t, err, `invalid config: key generator of "age": not a table key
+key generator of "email": not a table key`)
+
+	conf.KeyGenerators = map[dy.DBKey]dy.KeyGenerator{"id": nil}
+	assert.ErrorContains(t, conf.Validate(), `key generator of "id": nil generator`)
+}
+
+func TestDBConfig_Validate_Timestamps(t *testing.T) {
+	conf := adminConfig
+	conf.Timestamps = dy.Timestamps{CreatedAt: "createdAt", UpdatedAt: "updatedAt", Encoding: dy.TimeEpochMillis}
+	assert.NoError(t, conf.Validate())
+
+	conf.Timestamps = dy.Timestamps{CreatedAt: "id", UpdatedAt: "id", Encoding: dy.TimeEncoding(42)}
+	err := conf.Validate()
+	assert.ErrorIs(t, err, dy.ErrInvalidConfig)
+	assert.EqualError(t, err, `invalid config: timestamp "id": a table key
+timestamp "id": a table key
+timestamps: createdAt and updatedAt are both "id"
+timestamps: invalid time encoding TimeEncoding(42)`)
+}
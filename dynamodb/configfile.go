@@ -0,0 +1,308 @@
+package dy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadOption configures the loading of the config documents.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	naming func(name string) string
+	lookup func(name string) (string, bool)
+}
+
+// WithTablePrefix prefixes the loaded table names (e.g. "dev-" names the User table "dev-User").
+func WithTablePrefix(prefix string) LoadOption {
+	return WithTableNaming(func(name string) string { return prefix + name })
+}
+
+// WithTableSuffix suffixes the loaded table names (e.g. "-dev" names the User table "User-dev").
+func WithTableSuffix(suffix string) LoadOption {
+	return WithTableNaming(func(name string) string { return name + suffix })
+}
+
+// WithTableNaming renames the loaded tables. The naming functions are applied in order.
+func WithTableNaming(naming func(name string) string) LoadOption {
+	return func(o *loadOptions) {
+		previous := o.naming
+		o.naming = func(name string) string { return naming(previous(name)) }
+	}
+}
+
+// WithLookupEnv sets the lookup of the interpolated variables. Defaults to os.LookupEnv.
+func WithLookupEnv(lookup func(name string) (string, bool)) LoadOption {
+	return func(o *loadOptions) {
+		o.lookup = lookup
+	}
+}
+
+// configDocument the config document.
+type configDocument struct {
+	Tables map[string]tableDocument `yaml:"tables"`
+}
+
+type tableDocument struct {
+	TableName    string                        `yaml:"tableName"`
+	PartitionKey keyDocument                   `yaml:"partitionKey"`
+	SortKey      *keyDocument                  `yaml:"sortKey"`
+	Indexes      map[DBIndexName]indexDocument `yaml:"indexes"`
+	LocalIndexes map[DBIndexName]keyDocument   `yaml:"localIndexes"`
+	Timestamps   timestampsDocument            `yaml:"timestamps"`
+}
+
+type timestampsDocument struct {
+	CreatedAt DBKey        `yaml:"createdAt"`
+	UpdatedAt DBKey        `yaml:"updatedAt"`
+	Encoding  TimeEncoding `yaml:"encoding"`
+}
+
+type indexDocument struct {
+	PartitionKey keyDocument  `yaml:"partitionKey"`
+	SortKey      *keyDocument `yaml:"sortKey"`
+}
+
+type keyDocument struct {
+	Name       DBKey      `yaml:"name"`
+	Type       DBKeyType  `yaml:"type"`
+	NumberType NumberType `yaml:"numberType"`
+}
+
+func (k *keyDocument) metadata() *DynamoKeyMetadata {
+	if k == nil {
+		return nil
+	}
+
+	return &DynamoKeyMetadata{Name: k.Name, Type: k.Type, NumberType: k.NumberType}
+}
+
+// LoadConfigs loads the table configs of YAML or JSON documents, by their name in the documents:
+//
+//	tables:
+//	  users:
+//	    tableName: ${STAGE:-dev}-users
+//	    partitionKey: {name: groupID, type: Number, numberType: int64}
+//	    sortKey: {name: id, type: String}
+//	    indexes:
+//	      byEmail:
+//	        partitionKey: {name: email, type: String}
+//	    localIndexes:
+//	      byAge: {name: age, type: Number}
+//	    timestamps: {createdAt: createdAt, updatedAt: updatedAt, encoding: EpochSeconds}
+//
+// The table name defaults to the config name. The key type defaults to String, the number type to string, and the
+// timestamps encoding to RFC3339.
+//
+// The string values are interpolated: ${VAR} is replaced by the value of the VAR environment variable, which must be
+// set, ${VAR:-default} by default if VAR is unset or empty, and $$ by $.
+//
+// The loaded configs are validated, ErrInvalidConfig is returned if a config is invalid.
+func LoadConfigs(r io.Reader, opts ...LoadOption) (map[string]DBConfig, error) {
+	o := loadOptions{
+		naming: func(name string) string { return name },
+		lookup: os.LookupEnv,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	configs := make(map[string]DBConfig)
+	dec := yaml.NewDecoder(r)
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+
+		doc, err := decodeDocument(&node, o.lookup)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+
+		if err := addConfigs(configs, doc, o.naming); err != nil {
+			return nil, err
+		}
+	}
+
+	return configs, nil
+}
+
+// LoadConfigFiles loads the table configs of YAML or JSON files, see LoadConfigs.
+// The config names must be unique across the files.
+func LoadConfigFiles(paths []string, opts ...LoadOption) (map[string]DBConfig, error) {
+	configs := make(map[string]DBConfig)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		loaded, err := LoadConfigs(bytes.NewReader(data), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		for name, conf := range loaded {
+			if _, ok := configs[name]; ok {
+				return nil, fmt.Errorf("%w: %s: duplicate config %s", ErrInvalidConfig, path, name)
+			}
+			configs[name] = conf
+		}
+	}
+
+	return configs, nil
+}
+
+// decodeDocument interpolates the string values of the document then decodes it,
+// the unknown fields being rejected.
+func decodeDocument(node *yaml.Node, lookup func(string) (string, bool)) (configDocument, error) {
+	if err := interpolate(node, lookup); err != nil {
+		return configDocument{}, err
+	}
+
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return configDocument{}, err
+	}
+
+	var doc configDocument
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return configDocument{}, err
+	}
+
+	return doc, nil
+}
+
+func addConfigs(configs map[string]DBConfig, doc configDocument, naming func(string) string) error {
+	names := make([]string, 0, len(doc.Tables))
+	for name := range doc.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if _, ok := configs[name]; ok {
+			errs = append(errs, fmt.Errorf("%w: duplicate config %s", ErrInvalidConfig, name))
+			continue
+		}
+
+		table := doc.Tables[name]
+		tableName := table.TableName
+		if tableName == "" {
+			tableName = name
+		}
+
+		conf := DBConfig{
+			TableInfo: TableInfo{
+				TableName: naming(tableName),
+				PrimaryKey: DBPrimaryKeyNames{
+					PartitionKey: *table.PartitionKey.metadata(),
+					SortKey:      table.SortKey.metadata(),
+				},
+			},
+			Timestamps: Timestamps(table.Timestamps),
+		}
+
+		for index, keys := range table.Indexes {
+			if conf.Indexes == nil {
+				conf.Indexes = make(map[DBIndexName]DBPrimaryKeyNames)
+			}
+			conf.Indexes[index] = DBPrimaryKeyNames{PartitionKey: *keys.PartitionKey.metadata(), SortKey: keys.SortKey.metadata()}
+		}
+
+		for index, key := range table.LocalIndexes {
+			if conf.LocalIndexes == nil {
+				conf.LocalIndexes = make(map[DBIndexName]DynamoKeyMetadata)
+			}
+			conf.LocalIndexes[index] = *key.metadata()
+		}
+
+		if err := conf.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("config %s: %w", name, err))
+			continue
+		}
+		configs[name] = conf
+	}
+
+	return errors.Join(errs...)
+}
+
+// interpolate expands the variables of the scalar values of a node.
+func interpolate(node *yaml.Node, lookup func(string) (string, bool)) error {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		value, err := expandEnv(node.Value, lookup)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		node.Value = value
+	}
+
+	for _, child := range node.Content {
+		if err := interpolate(child, lookup); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expandEnv replaces ${VAR} and ${VAR:-default} by their value, and $$ by $.
+func expandEnv(s string, lookup func(string) (string, bool)) (string, error) {
+	var sb strings.Builder
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i == len(s)-1 {
+			sb.WriteString(s)
+			return sb.String(), nil
+		}
+
+		sb.WriteString(s[:i])
+		switch s[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			s = s[i+2:]
+			continue
+		case '{':
+		default:
+			sb.WriteByte('$')
+			s = s[i+1:]
+			continue
+		}
+
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable in %q", s)
+		}
+
+		name, fallback, hasFallback := strings.Cut(s[i+2:i+end], ":-")
+		if name == "" {
+			return "", fmt.Errorf("missing variable name in %q", s[i:i+end+1])
+		}
+
+		value, ok := lookup(name)
+		switch {
+		case hasFallback && value == "":
+			value = fallback
+		case !ok:
+			return "", fmt.Errorf("variable %s is not set", name)
+		}
+
+		sb.WriteString(value)
+		s = s[i+end+1:]
+	}
+}
@@ -0,0 +1,130 @@
+package dy_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const usersDocument = `
+tables:
+  users:
+    tableName: ${STAGE:-dev}-users
+    partitionKey: {name: groupID, type: Number, numberType: int64}
+    sortKey: {name: id, type: String}
+    indexes:
+      byEmail:
+        partitionKey: {name: email, type: S}
+    localIndexes:
+      byAge: {name: age, type: Number}
+  orders:
+    partitionKey: {name: "${ORDER_KEY}"}
+`
+
+func env(vars map[string]string) dy.LoadOption {
+	return dy.WithLookupEnv(func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	})
+}
+
+func TestLoadConfigs(t *testing.T) {
+	configs, err := dy.LoadConfigs(strings.NewReader(usersDocument), env(map[string]string{"ORDER_KEY": "orderID"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName:  "orders",
+			PrimaryKey: dy.DBPrimaryKeyNames{PartitionKey: dy.DynamoKeyMetadata{Name: "orderID", Type: dy.String}},
+		},
+	}, configs["orders"])
+
+	users := configs["users"]
+	assert.Equal(t, "dev-users", users.TableInfo.TableName)
+	users.TableInfo.TableName = adminConfig.TableInfo.TableName
+	assert.Equal(t, adminConfig, users)
+
+	configs, err = dy.LoadConfigs(strings.NewReader(usersDocument),
+		env(map[string]string{"STAGE": "prod", "ORDER_KEY": "orderID"}),
+		dy.WithTablePrefix("eu-"), dy.WithTableSuffix("-v2"))
+	require.NoError(t, err)
+	assert.Equal(t, "eu-prod-users-v2", configs["users"].TableInfo.TableName)
+	assert.Equal(t, "eu-orders-v2", configs["orders"].TableInfo.TableName)
+}
+
+func TestLoadConfigs_JSON(t *testing.T) {
+	doc := `{"tables": {"User": {"partitionKey": {"name": "ID", "type": "String"}}}}`
+
+	configs, err := dy.LoadConfigs(strings.NewReader(doc), dy.WithTablePrefix("dev-"))
+	require.NoError(t, err)
+	assert.Equal(t, "dev-User", configs["User"].TableInfo.TableName)
+	assert.Equal(t, dy.DBKey("ID"), configs["User"].TableInfo.PrimaryKey.PartitionKey.Name)
+}
+
+func TestLoadConfigs_Timestamps(t *testing.T) {
+	doc := `tables: {events: {partitionKey: {name: id}, timestamps: {createdAt: created, updatedAt: updated, encoding: epochMillis}}}`
+
+	configs, err := dy.LoadConfigs(strings.NewReader(doc))
+	require.NoError(t, err)
+	assert.Equal(t, dy.Timestamps{CreatedAt: "created", UpdatedAt: "updated", Encoding: dy.TimeEpochMillis},
+		configs["events"].Timestamps)
+}
+
+func TestLoadConfigs_Invalid(t *testing.T) {
+	cases := []struct {
+		name    string
+		doc     string
+		message string
+	}{
+		{"unset variable", usersDocument, "line 13: variable ORDER_KEY is not set"},
+		{"unknown field", "tables: {users: {partitionKey: {name: id}, sortkey: {name: sk}}}", "field sortkey not found"},
+		{"invalid key type", "tables: {users: {partitionKey: {name: id, type: Blob}}}", `invalid key type "Blob"`},
+		{"invalid number type", "tables: {users: {partitionKey: {name: id, type: Number, numberType: decimal}}}", `invalid number type "decimal"`},
+		{"boolean key", "tables: {users: {partitionKey: {name: id, type: Boolean}}}", "config users: invalid config: table: partition key"},
+		{"duplicate config", "tables: {users: {partitionKey: {name: id}}}\n---\ntables: {users: {partitionKey: {name: id}}}", "duplicate config users"},
+		{"invalid time encoding", "tables: {users: {partitionKey: {name: id}, timestamps: {updatedAt: at, encoding: unix}}}", `invalid time encoding "unix"`},
+		{"timestamp key", "tables: {users: {partitionKey: {name: id}, timestamps: {createdAt: id}}}", `timestamp "id": a table key`},
+		{"unterminated variable", `tables: {users: {tableName: "${STAGE", partitionKey: {name: id}}}`, "unterminated variable"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := dy.LoadConfigs(strings.NewReader(tc.doc), env(nil))
+			assert.ErrorIs(t, err, dy.ErrInvalidConfig)
+			assert.ErrorContains(t, err, tc.message)
+		})
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	doc := `tables: {users: {tableName: "$${A}-${A}-${B:-b}-${C:-c}-$x", partitionKey: {name: id}}}`
+
+	configs, err := dy.LoadConfigs(strings.NewReader(doc), env(map[string]string{"A": "a", "C": ""}))
+	require.NoError(t, err)
+	assert.Equal(t, "${A}-a-b-c-$x", configs["users"].TableInfo.TableName)
+}
+
+func TestLoadConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	users := filepath.Join(dir, "users.yaml")
+	orders := filepath.Join(dir, "orders.json")
+	require.NoError(t, os.WriteFile(users, []byte("tables: {users: {partitionKey: {name: id}}}"), 0o600))
+	require.NoError(t, os.WriteFile(orders, []byte(`{"tables": {"orders": {"partitionKey": {"name": "id"}}}}`), 0o600))
+
+	configs, err := dy.LoadConfigFiles([]string{users, orders}, dy.WithTableSuffix("-test"))
+	require.NoError(t, err)
+	assert.Equal(t, "users-test", configs["users"].TableInfo.TableName)
+	assert.Equal(t, "orders-test", configs["orders"].TableInfo.TableName)
+
+	_, err = dy.LoadConfigFiles([]string{users, users})
+	assert.ErrorIs(t, err, dy.ErrInvalidConfig)
+
+	_, err = dy.LoadConfigFiles([]string{filepath.Join(dir, "missing.yaml")})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -28,6 +28,9 @@ func (d *DynamoAttr) IsEmpty() bool {
 		s, ok := d.Value.(*types.AttributeValueMemberN)
 		b := !ok || s.Value == ""
 		return b
+	case Binary:
+		b, ok := d.Value.(*types.AttributeValueMemberB)
+		return !ok || len(b.Value) == 0
 	}
 
 	return true
@@ -71,6 +74,16 @@ func (b *DynamoExpressionBuilder) WithUpdateField(name string, value interface{}
 	return b
 }
 
+// WithUpdateFieldIfNotExists sets an update field if the item does not have it.
+func (b *DynamoExpressionBuilder) WithUpdateFieldIfNotExists(name string, value interface{}) *DynamoExpressionBuilder {
+	b.UpdateBuilder = b.UpdateBuilder.Set(
+		expression.Name(name),
+		expression.IfNotExists(expression.Name(name), expression.Value(value)),
+	)
+
+	return b
+}
+
 // BuildUpdateItemInput builds the update item request.
 // Todo: consider adding conditional update.
 func (b *DynamoExpressionBuilder) BuildUpdateItemInput() (*dynamodb.UpdateItemInput, error) {
@@ -165,6 +178,10 @@ func (b *DynamoExpressionBuilder) BuildScanInput(index *string, filter *Criteria
 		}, nil
 	}
 
+	if filter.err != nil {
+		return nil, filter.err
+	}
+
 	builder := expression.NewBuilder()
 	builder = builder.WithFilter(filter.GetExpression())
 
@@ -194,13 +211,22 @@ func (b *DynamoExpressionBuilder) BuildQueryInput(index *string, partitionKey Dy
 		size = aws.Int32(limit)
 	}
 
+	// the key value is marshaled according to the key type, e.g. as a number for a Number key given as a string
+	keyValue, err := newDynamoAttributeValue(partitionKey.Value, partitionKey.Type)
+	if err != nil {
+		return nil, err
+	}
+
 	builder := expression.NewBuilder()
 	builder = builder.
 		WithKeyCondition(
-			expression.Key(string(partitionKey.KeyName)).Equal(expression.Value(partitionKey.Value)),
+			expression.Key(string(partitionKey.KeyName)).Equal(expression.Value(keyValue)),
 		)
 
 	if filter != nil {
+		if filter.err != nil {
+			return nil, filter.err
+		}
 		builder.WithFilter(filter.GetExpression())
 	}
 
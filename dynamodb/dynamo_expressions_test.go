@@ -61,6 +61,22 @@ func TestNewDynamoUpdateBuildUpdateItemInput(t *testing.T) {
 
 	})
 
+	t.Run("if not exists", func(t *testing.T) {
+		builder := NewExpressionBuilder("table").WithPartitionKey(DynamoAttr{
+			Name:  "GroupID",
+			Type:  String,
+			Value: &types.AttributeValueMemberS{Value: "123"},
+		})
+
+		builder.WithUpdateFieldIfNotExists("createdAt", "2024-05-01T09:00:00Z")
+
+		req, err := builder.BuildUpdateItemInput()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "SET #0 = if_not_exists(#0, :0)\n", *req.UpdateExpression)
+		assert.Equal(t, "createdAt", req.ExpressionAttributeNames["#0"])
+	})
+
 	t.Run("with empty partition key", func(t *testing.T) {
 		builder := NewExpressionBuilder("table").WithPartitionKey(DynamoAttr{
 			Name:  "",
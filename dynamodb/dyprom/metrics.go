@@ -0,0 +1,97 @@
+// Package dyprom provides a Prometheus implementation of the dynamodb wrapper metrics.
+// It is a separate module, so that the wrapper does not depend on the Prometheus client.
+package dyprom
+
+import (
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var labelNames = []string{"table", "index", "operation"}
+
+// Metrics the Prometheus dy.Metrics implementation.
+type Metrics struct {
+	requests      *prometheus.CounterVec
+	errors        *prometheus.CounterVec
+	latency       *prometheus.HistogramVec
+	readCapacity  *prometheus.CounterVec
+	writeCapacity *prometheus.CounterVec
+}
+
+var _ dy.Metrics = (*Metrics)(nil)
+
+// NewMetrics creates the dynamodb metrics and registers them into the provided registerer.
+func NewMetrics(reg prometheus.Registerer, namespace string) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dynamodb",
+			Name:      "requests_total",
+			Help:      "The number of dynamodb requests.",
+		}, labelNames),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dynamodb",
+			Name:      "errors_total",
+			Help:      "The number of failed dynamodb requests.",
+		}, labelNames),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "dynamodb",
+			Name:      "request_duration_seconds",
+			Help:      "The dynamodb requests latency.",
+			Buckets:   prometheus.DefBuckets,
+		}, labelNames),
+		readCapacity: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dynamodb",
+			Name:      "consumed_read_capacity_units_total",
+			Help:      "The read capacity units consumed by the dynamodb requests.",
+		}, labelNames),
+		writeCapacity: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "dynamodb",
+			Name:      "consumed_write_capacity_units_total",
+			Help:      "The write capacity units consumed by the dynamodb requests.",
+		}, labelNames),
+	}
+
+	for _, c := range []prometheus.Collector{m.requests, m.errors, m.latency, m.readCapacity, m.writeCapacity} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// ObserveCall records the outcome and the latency of a dynamodb call.
+func (m *Metrics) ObserveCall(labels dy.MetricLabels, latency time.Duration, err error) {
+	values := labelValues(labels)
+
+	m.requests.WithLabelValues(values...).Inc()
+	m.latency.WithLabelValues(values...).Observe(latency.Seconds())
+
+	if err != nil {
+		m.errors.WithLabelValues(values...).Inc()
+	}
+}
+
+// AddConsumedCapacity records the read and write capacity units consumed by a dynamodb call.
+func (m *Metrics) AddConsumedCapacity(labels dy.MetricLabels, read, write float64) {
+	values := labelValues(labels)
+
+	if read > 0 {
+		m.readCapacity.WithLabelValues(values...).Add(read)
+	}
+
+	if write > 0 {
+		m.writeCapacity.WithLabelValues(values...).Add(write)
+	}
+}
+
+func labelValues(labels dy.MetricLabels) []string {
+	return []string{labels.Table, labels.Index, labels.Operation}
+}
@@ -0,0 +1,62 @@
+package dyprom_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/dyprom"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	m, err := dyprom.NewMetrics(reg, "app")
+	assert.NoError(t, err)
+
+	labels := dy.MetricLabels{Table: "User", Index: "byEmail", Operation: dy.OperationQuery}
+	m.ObserveCall(labels, 10*time.Millisecond, nil)
+	m.ObserveCall(labels, 20*time.Millisecond, fmt.Errorf("error"))
+	m.AddConsumedCapacity(labels, 1.5, 0)
+	m.AddConsumedCapacity(dy.MetricLabels{Table: "User", Operation: dy.OperationPutItem}, 0, 2)
+
+	count, err := testutil.GatherAndCount(reg)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+
+	metrics, err := reg.Gather()
+	assert.NoError(t, err)
+
+	values := make(map[string]float64)
+	for _, mf := range metrics {
+		for _, metric := range mf.GetMetric() {
+			switch {
+			case metric.GetCounter() != nil:
+				values[mf.GetName()] += metric.GetCounter().GetValue()
+			case metric.GetHistogram() != nil:
+				values[mf.GetName()] += float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]float64{
+		"app_dynamodb_requests_total":                      2,
+		"app_dynamodb_errors_total":                        1,
+		"app_dynamodb_request_duration_seconds":            2,
+		"app_dynamodb_consumed_read_capacity_units_total":  1.5,
+		"app_dynamodb_consumed_write_capacity_units_total": 2,
+	}, values)
+}
+
+func TestNewMetrics_AlreadyRegistered(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := dyprom.NewMetrics(reg, "app")
+	assert.NoError(t, err)
+
+	_, err = dyprom.NewMetrics(reg, "app")
+	assert.Error(t, err)
+}
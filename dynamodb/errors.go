@@ -1,6 +1,12 @@
 package dy
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/smithy-go"
+)
 
 // const errors
 var (
@@ -9,4 +15,143 @@ var (
 	ErrNotFound            = fmt.Errorf("not found")
 	ErrInvalidPartitionKey = fmt.Errorf("invalid partition key")
 	ErrInvalidSortKey      = fmt.Errorf("invalid sort key")
+	ErrCircuitOpen         = fmt.Errorf("circuit open")
+	ErrMarshal             = fmt.Errorf("failed to marshal entity")
+	ErrUnmarshal           = fmt.Errorf("failed to unmarshal item")
+	ErrTableMismatch       = fmt.Errorf("table does not match the configuration")
+	ErrInvalidConfig       = fmt.Errorf("invalid config")
 )
+
+// dynamodb errors, the original SDK error is kept in the error chain
+var (
+	ErrConditionalCheckFailed = fmt.Errorf("conditional check failed")
+	ErrThrottled              = fmt.Errorf("throttled")
+	ErrResourceNotFound       = fmt.Errorf("resource not found")
+	ErrValidation             = fmt.Errorf("validation error")
+	ErrTransactionCanceled    = fmt.Errorf("transaction canceled")
+	ErrItemTooLarge           = fmt.Errorf("item too large")
+	// ErrItemCollectionTooLarge the item collection of a local secondary index exceeds the 10 GB size limit.
+	ErrItemCollectionTooLarge = fmt.Errorf("item collection too large")
+)
+
+// OpError the error returned by the DB operations.
+//
+// The cause can be matched with errors.Is (e.g. errors.Is(err, ErrConditionalCheckFailed))
+// and the SDK error extracted with errors.As. ErrNotFound is returned unwrapped.
+type OpError struct {
+	// Op the DB operation (e.g. "Create").
+	Op string
+	// Table the table name.
+	Table string
+	// Index the index name, empty if the operation does not target an index.
+	Index string
+	// Key the primary key of the item, nil if the operation does not target a single item.
+	Key *DynamoPrimaryKey
+	// Err the cause.
+	Err error
+}
+
+func (e *OpError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Op)
+	sb.WriteString(" ")
+	sb.WriteString(e.Table)
+
+	if e.Index != "" {
+		sb.WriteString(" (index ")
+		sb.WriteString(e.Index)
+		sb.WriteString(")")
+	}
+
+	if e.Key != nil {
+		fmt.Fprintf(&sb, " [%s=%v", e.Key.PartitionKey.KeyName, e.Key.PartitionKey.Value)
+		if e.Key.SortKey != nil {
+			fmt.Fprintf(&sb, ", %s=%v", e.Key.SortKey.KeyName, e.Key.SortKey.Value)
+		}
+		sb.WriteString("]")
+	}
+
+	sb.WriteString(": ")
+	sb.WriteString(e.Err.Error())
+
+	return sb.String()
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// TableMismatchError the error reporting the differences between the config and the live table.
+//
+// It matches ErrTableMismatch with errors.Is.
+type TableMismatchError struct {
+	// Table the table name.
+	Table string
+	// Differences the differences between the config and the table (e.g. "missing global index byEmail").
+	Differences []string
+}
+
+func (e *TableMismatchError) Error() string {
+	return fmt.Sprintf("%v: table %s: %s", ErrTableMismatch, e.Table, strings.Join(e.Differences, "; "))
+}
+
+func (e *TableMismatchError) Unwrap() error {
+	return ErrTableMismatch
+}
+
+// opError wraps err into an OpError, mapping the dynamodb errors to their sentinel.
+func (d *DB[T]) opError(op, index string, key *DynamoPrimaryKey, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// ErrNotFound is an expected outcome, kept comparable with ==
+	if err == ErrNotFound {
+		return err
+	}
+
+	var opErr *OpError
+	if errors.As(err, &opErr) {
+		return err
+	}
+
+	return &OpError{
+		Op:    op,
+		Table: d.conf.TableInfo.TableName,
+		Index: index,
+		Key:   key,
+		Err:   mapError(err),
+	}
+}
+
+var errorCodes = map[string]error{
+	"ConditionalCheckFailedException":          ErrConditionalCheckFailed,
+	"ResourceNotFoundException":                ErrResourceNotFound,
+	"ValidationException":                      ErrValidation,
+	"TransactionCanceledException":             ErrTransactionCanceled,
+	"ItemCollectionSizeLimitExceededException": ErrItemCollectionTooLarge,
+}
+
+// mapError adds the sentinel matching a dynamodb error to the error chain.
+func mapError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	if throttlingErrorCodes[apiErr.ErrorCode()] {
+		return fmt.Errorf("%w: %w", ErrThrottled, err)
+	}
+
+	sentinel, ok := errorCodes[apiErr.ErrorCode()]
+	if !ok {
+		return err
+	}
+
+	// the item size limit is reported as a validation error
+	if sentinel == ErrValidation && strings.Contains(strings.ToLower(apiErr.ErrorMessage()), "item size") {
+		sentinel = ErrItemTooLarge
+	}
+
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
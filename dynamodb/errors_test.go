@@ -0,0 +1,141 @@
+package dy_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOpError_Mapping(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		sentinel error
+	}{
+		{name: "conditional check", err: &types.ConditionalCheckFailedException{}, sentinel: dy.ErrConditionalCheckFailed},
+		{name: "provisioned throughput", err: &types.ProvisionedThroughputExceededException{}, sentinel: dy.ErrThrottled},
+		{name: "throttling", err: &smithy.GenericAPIError{Code: "ThrottlingException"}, sentinel: dy.ErrThrottled},
+		{name: "resource not found", err: &types.ResourceNotFoundException{}, sentinel: dy.ErrResourceNotFound},
+		{name: "validation", err: &smithy.GenericAPIError{Code: "ValidationException", Message: "invalid expression"}, sentinel: dy.ErrValidation},
+		{
+			name:     "item too large",
+			err:      &smithy.GenericAPIError{Code: "ValidationException", Message: "Item size has exceeded the maximum allowed size"},
+			sentinel: dy.ErrItemTooLarge,
+		},
+		{name: "item collection too large", err: &types.ItemCollectionSizeLimitExceededException{}, sentinel: dy.ErrItemCollectionTooLarge},
+		{name: "transaction canceled", err: &types.TransactionCanceledException{}, sentinel: dy.ErrTransactionCanceled},
+	}
+
+	sortKey := dy.NewDynamoStringAttrib("id", "abc")
+	key := dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+		SortKey:      &sortKey,
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			m := mocks.NewDynamoClient(t)
+			m.On("DeleteItem", mock.Anything, mock.Anything).Return(nil, tc.err).Once()
+
+			db := dy.NewClient[entity](m, dbConfig)
+			err := db.Delete(context.Background(), key)
+			assert.ErrorIs(t, err, tc.sentinel)
+			assert.ErrorIs(t, err, tc.err)
+
+			var opErr *dy.OpError
+			assert.True(t, errors.As(err, &opErr))
+			assert.Equal(t, "Delete", opErr.Op)
+			assert.Equal(t, dbConfig.TableInfo.TableName, opErr.Table)
+			assert.Equal(t, &key, opErr.Key)
+		})
+	}
+}
+
+func TestOpError_NotFound(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+
+	db := dy.NewClient[entity](m, dbConfig)
+	_, err := db.GetItem(context.Background(), dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	})
+	assert.ErrorIs(t, err, dy.ErrNotFound)
+
+	// the not found errors are not wrapped, to be compared with ==
+	assert.True(t, err == dy.ErrNotFound)
+	var opErr *dy.OpError
+	assert.False(t, errors.As(err, &opErr))
+}
+
+func TestOpError_Find(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("Query", mock.Anything, mock.Anything).Return(nil, &types.ResourceNotFoundException{}).Once()
+
+	db := dy.NewClient[entity](m, dbConfig)
+	_, err := db.Find(context.Background(), dy.Request{
+		Index:        aws.String("byGroup"),
+		PartitionKey: dy.NewDynamoNumberAttrib("groupID", "1234"),
+		Size:         10,
+	})
+	assert.ErrorIs(t, err, dy.ErrResourceNotFound)
+
+	var notFound *types.ResourceNotFoundException
+	assert.True(t, errors.As(err, &notFound))
+
+	var opErr *dy.OpError
+	assert.True(t, errors.As(err, &opErr))
+	assert.Equal(t, "Find", opErr.Op)
+	assert.Equal(t, "byGroup", opErr.Index)
+	assert.Nil(t, opErr.Key)
+}
+
+func TestOpError_Create(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("PutItem", mock.Anything, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+
+	db := dy.NewClient[entity](m, dbConfig)
+	_, err := db.Create(context.Background(), entity{Id: "123", GroupID: aws.Int(1234)})
+	assert.ErrorIs(t, err, dy.ErrConditionalCheckFailed)
+
+	var opErr *dy.OpError
+	assert.True(t, errors.As(err, &opErr))
+	assert.Equal(t, "Create", opErr.Op)
+	if assert.NotNil(t, opErr.Key) {
+		assert.Equal(t, "1234", opErr.Key.PartitionKey.Value)
+	}
+}
+
+func TestOpError_Unmarshal(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{
+		Item: map[string]types.AttributeValue{
+			"groupID": &types.AttributeValueMemberS{Value: "not a number"},
+		},
+	}, nil).Once()
+
+	db := dy.NewClient[entity](m, dbConfig)
+	_, err := db.GetItem(context.Background(), dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	})
+	assert.ErrorIs(t, err, dy.ErrUnmarshal)
+}
+
+func TestOpError_Unwrap(t *testing.T) {
+	cause := fmt.Errorf("cause")
+	err := &dy.OpError{Op: "Find", Table: "User", Index: "byEmail", Err: cause}
+
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "Find User (index byEmail): cause", err.Error())
+}
@@ -0,0 +1,297 @@
+package dy
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KeyGenerator generates the missing keys of the created items.
+type KeyGenerator interface {
+	// GenerateKey returns the value of the key, e.g. a string for a String key or an int64 for a Number key.
+	GenerateKey(key DynamoKeyMetadata) (interface{}, error)
+}
+
+// KeyGeneratorFunc a function generating the keys.
+type KeyGeneratorFunc func(key DynamoKeyMetadata) (interface{}, error)
+
+// GenerateKey calls f(key).
+func (f KeyGeneratorFunc) GenerateKey(key DynamoKeyMetadata) (interface{}, error) {
+	return f(key)
+}
+
+// GeneratorOption configures the built-in key generators.
+type GeneratorOption func(*generatorOptions)
+
+type generatorOptions struct {
+	now    func() time.Time
+	random io.Reader
+}
+
+// WithGeneratorClock sets the clock of the time-based keys. Defaults to time.Now.
+// The default generator of a client uses the client clock (see WithClock).
+func WithGeneratorClock(now func() time.Time) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.now = now
+	}
+}
+
+// WithGeneratorRandom sets the source of the random bytes of the keys. Defaults to crypto/rand.Reader.
+func WithGeneratorRandom(random io.Reader) GeneratorOption {
+	return func(o *generatorOptions) {
+		o.random = random
+	}
+}
+
+func newGeneratorOptions(opts []GeneratorOption) generatorOptions {
+	o := generatorOptions{
+		now:    time.Now,
+		random: rand.Reader,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+func (o generatorOptions) read(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(o.random, b); err != nil {
+		return nil, fmt.Errorf("reading random bytes: %w", err)
+	}
+
+	return b, nil
+}
+
+func unsupportedKey(generator string, key DynamoKeyMetadata) error {
+	return fmt.Errorf("%w %v of key %s for a %s", ErrInvalidDBKeyType, key.Type, key.Name, generator)
+}
+
+// defaultKeyGenerator the generator of the keys without configured generator.
+var defaultKeyGenerator = NewDefaultKeyGenerator()
+
+// NewDefaultKeyGenerator creates the generator used for the keys without configured generator:
+// a random (version 4) UUID for the String and Binary keys, the current time in nanoseconds for the Number keys,
+// and a random boolean for the Boolean keys.
+func NewDefaultKeyGenerator(opts ...GeneratorOption) KeyGenerator {
+	o := newGeneratorOptions(opts)
+
+	return KeyGeneratorFunc(func(key DynamoKeyMetadata) (interface{}, error) {
+		switch key.Type {
+		case String, Binary:
+			id, err := uuid.NewRandomFromReader(o.random)
+			if err != nil {
+				return nil, err
+			}
+			if key.Type == Binary {
+				return id[:], nil
+			}
+			return id.String(), nil
+		case Number:
+			return o.now().UnixNano(), nil
+		case Boolean:
+			b, err := o.read(1)
+			if err != nil {
+				return nil, err
+			}
+			return b[0]&1 == 1, nil
+		}
+
+		return nil, unsupportedKey("generated key", key)
+	})
+}
+
+// NewUUIDv7Generator creates a generator of time-ordered (version 7) UUIDs, for the String and Binary keys.
+func NewUUIDv7Generator(opts ...GeneratorOption) KeyGenerator {
+	o := newGeneratorOptions(opts)
+
+	return KeyGeneratorFunc(func(key DynamoKeyMetadata) (interface{}, error) {
+		if key.Type != String && key.Type != Binary {
+			return nil, unsupportedKey("UUIDv7", key)
+		}
+
+		b, err := o.read(16)
+		if err != nil {
+			return nil, err
+		}
+
+		var id uuid.UUID
+		copy(id[:], b)
+		ms := uint64(o.now().UnixMilli())
+		id[0], id[1], id[2], id[3], id[4], id[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+		id[6] = 0x70 | id[6]&0x0f
+		id[8] = 0x80 | id[8]&0x3f
+
+		if key.Type == Binary {
+			return id[:], nil
+		}
+		return id.String(), nil
+	})
+}
+
+// crockford the Crockford's base32 alphabet of the ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULIDGenerator creates a generator of ULIDs (a 48 bits millisecond timestamp followed by 80 random bits),
+// encoded as 26 characters for the String keys and as 16 bytes for the Binary keys.
+func NewULIDGenerator(opts ...GeneratorOption) KeyGenerator {
+	o := newGeneratorOptions(opts)
+
+	return KeyGeneratorFunc(func(key DynamoKeyMetadata) (interface{}, error) {
+		if key.Type != String && key.Type != Binary {
+			return nil, unsupportedKey("ULID", key)
+		}
+
+		random, err := o.read(10)
+		if err != nil {
+			return nil, err
+		}
+
+		id := make([]byte, 16)
+		ms := uint64(o.now().UnixMilli())
+		binary.BigEndian.PutUint16(id, uint16(ms>>32))
+		binary.BigEndian.PutUint32(id[2:], uint32(ms))
+		copy(id[6:], random)
+
+		if key.Type == Binary {
+			return id, nil
+		}
+		return encode(id, crockford, 26), nil
+	})
+}
+
+// base62 the base62 alphabet of the KSUIDs.
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ksuidEpoch the epoch of the KSUID timestamps (2014-05-13T16:53:20Z).
+const ksuidEpoch = 1400000000
+
+// NewKSUIDGenerator creates a generator of KSUIDs (a 32 bits second timestamp followed by 128 random bits),
+// encoded as 27 characters for the String keys and as 20 bytes for the Binary keys.
+func NewKSUIDGenerator(opts ...GeneratorOption) KeyGenerator {
+	o := newGeneratorOptions(opts)
+
+	return KeyGeneratorFunc(func(key DynamoKeyMetadata) (interface{}, error) {
+		if key.Type != String && key.Type != Binary {
+			return nil, unsupportedKey("KSUID", key)
+		}
+
+		random, err := o.read(16)
+		if err != nil {
+			return nil, err
+		}
+
+		id := make([]byte, 20)
+		binary.BigEndian.PutUint32(id, uint32(o.now().Unix()-ksuidEpoch))
+		copy(id[4:], random)
+
+		if key.Type == Binary {
+			return id, nil
+		}
+		return encode(id, base62, 27), nil
+	})
+}
+
+// encode encodes big-endian bytes in the alphabet base, left padded to size characters.
+func encode(b []byte, alphabet string, size int) string {
+	n := new(big.Int).SetBytes(b)
+	base := big.NewInt(int64(len(alphabet)))
+	digit := new(big.Int)
+
+	out := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		n.DivMod(n, base, digit)
+		out[i] = alphabet[digit.Int64()]
+	}
+
+	return string(out)
+}
+
+// snowflakeEpoch the epoch of the snowflake timestamps (2010-11-04T01:42:54.657Z).
+const snowflakeEpoch = 1288834974657
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+)
+
+// SnowflakeGenerator a generator of snowflake IDs: a 41 bits millisecond timestamp, a 10 bits node ID and
+// a 12 bits sequence, the IDs of a generator being increasing.
+// The IDs are int64 values for the Number keys and decimal strings for the String keys.
+type SnowflakeGenerator struct {
+	mu       sync.Mutex
+	node     int64
+	now      func() time.Time
+	last     int64
+	sequence int64
+}
+
+// NewSnowflakeGenerator creates a snowflake ID generator. The node ID (0-1023) must be unique among the
+// generators of the same table.
+func NewSnowflakeGenerator(node int64, opts ...GeneratorOption) (*SnowflakeGenerator, error) {
+	if node < 0 || node >= 1<<snowflakeNodeBits {
+		return nil, fmt.Errorf("invalid snowflake node %d, expecting 0 to %d", node, 1<<snowflakeNodeBits-1)
+	}
+
+	return &SnowflakeGenerator{
+		node: node,
+		now:  newGeneratorOptions(opts).now,
+		last: -1,
+	}, nil
+}
+
+// GenerateKey returns the next snowflake ID.
+func (g *SnowflakeGenerator) GenerateKey(key DynamoKeyMetadata) (interface{}, error) {
+	if key.Type != String && key.Type != Number {
+		return nil, unsupportedKey("snowflake ID", key)
+	}
+
+	id := g.next()
+	if key.Type == String {
+		return strconv.FormatInt(id, 10), nil
+	}
+	return id, nil
+}
+
+func (g *SnowflakeGenerator) next() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	// the IDs stay increasing if the clock goes backwards or the sequence overflows
+	ms := g.now().UnixMilli() - snowflakeEpoch
+	if ms > g.last {
+		g.last, g.sequence = ms, 0
+	} else {
+		g.sequence++
+		if g.sequence == 1<<snowflakeSequenceBits {
+			g.last, g.sequence = g.last+1, 0
+		}
+	}
+
+	return g.last<<(snowflakeNodeBits+snowflakeSequenceBits) | g.node<<snowflakeSequenceBits | g.sequence
+}
+
+// NewPrefixedGenerator creates a generator of String keys prefixed with prefix (e.g. "USER#"),
+// the rest of the key being generated by generator.
+func NewPrefixedGenerator(prefix string, generator KeyGenerator) KeyGenerator {
+	return KeyGeneratorFunc(func(key DynamoKeyMetadata) (interface{}, error) {
+		if key.Type != String {
+			return nil, unsupportedKey("prefixed key", key)
+		}
+
+		value, err := generator.GenerateKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		return fmt.Sprintf("%s%v", prefix, value), nil
+	})
+}
@@ -0,0 +1,169 @@
+package dy_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	stringKey = dy.DynamoKeyMetadata{Name: "id", Type: dy.String}
+	numberKey = dy.DynamoKeyMetadata{Name: "id", Type: dy.Number}
+	binaryKey = dy.DynamoKeyMetadata{Name: "id", Type: dy.Binary}
+)
+
+func clockAt(t time.Time) dy.GeneratorOption {
+	return dy.WithGeneratorClock(func() time.Time { return t })
+}
+
+func randomOf(t *testing.T, s string) dy.GeneratorOption {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return dy.WithGeneratorRandom(bytes.NewReader(bytes.Repeat(b, 64)))
+}
+
+func TestNewDefaultKeyGenerator(t *testing.T) {
+	now := time.Unix(1700000000, 42)
+	generator := dy.NewDefaultKeyGenerator(clockAt(now), randomOf(t, "00"))
+
+	id, err := generator.GenerateKey(stringKey)
+	require.NoError(t, err)
+	assert.Equal(t, "00000000-0000-4000-8000-000000000000", id)
+
+	id, err = generator.GenerateKey(numberKey)
+	require.NoError(t, err)
+	assert.Equal(t, now.UnixNano(), id)
+
+	id, err = generator.GenerateKey(binaryKey)
+	require.NoError(t, err)
+	assert.Len(t, id, 16)
+
+	id, err = generator.GenerateKey(dy.DynamoKeyMetadata{Name: "enabled", Type: dy.Boolean})
+	require.NoError(t, err)
+	assert.Equal(t, false, id)
+
+	_, err = generator.GenerateKey(dy.DynamoKeyMetadata{Name: "tags", Type: dy.StringSet})
+	assert.ErrorIs(t, err, dy.ErrInvalidDBKeyType)
+}
+
+func TestNewUUIDv7Generator(t *testing.T) {
+	// the RFC 9562 example
+	generator := dy.NewUUIDv7Generator(clockAt(time.UnixMilli(1645557742000)), randomOf(t, "0000000000000cc318c4dc0c0c07398f"))
+
+	id, err := generator.GenerateKey(stringKey)
+	require.NoError(t, err)
+	assert.Equal(t, "017f22e2-79b0-7cc3-98c4-dc0c0c07398f", id)
+
+	_, err = generator.GenerateKey(numberKey)
+	assert.ErrorIs(t, err, dy.ErrInvalidDBKeyType)
+}
+
+func TestNewULIDGenerator(t *testing.T) {
+	// the timestamp of the ULID spec example
+	generator := dy.NewULIDGenerator(clockAt(time.UnixMilli(1469918176385)), randomOf(t, "00"))
+
+	id, err := generator.GenerateKey(stringKey)
+	require.NoError(t, err)
+	assert.Equal(t, "01ARYZ6S410000000000000000", id)
+
+	id, err = generator.GenerateKey(binaryKey)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x56, 0x3d, 0xf3, 0x64, 0x81, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, id)
+
+	_, err = dy.NewULIDGenerator(dy.WithGeneratorRandom(iotest.ErrReader(assert.AnError))).GenerateKey(stringKey)
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestNewKSUIDGenerator(t *testing.T) {
+	id, err := dy.NewKSUIDGenerator(clockAt(time.Unix(1400000000, 0)), randomOf(t, "00")).GenerateKey(stringKey)
+	require.NoError(t, err)
+	assert.Equal(t, "000000000000000000000000000", id)
+
+	// the largest KSUID
+	id, err = dy.NewKSUIDGenerator(clockAt(time.Unix(1400000000+1<<32-1, 0)), randomOf(t, "ff")).GenerateKey(stringKey)
+	require.NoError(t, err)
+	assert.Equal(t, "aWgEPTl1tmebfsQzFP4bxwgy80V", id)
+
+	id, err = dy.NewKSUIDGenerator(randomOf(t, "ff")).GenerateKey(binaryKey)
+	require.NoError(t, err)
+	assert.Len(t, id, 20)
+}
+
+func TestSnowflakeGenerator(t *testing.T) {
+	now := time.UnixMilli(1288834974657 + 1000)
+	generator, err := dy.NewSnowflakeGenerator(5, dy.WithGeneratorClock(func() time.Time { return now }))
+	require.NoError(t, err)
+
+	first, err := generator.GenerateKey(numberKey)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000<<22|5<<12), first)
+
+	// the IDs of the same millisecond are sequenced
+	second, err := generator.GenerateKey(stringKey)
+	require.NoError(t, err)
+	assert.Equal(t, "4194324481", second)
+
+	// the IDs keep increasing when the clock goes backwards
+	now = now.Add(-time.Second)
+	third, err := generator.GenerateKey(numberKey)
+	require.NoError(t, err)
+	assert.Equal(t, first.(int64)+2, third)
+
+	_, err = generator.GenerateKey(binaryKey)
+	assert.ErrorIs(t, err, dy.ErrInvalidDBKeyType)
+
+	_, err = dy.NewSnowflakeGenerator(1024)
+	assert.Error(t, err)
+}
+
+func TestNewPrefixedGenerator(t *testing.T) {
+	generator := dy.NewPrefixedGenerator("USER#", dy.NewULIDGenerator(clockAt(time.UnixMilli(1469918176385)), randomOf(t, "00")))
+
+	id, err := generator.GenerateKey(stringKey)
+	require.NoError(t, err)
+	assert.Equal(t, "USER#01ARYZ6S410000000000000000", id)
+
+	_, err = generator.GenerateKey(numberKey)
+	assert.ErrorIs(t, err, dy.ErrInvalidDBKeyType)
+}
+
+func TestDynamodb_Create_KeyGenerators(t *testing.T) {
+	snowflake, err := dy.NewSnowflakeGenerator(1, clockAt(time.UnixMilli(1288834974657)))
+	require.NoError(t, err)
+
+	conf := dbConfig
+	conf.TableInfo.PrimaryKey.PartitionKey.NumberType = dy.NumberInt64
+	conf.KeyGenerators = map[dy.DBKey]dy.KeyGenerator{
+		"groupID": snowflake,
+		"id":      dy.NewPrefixedGenerator("USER#", dy.NewUUIDv7Generator()),
+	}
+	require.NoError(t, conf.Validate())
+
+	m := mocks.NewDynamoClient(t)
+	m.On("PutItem", mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil)
+
+	key, err := dy.NewClient[entity](m, conf).Create(context.Background(), entity{FirstName: "f1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<12), key.PartitionKey.Value)
+	assert.Regexp(t, `^USER#[0-9a-f-]{36}$`, key.SortKey.Value)
+
+	// the generation errors are returned
+	conf.KeyGenerators["id"] = dy.KeyGeneratorFunc(func(dy.DynamoKeyMetadata) (interface{}, error) { return "", nil })
+	_, err = dy.NewClient[entity](m, conf).Create(context.Background(), entity{FirstName: "f1"})
+	assert.ErrorContains(t, err, "generating key id: empty key")
+
+	conf.KeyGenerators["id"] = dy.KeyGeneratorFunc(func(dy.DynamoKeyMetadata) (interface{}, error) { return nil, assert.AnError })
+	_, err = dy.NewClient[entity](m, conf).Create(context.Background(), entity{FirstName: "f1"})
+	assert.ErrorIs(t, err, assert.AnError)
+}
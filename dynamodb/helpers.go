@@ -1,14 +1,13 @@
 package dy
 
 import (
+	"encoding"
 	"fmt"
-	"math/rand"
-	"strconv"
+	"reflect"
 	"strings"
-	"time"
 
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"github.com/google/uuid"
 )
 
 func preparePartSortKey(primaryKey DynamoPrimaryKey) (partKey DynamoAttr, sortKey *DynamoAttr, err error) {
@@ -32,20 +31,23 @@ func preparePartSortKey(primaryKey DynamoPrimaryKey) (partKey DynamoAttr, sortKe
 	return
 }
 
-func addPrimaryKey(dbMap map[string]types.AttributeValue, metadata DynamoKeyMetadata) (DynamoAttribute, error) {
+func addPrimaryKey(dbMap map[string]types.AttributeValue, metadata DynamoKeyMetadata, generator KeyGenerator) (DynamoAttribute, error) {
 	dynamoAttrib := DynamoAttribute{
 		KeyName: metadata.Name,
 		Type:    metadata.Type,
 	}
 	// getting the partition key
-	k, ok := dbMap[strings.ToLower(string(metadata.Name))]
+	k, ok := lookupKey(dbMap, metadata.Name)
 
 	// if partition key read from the config is not found then it returns an error
 	if !ok {
 		return dynamoAttrib, ErrKeyNotFound
 	}
 
-	val, empty := getValueOf(k, metadata.Type)
+	val, empty, err := keyValueOf(k, metadata)
+	if err != nil {
+		return dynamoAttrib, err
+	}
 
 	if !empty {
 		dynamoAttrib.Value = val
@@ -53,7 +55,7 @@ func addPrimaryKey(dbMap map[string]types.AttributeValue, metadata DynamoKeyMeta
 	}
 
 	// if the key is nil then we create it
-	key, val, err := initDynamoKeyValue(metadata)
+	key, val, err := generateKey(generator, metadata)
 	if err != nil {
 		return dynamoAttrib, err
 	}
@@ -63,23 +65,45 @@ func addPrimaryKey(dbMap map[string]types.AttributeValue, metadata DynamoKeyMeta
 	return dynamoAttrib, nil
 }
 
-func initDynamoKeyValue(attribute DynamoKeyMetadata) (types.AttributeValue, interface{}, error) {
-	switch attribute.Type {
-	case String:
-		val := uuid.NewString()
-		dynamoValue, err := newDynamoAttributeValue(val, String)
-		return dynamoValue, val, err
-	case Number:
-		val := strconv.FormatInt(time.Now().UnixNano(), 10)
-		dynamoValue, err := newDynamoAttributeValue(val, Number)
-		return dynamoValue, val, err
-	case Boolean:
-		val := randBool()
-		dynamoValue, err := newDynamoAttributeValue(val, Boolean)
-		return dynamoValue, val, err
+// lookupKey returns the attribute of a key. The key name is matched case-insensitively when no attribute has its
+// exact name (e.g. the key "id" of an untagged Id field), the attribute being then renamed to the key name.
+func lookupKey(item map[string]types.AttributeValue, name DBKey) (types.AttributeValue, bool) {
+	if k, ok := item[string(name)]; ok {
+		return k, true
 	}
 
-	return nil, nil, ErrInvalidDBKeyType
+	for attribute, k := range item {
+		if strings.EqualFold(attribute, string(name)) {
+			delete(item, attribute)
+			item[string(name)] = k
+			return k, true
+		}
+	}
+
+	return nil, false
+}
+
+// generateKey generates the value of a key, returned as read from dynamodb.
+func generateKey(generator KeyGenerator, metadata DynamoKeyMetadata) (types.AttributeValue, interface{}, error) {
+	generated, err := generator.GenerateKey(metadata)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating key %s: %w", metadata.Name, err)
+	}
+
+	key, err := newDynamoAttributeValue(generated, metadata.Type)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating key %s: %w", metadata.Name, err)
+	}
+
+	val, empty, err := keyValueOf(key, metadata)
+	if err != nil {
+		return nil, nil, err
+	}
+	if empty {
+		return nil, nil, fmt.Errorf("generating key %s: empty key", metadata.Name)
+	}
+
+	return key, val, nil
 }
 
 func createDynamoAttribute(name string, value interface{}, KeyType DBKeyType) (DynamoAttr, error) {
@@ -102,8 +126,12 @@ func newDynamoAttributeValue(value interface{}, KeyType DBKeyType) (types.Attrib
 			Value: fmt.Sprintf("%v", value),
 		}, nil
 	case Number:
+		n, err := formatNumber(value)
+		if err != nil {
+			return nil, err
+		}
 		return &types.AttributeValueMemberN{
-			Value: fmt.Sprintf("%v", value),
+			Value: n,
 		}, nil
 	case Boolean:
 		b, ok := value.(bool)
@@ -113,11 +141,133 @@ func newDynamoAttributeValue(value interface{}, KeyType DBKeyType) (types.Attrib
 		return &types.AttributeValueMemberBOOL{
 			Value: b,
 		}, nil
+	case Binary:
+		b, err := toBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberB{
+			Value: b,
+		}, nil
+	case StringSet, NumberSet, BinarySet:
+		return newSetValue(value, KeyType)
+	case List:
+		return newListValue(value)
+	case Map:
+		return newMapValue(value)
 	}
 
 	return nil, ErrInvalidDBKeyType
 }
 
+// newSetValue creates a set from the elements of a slice. The sets can't be empty.
+func newSetValue(value interface{}, setType DBKeyType) (types.AttributeValue, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%v cannot be casted to %v", value, setType)
+	}
+
+	if v.Len() == 0 {
+		return nil, fmt.Errorf("%v cannot be empty", setType)
+	}
+
+	switch setType {
+	case BinarySet:
+		set := make([][]byte, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			b, err := toBytes(v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, b)
+		}
+		return &types.AttributeValueMemberBS{Value: set}, nil
+	}
+
+	set := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if setType != NumberSet {
+			set = append(set, fmt.Sprintf("%v", v.Index(i).Interface()))
+			continue
+		}
+
+		// the numbers are stored exactly, as their shortest decimal representation
+		n, err := formatNumber(v.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, n)
+	}
+
+	if setType == NumberSet {
+		return &types.AttributeValueMemberNS{Value: set}, nil
+	}
+
+	return &types.AttributeValueMemberSS{Value: set}, nil
+}
+
+func newListValue(value interface{}) (types.AttributeValue, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%v cannot be casted to %v", value, List)
+	}
+
+	list := make([]types.AttributeValue, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		av, err := marshalValue(v.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, av)
+	}
+
+	return &types.AttributeValueMemberL{Value: list}, nil
+}
+
+func newMapValue(value interface{}) (types.AttributeValue, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("%v cannot be casted to %v", value, Map)
+	}
+
+	m := make(map[string]types.AttributeValue, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		av, err := marshalValue(iter.Value().Interface())
+		if err != nil {
+			return nil, err
+		}
+		m[iter.Key().String()] = av
+	}
+
+	return &types.AttributeValueMemberM{Value: m}, nil
+}
+
+// marshalValue marshals a list element or a map value.
+func marshalValue(value interface{}) (types.AttributeValue, error) {
+	switch v := value.(type) {
+	case types.AttributeValue:
+		return v, nil
+	case DynamoAttribute:
+		return newDynamoAttributeValue(v.Value, v.Type)
+	case *DynamoAttribute:
+		return newDynamoAttributeValue(v.Value, v.Type)
+	}
+
+	return attributevalue.Marshal(value)
+}
+
+// expressionValue returns the value of an attribute to use in the expressions.
+// The numbers and the collections are converted to their attribute value, the other scalars being marshaled by the
+// expression builder.
+func expressionValue(attr DynamoAttribute) (interface{}, error) {
+	if attr.Type != Number && !attr.Type.isCollection() {
+		return attr.Value, nil
+	}
+
+	return newDynamoAttributeValue(attr.Value, attr.Type)
+}
+
 func extractUnprocessedKeys(keys []map[string]types.AttributeValue, partitionKey DynamoKeyMetadata, sortKeyMeta *DynamoKeyMetadata) ([]DynamoPrimaryKey, error) {
 	primaryKeys := make([]DynamoPrimaryKey, 0)
 	for _, key := range keys {
@@ -141,11 +291,16 @@ func extractPrimaryKey(keys map[string]types.AttributeValue, partitionKey Dynamo
 		return nil, nil
 	}
 
-	partKey := getDynamoAttribute(keys, partitionKey)
-	var sortKey *DynamoAttribute
+	partKey, err := getDynamoAttribute(keys, partitionKey)
+	if err != nil {
+		return nil, err
+	}
 
+	var sortKey *DynamoAttribute
 	if sortKeyMeta != nil {
-		sortKey = getDynamoAttribute(keys, *sortKeyMeta)
+		if sortKey, err = getDynamoAttribute(keys, *sortKeyMeta); err != nil {
+			return nil, err
+		}
 	}
 
 	if partKey == nil || (sortKey == nil && sortKeyMeta != nil) {
@@ -158,22 +313,22 @@ func extractPrimaryKey(keys map[string]types.AttributeValue, partitionKey Dynamo
 	}, nil
 }
 
-func getDynamoAttribute(attributes map[string]types.AttributeValue, meta DynamoKeyMetadata) *DynamoAttribute {
+func getDynamoAttribute(attributes map[string]types.AttributeValue, meta DynamoKeyMetadata) (*DynamoAttribute, error) {
 	attr := attributes[string(meta.Name)]
 	if attr == nil {
-		return nil
+		return nil, nil
 	}
 
-	val, empty := getValueOf(attr, meta.Type)
-	if empty {
-		return nil
+	val, empty, err := keyValueOf(attr, meta)
+	if err != nil || empty {
+		return nil, err
 	}
 
 	return &DynamoAttribute{
 		KeyName: meta.Name,
 		Type:    meta.Type,
 		Value:   val,
-	}
+	}, nil
 }
 
 func extractMetadata(attrib *DynamoAttribute) *DynamoKeyMetadata {
@@ -187,6 +342,20 @@ func extractMetadata(attrib *DynamoAttribute) *DynamoKeyMetadata {
 	}
 }
 
+// keyValueOf returns the value of a key, the Number keys being decoded into their NumberType.
+func keyValueOf(attribute types.AttributeValue, meta DynamoKeyMetadata) (val interface{}, empty bool, err error) {
+	val, empty = getValueOf(attribute, meta.Type)
+	if empty || meta.Type != Number {
+		return val, empty, nil
+	}
+
+	if val, err = parseNumber(val.(string), meta.NumberType); err != nil {
+		return nil, false, fmt.Errorf("key %s: %w", meta.Name, err)
+	}
+
+	return val, false, nil
+}
+
 func getValueOf(attribute types.AttributeValue, DBKeyType DBKeyType) (val interface{}, empty bool) {
 	switch DBKeyType {
 	case String:
@@ -207,20 +376,25 @@ func getValueOf(attribute types.AttributeValue, DBKeyType DBKeyType) (val interf
 			return false, true
 		}
 		return v.Value, false
+	case Binary:
+		v, ok := attribute.(*types.AttributeValueMemberB)
+		if !ok {
+			return []byte(nil), true
+		}
+		return v.Value, len(v.Value) == 0
 	}
 
 	return nil, true
 }
 
-func randBool() bool {
-	return rand.Intn(2) == 1
-}
-
-func toLowerCaseKeys(m map[string]types.AttributeValue) map[string]types.AttributeValue {
-	newMap := make(map[string]types.AttributeValue)
-	for k, v := range m {
-		newMap[strings.ToLower(k)] = v
+// toBytes converts a binary key value, either a byte slice or an encoding.BinaryMarshaler (e.g. uuid.UUID).
+func toBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case encoding.BinaryMarshaler:
+		return v.MarshalBinary()
 	}
 
-	return newMap
+	return nil, fmt.Errorf("%v cannot be casted to []byte", value)
 }
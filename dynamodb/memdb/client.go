@@ -0,0 +1,185 @@
+// Package memdb provides an in-memory dynamodb client to test the code relying on dy.DynamoClient
+// without any external service.
+//
+// The client stores the items per table, honours the key schemas and the secondary indexes,
+// evaluates the key condition, filter, condition, projection and update expressions, and pages with
+// Limit and ExclusiveStartKey.
+package memdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Client an in-memory dynamodb client. It is safe for concurrent use.
+type Client struct {
+	mu     sync.RWMutex
+	tables map[string]*table
+	now    func() time.Time
+}
+
+var (
+	_ dy.DynamoClient = (*Client)(nil)
+	_ dy.TableAdmin   = (*Client)(nil)
+)
+
+// New creates a new empty in-memory client.
+func New() *Client {
+	return &Client{
+		tables: make(map[string]*table),
+		now:    time.Now,
+	}
+}
+
+// CreateTable creates a table. The table is immediately ACTIVE.
+func (c *Client) CreateTable(_ context.Context, params *dynamodb.CreateTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := aws.ToString(params.TableName)
+	if _, ok := c.tables[name]; ok {
+		return nil, &types.ResourceInUseException{Message: aws.String(fmt.Sprintf("Table already exists: %s", name))}
+	}
+
+	t, err := newTable(params, c.now())
+	if err != nil {
+		return nil, err
+	}
+	c.tables[name] = t
+
+	return &dynamodb.CreateTableOutput{TableDescription: t.describe()}, nil
+}
+
+// DescribeTable describes a table.
+func (c *Client) DescribeTable(_ context.Context, params *dynamodb.DescribeTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.DescribeTableOutput{Table: t.describe()}, nil
+}
+
+// DeleteTable deletes a table and its items.
+func (c *Client) DeleteTable(_ context.Context, params *dynamodb.DeleteTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+	delete(c.tables, t.name)
+
+	desc := t.describe()
+	desc.TableStatus = types.TableStatusDeleting
+
+	return &dynamodb.DeleteTableOutput{TableDescription: desc}, nil
+}
+
+// ListTables lists the table names in alphabetical order.
+func (c *Client) ListTables(_ context.Context, params *dynamodb.ListTablesInput, _ ...func(*dynamodb.Options)) (*dynamodb.ListTablesOutput, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.tables))
+	for name := range c.tables {
+		if name > aws.ToString(params.ExclusiveStartTableName) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	out := &dynamodb.ListTablesOutput{}
+	if limit := int(aws.ToInt32(params.Limit)); limit > 0 && len(names) > limit {
+		names = names[:limit]
+		out.LastEvaluatedTableName = aws.String(names[limit-1])
+	}
+	out.TableNames = names
+
+	return out, nil
+}
+
+// UpdateTimeToLive enables or disables the time to live of a table. The expired items are not deleted.
+func (c *Client) UpdateTimeToLive(_ context.Context, params *dynamodb.UpdateTimeToLiveInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := params.TimeToLiveSpecification
+	if spec == nil || aws.ToString(spec.AttributeName) == "" {
+		return nil, validationErrorf("TimeToLiveSpecification.AttributeName must not be empty")
+	}
+
+	enabled := aws.ToBool(spec.Enabled)
+	if enabled && t.ttl != nil {
+		return nil, validationErrorf("TimeToLive is already enabled")
+	}
+	if !enabled && t.ttl == nil {
+		return nil, validationErrorf("TimeToLive is already disabled")
+	}
+
+	t.ttl = nil
+	if enabled {
+		t.ttl = &types.TimeToLiveSpecification{AttributeName: spec.AttributeName, Enabled: aws.Bool(true)}
+	}
+
+	return &dynamodb.UpdateTimeToLiveOutput{TimeToLiveSpecification: spec}, nil
+}
+
+// DescribeTimeToLive describes the time to live of a table.
+func (c *Client) DescribeTimeToLive(_ context.Context, params *dynamodb.DescribeTimeToLiveInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	desc := &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled}
+	if t.ttl != nil {
+		desc = &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusEnabled, AttributeName: t.ttl.AttributeName}
+	}
+
+	return &dynamodb.DescribeTimeToLiveOutput{TimeToLiveDescription: desc}, nil
+}
+
+// CreateTableFromConfig creates the table described by a dy.DBConfig, its indexes projecting all the attributes.
+// It creates the table of dy.NewCreateTableInput with the default table options, the invalid configs being rejected
+// with dy.ErrInvalidConfig.
+func (c *Client) CreateTableFromConfig(ctx context.Context, conf dy.DBConfig) error {
+	in, err := dy.NewCreateTableInput(conf, dy.TableOptions{})
+	if err != nil {
+		return err
+	}
+
+	_, err = c.CreateTable(ctx, in)
+	return err
+}
+
+// table returns a table. c.mu must be held.
+func (c *Client) table(name *string) (*table, error) {
+	t, ok := c.tables[aws.ToString(name)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("Requested resource not found")}
+	}
+
+	return t, nil
+}
@@ -0,0 +1,378 @@
+package memdb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	GroupID   int    `dynamodbav:"groupID"`
+	ID        string `dynamodbav:"id"`
+	Email     string `dynamodbav:"email,omitempty"`
+	FirstName string `dynamodbav:"firstName"`
+}
+
+func (u user) IsEmpty() bool {
+	return u.GroupID == 0 && u.ID == ""
+}
+
+var usersConfig = dy.DBConfig{
+	TableInfo: dy.TableInfo{
+		TableName: "users",
+		PrimaryKey: dy.DBPrimaryKeyNames{
+			PartitionKey: dy.DynamoKeyMetadata{Name: "groupID", Type: dy.Number},
+			SortKey:      &dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+		},
+	},
+	Indexes: map[dy.DBIndexName]dy.DBPrimaryKeyNames{
+		"byEmail": {PartitionKey: dy.DynamoKeyMetadata{Name: "email", Type: dy.String}},
+	},
+}
+
+func newClient(t *testing.T) *memdb.Client {
+	c := memdb.New()
+	require.NoError(t, c.CreateTableFromConfig(context.Background(), usersConfig))
+	return c
+}
+
+func key(groupID int, id string) dy.DynamoPrimaryKey {
+	sortKey := dy.NewDynamoStringAttrib("id", id)
+	return dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", fmt.Sprint(groupID)),
+		SortKey:      &sortKey,
+	}
+}
+
+func TestClient_DB(t *testing.T) {
+	ctx := context.Background()
+	db := dy.NewClient[user](newClient(t), usersConfig)
+
+	for i := 1; i <= 5; i++ {
+		u := user{GroupID: 1 + i%2, ID: fmt.Sprintf("u%d", i), FirstName: fmt.Sprintf("name%d", i)}
+		if i%2 == 0 {
+			u.Email = "team@example.com"
+		}
+
+		_, err := db.Create(ctx, u)
+		require.NoError(t, err)
+	}
+
+	u, err := db.GetItem(ctx, key(2, "u1"))
+	require.NoError(t, err)
+	assert.Equal(t, "name1", u.FirstName)
+
+	_, err = db.GetItem(ctx, key(1, "u1"))
+	assert.ErrorIs(t, err, dy.ErrNotFound)
+
+	require.NoError(t, db.Update(ctx, key(2, "u1"), []dy.DynamoAttribute{dy.NewDynamoStringAttrib("firstName", "updated")}))
+	u, err = db.GetItem(ctx, key(2, "u1"))
+	require.NoError(t, err)
+	assert.Equal(t, "updated", u.FirstName)
+
+	// query the sparse index
+	emailKey := dy.NewDynamoStringAttrib("email", "team@example.com")
+	page, err := db.Find(ctx, dy.Request{Size: 10, Index: aws.String("byEmail"), PartitionKey: &emailKey})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.Nil(t, page.LastEvaluatedKey)
+
+	// scan the table page by page
+	var ids []string
+	req := dy.Request{Size: 2}
+	for {
+		page, err := db.Find(ctx, req)
+		require.NoError(t, err)
+
+		for _, item := range page.Items {
+			ids = append(ids, item.ID)
+		}
+
+		if page.LastEvaluatedKey == nil {
+			break
+		}
+		req.LastEvaluatedKey = page.LastEvaluatedKey
+	}
+	assert.Equal(t, []string{"u2", "u4", "u1", "u3", "u5"}, ids)
+
+	// scan with a filter
+	page, err = db.Find(ctx, dy.Request{Size: 10, Conditions: []dy.Criteria{*dy.NewCriteria().And("firstName", "name3", dy.EQUAL)}})
+	require.NoError(t, err)
+	if assert.Len(t, page.Items, 1) {
+		assert.Equal(t, "u3", page.Items[0].ID)
+	}
+
+	items, unprocessed, err := db.GetItems(ctx, []dy.DynamoPrimaryKey{key(2, "u1"), key(1, "u2"), key(1, "missing")})
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Empty(t, unprocessed)
+
+	require.NoError(t, db.Delete(ctx, key(2, "u1")))
+	_, err = db.GetItem(ctx, key(2, "u1"))
+	assert.ErrorIs(t, err, dy.ErrNotFound)
+}
+
+func TestClient_ConditionalWrites(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+
+	item := map[string]types.AttributeValue{
+		"groupID": &types.AttributeValueMemberN{Value: "1"},
+		"id":      &types.AttributeValueMemberS{Value: "a"},
+		"count":   &types.AttributeValueMemberN{Value: "1"},
+	}
+
+	put := &dynamodb.PutItemInput{
+		TableName:                aws.String("users"),
+		Item:                     item,
+		ConditionExpression:      aws.String("attribute_not_exists(#id)"),
+		ExpressionAttributeNames: map[string]string{"#id": "id"},
+	}
+
+	_, err := c.PutItem(ctx, put)
+	require.NoError(t, err)
+
+	_, err = c.PutItem(ctx, put)
+	var conditionErr *types.ConditionalCheckFailedException
+	assert.True(t, errors.As(err, &conditionErr))
+
+	out, err := c.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String("users"),
+		Key:                 map[string]types.AttributeValue{"groupID": item["groupID"], "id": item["id"]},
+		UpdateExpression:    aws.String("SET #c = #c + :inc, tags = if_not_exists(tags, :empty) ADD visits :inc"),
+		ConditionExpression: aws.String("#c < :max"),
+		ExpressionAttributeNames: map[string]string{
+			"#c": "count",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":inc":   &types.AttributeValueMemberN{Value: "1"},
+			":max":   &types.AttributeValueMemberN{Value: "2"},
+			":empty": &types.AttributeValueMemberL{},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"count":  &types.AttributeValueMemberN{Value: "2"},
+		"tags":   &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+		"visits": &types.AttributeValueMemberN{Value: "1"},
+	}, out.Attributes)
+
+	_, err = c.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String("users"),
+		Key:                       map[string]types.AttributeValue{"groupID": item["groupID"], "id": item["id"]},
+		ConditionExpression:       aws.String("visits > :n"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":n": &types.AttributeValueMemberN{Value: "1"}},
+	})
+	assert.True(t, errors.As(err, &conditionErr))
+
+	// the key attributes cannot be updated
+	_, err = c.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("users"),
+		Key:                       map[string]types.AttributeValue{"groupID": item["groupID"], "id": item["id"]},
+		UpdateExpression:          aws.String("SET id = :id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":id": &types.AttributeValueMemberS{Value: "b"}},
+	})
+	assertValidationError(t, err)
+}
+
+func TestClient_Query(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+
+	for i := 0; i < 10; i++ {
+		item := map[string]types.AttributeValue{
+			"groupID": &types.AttributeValueMemberN{Value: "1"},
+			"id":      &types.AttributeValueMemberS{Value: fmt.Sprintf("item#%d", i)},
+			"n":       &types.AttributeValueMemberN{Value: fmt.Sprint(i)},
+		}
+		if i%3 == 0 {
+			item["email"] = &types.AttributeValueMemberS{Value: fmt.Sprintf("user%d@example.com", i)}
+		}
+
+		_, err := c.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("users"), Item: item})
+		require.NoError(t, err)
+	}
+
+	in := &dynamodb.QueryInput{
+		TableName:              aws.String("users"),
+		KeyConditionExpression: aws.String("groupID = :g AND begins_with(id, :p)"),
+		FilterExpression:       aws.String("n <> :five"),
+		ProjectionExpression:   aws.String("id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":g":    &types.AttributeValueMemberN{Value: "1"},
+			":p":    &types.AttributeValueMemberS{Value: "item#"},
+			":five": &types.AttributeValueMemberN{Value: "5"},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(4),
+	}
+
+	var ids []string
+	pages := 0
+	for {
+		out, err := c.Query(ctx, in)
+		require.NoError(t, err)
+		pages++
+
+		for _, item := range out.Items {
+			assert.Len(t, item, 1)
+			ids = append(ids, item["id"].(*types.AttributeValueMemberS).Value)
+		}
+
+		if out.LastEvaluatedKey == nil {
+			break
+		}
+		in.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []string{"item#9", "item#8", "item#7", "item#6", "item#4", "item#3", "item#2", "item#1", "item#0"}, ids)
+
+	// the index is sparse
+	out, err := c.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("users"), IndexName: aws.String("byEmail"), Select: types.SelectCount})
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), out.Count)
+	assert.Nil(t, out.Items)
+
+	byEmail, err := c.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String("users"),
+		IndexName:                 aws.String("byEmail"),
+		KeyConditionExpression:    aws.String("email = :e"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":e": &types.AttributeValueMemberS{Value: "user6@example.com"}},
+	})
+	require.NoError(t, err)
+	if assert.Len(t, byEmail.Items, 1) {
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "item#6"}, byEmail.Items[0]["id"])
+	}
+
+	// the scan segments cover the whole table
+	total := int32(0)
+	for segment := int32(0); segment < 3; segment++ {
+		out, err := c.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("users"), Segment: aws.Int32(segment), TotalSegments: aws.Int32(3)})
+		require.NoError(t, err)
+		total += out.Count
+	}
+	assert.Equal(t, int32(10), total)
+}
+
+func TestClient_Validation(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+
+	_, err := c.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("users"),
+		Key:       map[string]types.AttributeValue{"groupID": &types.AttributeValueMemberN{Value: "1"}},
+	})
+	assertValidationError(t, err)
+
+	_, err = c.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String("users"),
+		Item: map[string]types.AttributeValue{
+			"groupID": &types.AttributeValueMemberS{Value: "1"},
+			"id":      &types.AttributeValueMemberS{Value: "a"},
+		},
+	})
+	assertValidationError(t, err)
+
+	_, err = c.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String("users"),
+		KeyConditionExpression:    aws.String("firstName = :n"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":n": &types.AttributeValueMemberS{Value: "a"}},
+	})
+	assertValidationError(t, err)
+
+	_, err = c.Query(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String("users"),
+		IndexName:                 aws.String("byEmail"),
+		ConsistentRead:            aws.Bool(true),
+		KeyConditionExpression:    aws.String("email = :e"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":e": &types.AttributeValueMemberS{Value: "a"}},
+	})
+	assertValidationError(t, err)
+
+	_, err = c.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("users"), FilterExpression: aws.String("a = ")})
+	assertValidationError(t, err)
+
+	_, err = c.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("unknown")})
+	var notFound *types.ResourceNotFoundException
+	assert.True(t, errors.As(err, &notFound))
+
+	err = c.CreateTableFromConfig(ctx, usersConfig)
+	var inUse *types.ResourceInUseException
+	assert.True(t, errors.As(err, &inUse))
+}
+
+func TestClient_Tables(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+
+	out, err := c.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String("users")})
+	require.NoError(t, err)
+	assert.Equal(t, types.TableStatusActive, out.Table.TableStatus)
+	assert.Len(t, out.Table.KeySchema, 2)
+	if assert.Len(t, out.Table.GlobalSecondaryIndexes, 1) {
+		assert.Equal(t, "byEmail", aws.ToString(out.Table.GlobalSecondaryIndexes[0].IndexName))
+	}
+
+	tables, err := c.ListTables(ctx, &dynamodb.ListTablesInput{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"users"}, tables.TableNames)
+
+	_, err = c.DeleteTable(ctx, &dynamodb.DeleteTableInput{TableName: aws.String("users")})
+	require.NoError(t, err)
+
+	_, err = c.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String("users")})
+	assert.Error(t, err)
+}
+
+func assertValidationError(t *testing.T, err error) {
+	t.Helper()
+
+	var apiErr smithy.APIError
+	if assert.True(t, errors.As(err, &apiErr), "%v", err) {
+		assert.Equal(t, "ValidationException", apiErr.ErrorCode())
+	}
+}
+
+func TestClient_CreateTableFromConfig(t *testing.T) {
+	ctx := context.Background()
+	c := memdb.New()
+
+	conf := usersConfig
+	conf.LocalIndexes = map[dy.DBIndexName]dy.DynamoKeyMetadata{
+		"byFirstName": {Name: "firstName", Type: dy.String},
+	}
+	require.NoError(t, c.CreateTableFromConfig(ctx, conf))
+
+	// the table is created as described by the admin input of the config
+	in, err := dy.NewCreateTableInput(conf, dy.TableOptions{})
+	require.NoError(t, err)
+
+	out, err := c.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String("users")})
+	require.NoError(t, err)
+	assert.Equal(t, in.KeySchema, out.Table.KeySchema)
+	assert.ElementsMatch(t, in.AttributeDefinitions, out.Table.AttributeDefinitions)
+	assert.Equal(t, types.BillingModePayPerRequest, out.Table.BillingModeSummary.BillingMode)
+	if assert.Len(t, out.Table.GlobalSecondaryIndexes, 1) {
+		assert.Equal(t, types.ProjectionTypeAll, out.Table.GlobalSecondaryIndexes[0].Projection.ProjectionType)
+	}
+	if assert.Len(t, out.Table.LocalSecondaryIndexes, 1) {
+		assert.Equal(t, "byFirstName", aws.ToString(out.Table.LocalSecondaryIndexes[0].IndexName))
+	}
+
+	// the invalid configs are rejected
+	conf.TableInfo.TableName = "x"
+	assert.ErrorIs(t, c.CreateTableFromConfig(ctx, conf), dy.ErrInvalidConfig)
+}
@@ -0,0 +1,31 @@
+package memdb
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+func validationErrorf(format string, args ...interface{}) error {
+	return &smithy.GenericAPIError{
+		Code:    "ValidationException",
+		Message: fmt.Sprintf(format, args...),
+		Fault:   smithy.FaultClient,
+	}
+}
+
+// expressionError reports an invalid expression (e.g. "Invalid FilterExpression: ...").
+func expressionError(kind string, err error) error {
+	return validationErrorf("Invalid %s: %s", kind, err)
+}
+
+func conditionalCheckFailed(item map[string]types.AttributeValue, mode types.ReturnValuesOnConditionCheckFailure) error {
+	err := &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	if mode == types.ReturnValuesOnConditionCheckFailureAllOld {
+		err.Item = item
+	}
+
+	return err
+}
@@ -0,0 +1,381 @@
+package memdb
+
+import (
+	"context"
+	"math"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/expr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxItemSize the maximum size of an item.
+const maxItemSize = 400 * 1024
+
+// GetItem returns the item matching the provided key.
+func (c *Client) GetItem(_ context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if params.AttributesToGet != nil {
+		return nil, validationErrorf("memdb: AttributesToGet is not supported, use ProjectionExpression")
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.validateKey(params.Key); err != nil {
+		return nil, err
+	}
+
+	item := t.items[t.keyString(params.Key)]
+
+	out := &dynamodb.GetItemOutput{
+		ConsumedCapacity: consumedCapacity(t.name, readUnits(itemSize(item), aws.ToBool(params.ConsistentRead)), params.ReturnConsumedCapacity),
+	}
+
+	if item == nil {
+		return out, nil
+	}
+
+	env := newEnv(params.ExpressionAttributeNames, nil)
+	out.Item, err = project(item, params.ProjectionExpression, env)
+	return out, err
+}
+
+// BatchGetItem returns the items matching the provided keys. All the keys are always processed.
+func (c *Client) BatchGetItem(_ context.Context, params *dynamodb.BatchGetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	count := 0
+	for _, keys := range params.RequestItems {
+		count += len(keys.Keys)
+	}
+
+	if count == 0 || count > 100 {
+		return nil, validationErrorf("Too many items requested for the BatchGetItem call")
+	}
+
+	out := &dynamodb.BatchGetItemOutput{
+		Responses:       make(map[string][]map[string]types.AttributeValue),
+		UnprocessedKeys: make(map[string]types.KeysAndAttributes),
+	}
+
+	for name, keys := range params.RequestItems {
+		t, err := c.table(aws.String(name))
+		if err != nil {
+			return nil, err
+		}
+
+		if keys.AttributesToGet != nil {
+			return nil, validationErrorf("memdb: AttributesToGet is not supported, use ProjectionExpression")
+		}
+
+		env := newEnv(keys.ExpressionAttributeNames, nil)
+		seen := make(map[string]bool, len(keys.Keys))
+		items := make([]map[string]types.AttributeValue, 0, len(keys.Keys))
+		size := 0
+
+		for _, key := range keys.Keys {
+			if err := t.validateKey(key); err != nil {
+				return nil, err
+			}
+
+			k := t.keyString(key)
+			if seen[k] {
+				return nil, validationErrorf("Provided list of item keys contains duplicates")
+			}
+			seen[k] = true
+
+			item, ok := t.items[k]
+			if !ok {
+				continue
+			}
+			size += itemSize(item)
+
+			projected, err := project(item, keys.ProjectionExpression, env)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, projected)
+		}
+
+		out.Responses[name] = items
+		if c := consumedCapacity(name, readUnits(size, aws.ToBool(keys.ConsistentRead)), params.ReturnConsumedCapacity); c != nil {
+			out.ConsumedCapacity = append(out.ConsumedCapacity, *c)
+		}
+	}
+
+	return out, nil
+}
+
+// PutItem creates or replaces an item.
+func (c *Client) PutItem(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if params.Expected != nil {
+		return nil, validationErrorf("memdb: Expected is not supported, use ConditionExpression")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.validateKeyAttributes(params.Item); err != nil {
+		return nil, err
+	}
+
+	if itemSize(params.Item) > maxItemSize {
+		return nil, validationErrorf("Item size has exceeded the maximum allowed size")
+	}
+
+	key := t.keyString(params.Item)
+	old := t.items[key]
+
+	env := newEnv(params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+	if err := checkCondition(old, params.ConditionExpression, env, params.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+
+	t.items[key] = expr.CopyItem(params.Item)
+
+	out := &dynamodb.PutItemOutput{
+		ConsumedCapacity: consumedCapacity(t.name, writeUnits(math.Max(float64(itemSize(old)), float64(itemSize(params.Item)))), params.ReturnConsumedCapacity),
+	}
+
+	switch params.ReturnValues {
+	case types.ReturnValueNone, "":
+	case types.ReturnValueAllOld:
+		out.Attributes = old
+	default:
+		return nil, validationErrorf("ReturnValues can only be ALL_OLD or NONE")
+	}
+
+	return out, nil
+}
+
+// UpdateItem updates an item, creating it if it does not exist.
+func (c *Client) UpdateItem(_ context.Context, params *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	if params.AttributeUpdates != nil || params.Expected != nil {
+		return nil, validationErrorf("memdb: AttributeUpdates and Expected are not supported, use UpdateExpression and ConditionExpression")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.validateKey(params.Key); err != nil {
+		return nil, err
+	}
+
+	key := t.keyString(params.Key)
+	old := t.items[key]
+
+	env := newEnv(params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+	if err := checkCondition(old, params.ConditionExpression, env, params.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+
+	base := old
+	if base == nil {
+		base = t.primaryKey(params.Key)
+	}
+
+	updated := expr.CopyItem(base)
+	var updatedNames []string
+	if params.UpdateExpression != nil {
+		u, err := expr.ParseUpdate(aws.ToString(params.UpdateExpression))
+		if err != nil {
+			return nil, expressionError("UpdateExpression", err)
+		}
+
+		if updated, err = expr.Apply(u, base, env); err != nil {
+			return nil, expressionError("UpdateExpression", err)
+		}
+
+		if updatedNames, err = expr.UpdatedPaths(u, env); err != nil {
+			return nil, expressionError("UpdateExpression", err)
+		}
+	}
+
+	for _, name := range t.schema.names() {
+		if !expr.Equal(updated[name], base[name]) {
+			return nil, validationErrorf("One or more parameter values were invalid: Cannot update attribute %s. This attribute is part of the key", name)
+		}
+	}
+
+	if err := t.validateKeyAttributes(updated); err != nil {
+		return nil, err
+	}
+
+	if itemSize(updated) > maxItemSize {
+		return nil, validationErrorf("Item size to update has exceeded the maximum allowed size")
+	}
+
+	t.items[key] = updated
+
+	out := &dynamodb.UpdateItemOutput{
+		ConsumedCapacity: consumedCapacity(t.name, writeUnits(math.Max(float64(itemSize(old)), float64(itemSize(updated)))), params.ReturnConsumedCapacity),
+	}
+
+	switch params.ReturnValues {
+	case types.ReturnValueNone, "":
+	case types.ReturnValueAllOld:
+		out.Attributes = expr.CopyItem(old)
+	case types.ReturnValueAllNew:
+		out.Attributes = expr.CopyItem(updated)
+	case types.ReturnValueUpdatedOld:
+		out.Attributes = pick(old, updatedNames)
+	case types.ReturnValueUpdatedNew:
+		out.Attributes = pick(updated, updatedNames)
+	default:
+		return nil, validationErrorf("Invalid ReturnValues: %s", params.ReturnValues)
+	}
+
+	return out, nil
+}
+
+// DeleteItem deletes an item.
+func (c *Client) DeleteItem(_ context.Context, params *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	if params.Expected != nil {
+		return nil, validationErrorf("memdb: Expected is not supported, use ConditionExpression")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.validateKey(params.Key); err != nil {
+		return nil, err
+	}
+
+	key := t.keyString(params.Key)
+	old := t.items[key]
+
+	env := newEnv(params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+	if err := checkCondition(old, params.ConditionExpression, env, params.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+
+	delete(t.items, key)
+
+	out := &dynamodb.DeleteItemOutput{
+		ConsumedCapacity: consumedCapacity(t.name, writeUnits(float64(itemSize(old))), params.ReturnConsumedCapacity),
+	}
+
+	switch params.ReturnValues {
+	case types.ReturnValueNone, "":
+	case types.ReturnValueAllOld:
+		out.Attributes = old
+	default:
+		return nil, validationErrorf("ReturnValues can only be ALL_OLD or NONE")
+	}
+
+	return out, nil
+}
+
+func newEnv(names map[string]string, values map[string]types.AttributeValue) expr.Env {
+	return expr.Env{Names: names, Values: values}
+}
+
+// checkCondition evaluates a condition expression against the current item (nil if missing).
+func checkCondition(item map[string]types.AttributeValue, condition *string, env expr.Env, mode types.ReturnValuesOnConditionCheckFailure) error {
+	if condition == nil {
+		return nil
+	}
+
+	c, err := expr.ParseCondition(aws.ToString(condition))
+	if err != nil {
+		return expressionError("ConditionExpression", err)
+	}
+
+	ok, err := expr.Eval(c, item, env)
+	if err != nil {
+		return expressionError("ConditionExpression", err)
+	}
+
+	if !ok {
+		return conditionalCheckFailed(expr.CopyItem(item), mode)
+	}
+
+	return nil
+}
+
+// project returns a copy of the item restricted to the projection expression, if any.
+func project(item map[string]types.AttributeValue, projection *string, env expr.Env) (map[string]types.AttributeValue, error) {
+	if projection == nil {
+		return expr.CopyItem(item), nil
+	}
+
+	paths, err := expr.ParseProjection(aws.ToString(projection))
+	if err != nil {
+		return nil, expressionError("ProjectionExpression", err)
+	}
+
+	projected, err := expr.Project(item, paths, env)
+	if err != nil {
+		return nil, expressionError("ProjectionExpression", err)
+	}
+
+	return projected, nil
+}
+
+func pick(item map[string]types.AttributeValue, names []string) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+
+	picked := make(map[string]types.AttributeValue, len(names))
+	for _, name := range names {
+		if v, ok := item[name]; ok {
+			picked[name] = expr.Copy(v)
+		}
+	}
+
+	return picked
+}
+
+func consumedCapacity(table string, units float64, mode types.ReturnConsumedCapacity) *types.ConsumedCapacity {
+	switch mode {
+	case types.ReturnConsumedCapacityTotal:
+		return &types.ConsumedCapacity{TableName: aws.String(table), CapacityUnits: aws.Float64(units)}
+	case types.ReturnConsumedCapacityIndexes:
+		return &types.ConsumedCapacity{
+			TableName:     aws.String(table),
+			CapacityUnits: aws.Float64(units),
+			Table:         &types.Capacity{CapacityUnits: aws.Float64(units)},
+		}
+	}
+
+	return nil
+}
+
+// readUnits 1 RCU per started 4KB, halved for eventually consistent reads.
+func readUnits(size int, consistent bool) float64 {
+	units := math.Max(1, math.Ceil(float64(size)/4096))
+	if !consistent {
+		units /= 2
+	}
+
+	return units
+}
+
+// writeUnits 1 WCU per started KB.
+func writeUnits(size float64) float64 {
+	return math.Max(1, math.Ceil(size/1024))
+}
@@ -0,0 +1,374 @@
+package memdb
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/expr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Query returns the items matching the key condition, ordered by sort key.
+func (c *Client) Query(_ context.Context, params *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	if params.KeyConditions != nil || params.QueryFilter != nil || params.AttributesToGet != nil {
+		return nil, validationErrorf("memdb: KeyConditions, QueryFilter and AttributesToGet are not supported, use the expressions")
+	}
+
+	if params.KeyConditionExpression == nil {
+		return nil, validationErrorf("Either the KeyConditions or KeyConditionExpression parameter must be specified in the request.")
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := t.newSearch(params.IndexName, params.ConsistentRead, params.Select, params.ProjectionExpression, params.FilterExpression,
+		params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	keyCondition, err := expr.ParseCondition(aws.ToString(params.KeyConditionExpression))
+	if err != nil {
+		return nil, expressionError("KeyConditionExpression", err)
+	}
+
+	if err := validateKeyCondition(keyCondition, s.env, s.schemas[0]); err != nil {
+		return nil, err
+	}
+
+	var matching []map[string]types.AttributeValue
+	for _, item := range s.candidates() {
+		ok, err := expr.Eval(keyCondition, item, s.env)
+		if err != nil {
+			return nil, expressionError("KeyConditionExpression", err)
+		}
+
+		if ok {
+			matching = append(matching, item)
+		}
+	}
+
+	if params.ScanIndexForward != nil && !*params.ScanIndexForward {
+		for i, j := 0, len(matching)-1; i < j; i, j = i+1, j-1 {
+			matching[i], matching[j] = matching[j], matching[i]
+		}
+		s.reverse = true
+	}
+
+	p, err := s.page(matching, params.ExclusiveStartKey, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.QueryOutput{
+		Items:            p.items,
+		Count:            p.count,
+		ScannedCount:     p.scanned,
+		LastEvaluatedKey: p.lastEvaluatedKey,
+		ConsumedCapacity: consumedCapacity(t.name, readUnits(p.size, aws.ToBool(params.ConsistentRead)), params.ReturnConsumedCapacity),
+	}, nil
+}
+
+// Scan returns the items of a table or of an index, ordered by key.
+func (c *Client) Scan(_ context.Context, params *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if params.ScanFilter != nil || params.AttributesToGet != nil {
+		return nil, validationErrorf("memdb: ScanFilter and AttributesToGet are not supported, use the expressions")
+	}
+
+	segment, total := aws.ToInt32(params.Segment), aws.ToInt32(params.TotalSegments)
+	if (params.Segment != nil) != (params.TotalSegments != nil) || (total > 0 && (segment < 0 || segment >= total)) {
+		return nil, validationErrorf("The Segment parameter must be lower than TotalSegments")
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, err := c.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := t.newSearch(params.IndexName, params.ConsistentRead, params.Select, params.ProjectionExpression, params.FilterExpression,
+		params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := s.candidates()
+	if total > 0 {
+		var inSegment []map[string]types.AttributeValue
+		for _, item := range candidates {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(scalarString(item[t.schema.hash])))
+			if int32(h.Sum32()%uint32(total)) == segment {
+				inSegment = append(inSegment, item)
+			}
+		}
+		candidates = inSegment
+	}
+
+	p, err := s.page(candidates, params.ExclusiveStartKey, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.ScanOutput{
+		Items:            p.items,
+		Count:            p.count,
+		ScannedCount:     p.scanned,
+		LastEvaluatedKey: p.lastEvaluatedKey,
+		ConsumedCapacity: consumedCapacity(t.name, readUnits(p.size, aws.ToBool(params.ConsistentRead)), params.ReturnConsumedCapacity),
+	}, nil
+}
+
+// search a Query or a Scan of a table or of an index.
+type search struct {
+	table      *table
+	index      *index
+	schemas    []keySchema
+	env        expr.Env
+	filter     expr.Condition
+	projection []expr.Path
+	count      bool
+	reverse    bool
+}
+
+func (t *table) newSearch(indexName *string, consistentRead *bool, sel types.Select, projection, filter *string,
+	names map[string]string, values map[string]types.AttributeValue) (*search, error) {
+	s := &search{
+		table:   t,
+		schemas: []keySchema{t.schema},
+		env:     newEnv(names, values),
+		count:   sel == types.SelectCount,
+	}
+
+	if indexName != nil {
+		idx, ok := t.indexes[*indexName]
+		if !ok {
+			return nil, validationErrorf("The table does not have the specified index: %s", *indexName)
+		}
+
+		if idx.global && aws.ToBool(consistentRead) {
+			return nil, validationErrorf("Consistent reads are not supported on global secondary indexes")
+		}
+
+		s.index = idx
+		s.schemas = []keySchema{idx.schema, t.schema}
+	}
+
+	if sel == types.SelectSpecificAttributes && projection == nil {
+		return nil, validationErrorf("SPECIFIC_ATTRIBUTES requires a ProjectionExpression")
+	}
+
+	if projection != nil {
+		if sel != "" && sel != types.SelectSpecificAttributes {
+			return nil, validationErrorf("Cannot specify the ProjectionExpression when choosing to get %s", sel)
+		}
+
+		paths, err := expr.ParseProjection(aws.ToString(projection))
+		if err != nil {
+			return nil, expressionError("ProjectionExpression", err)
+		}
+		s.projection = paths
+	}
+
+	if filter != nil {
+		c, err := expr.ParseCondition(aws.ToString(filter))
+		if err != nil {
+			return nil, expressionError("FilterExpression", err)
+		}
+		s.filter = c
+	}
+
+	return s, nil
+}
+
+// candidates returns the items of the table or of the (sparse) index ordered by key.
+func (s *search) candidates() []map[string]types.AttributeValue {
+	items := make([]map[string]types.AttributeValue, 0, len(s.table.items))
+	for _, item := range s.table.items {
+		if s.index == nil || s.index.contains(item) {
+			items = append(items, item)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return compareKeys(items[i], items[j], s.schemas...) < 0
+	})
+
+	return items
+}
+
+type page struct {
+	items            []map[string]types.AttributeValue
+	count, scanned   int32
+	size             int
+	lastEvaluatedKey map[string]types.AttributeValue
+}
+
+// page evaluates the items following the exclusive start key, up to limit.
+// The last evaluated key is only returned if more items remain.
+func (s *search) page(items []map[string]types.AttributeValue, start map[string]types.AttributeValue, limit *int32) (*page, error) {
+	if limit != nil && *limit <= 0 {
+		return nil, validationErrorf("Limit must be greater than or equal to 1")
+	}
+
+	if start != nil {
+		for _, schema := range s.schemas {
+			for _, name := range schema.names() {
+				if _, ok := start[name]; !ok {
+					return nil, validationErrorf("The provided starting key is invalid: missing key attribute %s", name)
+				}
+			}
+		}
+
+		first := len(items)
+		for i, item := range items {
+			cmp := compareKeys(item, start, s.schemas...)
+			if (!s.reverse && cmp > 0) || (s.reverse && cmp < 0) {
+				first = i
+				break
+			}
+		}
+		items = items[first:]
+	}
+
+	p := &page{items: []map[string]types.AttributeValue{}}
+	evaluated := items
+	if limit != nil && int(*limit) < len(items) {
+		evaluated = items[:*limit]
+		p.lastEvaluatedKey = s.key(evaluated[len(evaluated)-1])
+	}
+
+	for _, item := range evaluated {
+		p.scanned++
+		p.size += itemSize(item)
+
+		if s.filter != nil {
+			ok, err := expr.Eval(s.filter, item, s.env)
+			if err != nil {
+				return nil, expressionError("FilterExpression", err)
+			}
+
+			if !ok {
+				continue
+			}
+		}
+
+		p.count++
+		if s.count {
+			continue
+		}
+
+		projected := item
+		if s.index != nil {
+			projected = s.index.project(s.table, item)
+		}
+
+		if s.projection != nil {
+			var err error
+			if projected, err = expr.Project(projected, s.projection, s.env); err != nil {
+				return nil, expressionError("ProjectionExpression", err)
+			}
+		} else {
+			projected = expr.CopyItem(projected)
+		}
+
+		p.items = append(p.items, projected)
+	}
+
+	if s.count {
+		p.items = nil
+	}
+
+	return p, nil
+}
+
+// key extracts the table and the index keys of an item.
+func (s *search) key(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := make(map[string]types.AttributeValue)
+	for _, schema := range s.schemas {
+		for _, name := range schema.names() {
+			key[name] = expr.Copy(item[name])
+		}
+	}
+
+	return key
+}
+
+// validateKeyCondition checks that the key condition is an equality on the partition key,
+// optionally combined with a single condition on the sort key.
+func validateKeyCondition(c expr.Condition, env expr.Env, schema keySchema) error {
+	var parts []expr.Condition
+	var flatten func(expr.Condition)
+	flatten = func(c expr.Condition) {
+		if and, ok := c.(expr.And); ok {
+			flatten(and.Left)
+			flatten(and.Right)
+			return
+		}
+		parts = append(parts, c)
+	}
+	flatten(c)
+
+	keyName := func(o expr.Operand) (string, error) {
+		p, ok := o.(expr.PathOperand)
+		if !ok || len(p.Path) != 1 || p.Path[0].IsIndex {
+			return "", nil
+		}
+
+		return env.Name(p.Path[0].Name)
+	}
+
+	hash, rng := 0, 0
+	for _, part := range parts {
+		var name string
+		var err error
+		var op string
+
+		switch part := part.(type) {
+		case expr.Compare:
+			name, err = keyName(part.Left)
+			op = part.Op
+			if _, ok := part.Right.(expr.ValueOperand); !ok {
+				name = ""
+			}
+		case expr.Between:
+			name, err = keyName(part.Operand)
+			op = "BETWEEN"
+		case expr.FuncCondition:
+			if part.Name == "begins_with" {
+				name, err = keyName(part.Args[0])
+				op = part.Name
+			}
+		}
+
+		if err != nil {
+			return expressionError("KeyConditionExpression", err)
+		}
+
+		switch {
+		case name == schema.hash && op == "=":
+			hash++
+		case name != "" && name == schema.rng && op != "<>":
+			rng++
+		default:
+			return validationErrorf("Query key condition not supported: %s", part)
+		}
+	}
+
+	if hash != 1 || rng > 1 {
+		return validationErrorf("Query condition missed key schema element: %s", schema.hash)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package memdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/ddbjson"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	targetPrefix = "DynamoDB_20120810."
+	errorPrefix  = "com.amazonaws.dynamodb.v20120810#"
+	contentType  = "application/x-amz-json-1.0"
+)
+
+// operation decodes a request body, calls the client and returns the output to encode.
+type operation func(ctx context.Context, body []byte) (interface{}, error)
+
+func newOperation[In, Out any](call func(context.Context, *In, ...func(*dynamodb.Options)) (*Out, error)) operation {
+	return func(ctx context.Context, body []byte) (interface{}, error) {
+		in := new(In)
+		if err := ddbjson.Unmarshal(body, in); err != nil {
+			return nil, &smithy.GenericAPIError{Code: "SerializationException", Message: err.Error(), Fault: smithy.FaultClient}
+		}
+
+		return call(ctx, in)
+	}
+}
+
+// Server serves a Client over the DynamoDB JSON protocol, the operation being selected by the X-Amz-Target header.
+// Point the BaseEndpoint of a dynamodb client to the server (e.g. an httptest.Server) to use the in-memory store:
+//
+//	srv := httptest.NewServer(memdb.NewServer(memdb.New()))
+//	defer srv.Close()
+//
+//	client := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+//		o.BaseEndpoint = aws.String(srv.URL)
+//	})
+//
+// The request signatures are not verified.
+type Server struct {
+	client     *Client
+	operations map[string]operation
+}
+
+var _ http.Handler = (*Server)(nil)
+
+// NewServer creates a new server backed by the provided client.
+func NewServer(c *Client) *Server {
+	return &Server{
+		client: c,
+		operations: map[string]operation{
+			"CreateTable":        newOperation(c.CreateTable),
+			"DescribeTable":      newOperation(c.DescribeTable),
+			"DeleteTable":        newOperation(c.DeleteTable),
+			"ListTables":         newOperation(c.ListTables),
+			"UpdateTimeToLive":   newOperation(c.UpdateTimeToLive),
+			"DescribeTimeToLive": newOperation(c.DescribeTimeToLive),
+			"GetItem":            newOperation(c.GetItem),
+			"BatchGetItem":       newOperation(c.BatchGetItem),
+			"PutItem":            newOperation(c.PutItem),
+			"UpdateItem":         newOperation(c.UpdateItem),
+			"DeleteItem":         newOperation(c.DeleteItem),
+			"Query":              newOperation(c.Query),
+			"Scan":               newOperation(c.Scan),
+		},
+	}
+}
+
+// Client returns the client backing the server.
+func (s *Server) Client() *Client {
+	return s.client
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	target := r.Header.Get("X-Amz-Target")
+	op, ok := s.operations[strings.TrimPrefix(target, targetPrefix)]
+	if !ok || !strings.HasPrefix(target, targetPrefix) {
+		writeError(w, &smithy.GenericAPIError{Code: "UnknownOperationException", Message: "unknown operation " + target, Fault: smithy.FaultClient})
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, &smithy.GenericAPIError{Code: "SerializationException", Message: err.Error(), Fault: smithy.FaultClient})
+		return
+	}
+
+	out, err := op(r.Context(), body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	data, err := ddbjson.Marshal(out)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	write(w, http.StatusOK, data)
+}
+
+// writeError writes the error code in the __type member along with the exception members (e.g. the Item
+// of a ConditionalCheckFailedException). The errors which are not API errors are internal server errors.
+func writeError(w http.ResponseWriter, err error) {
+	code, message, status := "InternalServerError", err.Error(), http.StatusInternalServerError
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code, message = apiErr.ErrorCode(), apiErr.ErrorMessage()
+		if apiErr.ErrorFault() != smithy.FaultServer {
+			status = http.StatusBadRequest
+		}
+	}
+
+	body := make(map[string]json.RawMessage)
+	if apiErr != nil {
+		if _, generic := apiErr.(*smithy.GenericAPIError); !generic {
+			if data, err := ddbjson.Marshal(apiErr); err == nil {
+				_ = json.Unmarshal(data, &body)
+			}
+		}
+	}
+	delete(body, "Message")
+	delete(body, "ErrorCodeOverride")
+
+	body["__type"], _ = json.Marshal(errorPrefix + code)
+	body["message"], _ = json.Marshal(message)
+
+	data, _ := json.Marshal(body)
+	write(w, status, data)
+}
+
+func write(w http.ResponseWriter, status int, data []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("X-Amz-Crc32", strconv.FormatUint(uint64(crc32.ChecksumIEEE(data)), 10))
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,132 @@
+package memdb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServerClient(t *testing.T, c *memdb.Client) (*dynamodb.Client, *httptest.Server) {
+	srv := httptest.NewServer(memdb.NewServer(c))
+	t.Cleanup(srv.Close)
+
+	return dynamodb.New(dynamodb.Options{
+		Region:       "us-west-1",
+		BaseEndpoint: aws.String(srv.URL),
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "key", SecretAccessKey: "secret"}, nil
+		}),
+	}), srv
+}
+
+func TestServer_DB(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newServerClient(t, memdb.New())
+
+	_, err := client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String("users"),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("groupID"), AttributeType: types.ScalarAttributeTypeN},
+			{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("email"), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("groupID"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("id"), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName:  aws.String("byEmail"),
+			KeySchema:  []types.KeySchemaElement{{AttributeName: aws.String("email"), KeyType: types.KeyTypeHash}},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+	require.NoError(t, err)
+
+	desc, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String("users")})
+	require.NoError(t, err)
+	assert.Equal(t, types.TableStatusActive, desc.Table.TableStatus)
+	assert.NotNil(t, desc.Table.CreationDateTime)
+
+	db := dy.NewClient[user](client, usersConfig)
+	for i := 1; i <= 3; i++ {
+		_, err := db.Create(ctx, user{GroupID: 1, ID: fmt.Sprintf("u%d", i), Email: "team@example.com", FirstName: fmt.Sprintf("name%d", i)})
+		require.NoError(t, err)
+	}
+
+	u, err := db.GetItem(ctx, key(1, "u2"))
+	require.NoError(t, err)
+	assert.Equal(t, "name2", u.FirstName)
+
+	require.NoError(t, db.Update(ctx, key(1, "u2"), []dy.DynamoAttribute{dy.NewDynamoStringAttrib("firstName", "updated")}))
+
+	emailKey := dy.NewDynamoStringAttrib("email", "team@example.com")
+	page, err := db.Find(ctx, dy.Request{Size: 10, Index: aws.String("byEmail"), PartitionKey: &emailKey})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 3)
+
+	items, _, err := db.GetItems(ctx, []dy.DynamoPrimaryKey{key(1, "u1"), key(1, "u2")})
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	require.NoError(t, db.Delete(ctx, key(1, "u2")))
+	_, err = db.GetItem(ctx, key(1, "u2"))
+	assert.ErrorIs(t, err, dy.ErrNotFound)
+}
+
+func TestServer_Errors(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newServerClient(t, newClient(t))
+
+	_, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("unknown"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "a"}},
+	})
+	var notFound *types.ResourceNotFoundException
+	assert.True(t, errors.As(err, &notFound), "%v", err)
+
+	item := map[string]types.AttributeValue{
+		"groupID": &types.AttributeValueMemberN{Value: "1"},
+		"id":      &types.AttributeValueMemberS{Value: "a"},
+		"data":    &types.AttributeValueMemberB{Value: []byte{0, 1, 2}},
+		"tags":    &types.AttributeValueMemberSS{Value: []string{"x", "y"}},
+	}
+
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String("users"), Item: item})
+	require.NoError(t, err)
+
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                           aws.String("users"),
+		Item:                                item,
+		ConditionExpression:                 aws.String("attribute_not_exists(id)"),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var conditionErr *types.ConditionalCheckFailedException
+	if assert.True(t, errors.As(err, &conditionErr), "%v", err) {
+		assert.Equal(t, item, conditionErr.Item)
+		assert.Equal(t, "The conditional request failed", conditionErr.ErrorMessage())
+	}
+
+	_, err = client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("users"), FilterExpression: aws.String("a = ")})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "ValidationException")
+	}
+
+	resp, err := http.Post(srv.URL, "application/x-amz-json-1.0", strings.NewReader("{}"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
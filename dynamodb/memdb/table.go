@@ -0,0 +1,426 @@
+package memdb
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/expr"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// keySchema the partition and the (optional) sort key of a table or an index.
+type keySchema struct {
+	hash, rng         string
+	hashType, rngType types.ScalarAttributeType
+}
+
+func (k keySchema) names() []string {
+	if k.rng == "" {
+		return []string{k.hash}
+	}
+
+	return []string{k.hash, k.rng}
+}
+
+type index struct {
+	name       string
+	global     bool
+	schema     keySchema
+	projection types.Projection
+	throughput *types.ProvisionedThroughput
+}
+
+type table struct {
+	name       string
+	created    time.Time
+	schema     keySchema
+	indexes    map[string]*index
+	input      *dynamodb.CreateTableInput
+	attributes map[string]types.ScalarAttributeType
+	items      map[string]map[string]types.AttributeValue
+	ttl        *types.TimeToLiveSpecification
+}
+
+func newTable(in *dynamodb.CreateTableInput, now time.Time) (*table, error) {
+	name := aws.ToString(in.TableName)
+	if len(name) < 3 || len(name) > 255 {
+		return nil, validationErrorf("TableName must be at least 3 characters long and at most 255 characters long")
+	}
+
+	t := &table{
+		name:       name,
+		created:    now,
+		indexes:    make(map[string]*index),
+		input:      in,
+		attributes: make(map[string]types.ScalarAttributeType),
+		items:      make(map[string]map[string]types.AttributeValue),
+	}
+
+	for _, def := range in.AttributeDefinitions {
+		t.attributes[aws.ToString(def.AttributeName)] = def.AttributeType
+	}
+
+	schema, err := t.keySchema(in.KeySchema)
+	if err != nil {
+		return nil, err
+	}
+	t.schema = schema
+
+	for _, gsi := range in.GlobalSecondaryIndexes {
+		if err := t.addIndex(aws.ToString(gsi.IndexName), true, gsi.KeySchema, gsi.Projection, gsi.ProvisionedThroughput); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, lsi := range in.LocalSecondaryIndexes {
+		if err := t.addIndex(aws.ToString(lsi.IndexName), false, lsi.KeySchema, lsi.Projection, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func (t *table) keySchema(elements []types.KeySchemaElement) (keySchema, error) {
+	if len(elements) == 0 || len(elements) > 2 || elements[0].KeyType != types.KeyTypeHash ||
+		(len(elements) == 2 && elements[1].KeyType != types.KeyTypeRange) {
+		return keySchema{}, validationErrorf("Invalid KeySchema: the first element must be a HASH key, optionally followed by a RANGE key")
+	}
+
+	var schema keySchema
+	for i, e := range elements {
+		name := aws.ToString(e.AttributeName)
+		attrType, ok := t.attributes[name]
+		if !ok {
+			return keySchema{}, validationErrorf("One or more parameter values were invalid: Some index key attributes are not defined in AttributeDefinitions. Keys: [%s]", name)
+		}
+
+		if i == 0 {
+			schema.hash, schema.hashType = name, attrType
+			continue
+		}
+		schema.rng, schema.rngType = name, attrType
+	}
+
+	return schema, nil
+}
+
+func (t *table) addIndex(name string, global bool, elements []types.KeySchemaElement, projection *types.Projection, throughput *types.ProvisionedThroughput) error {
+	if _, ok := t.indexes[name]; ok || name == "" {
+		return validationErrorf("One or more parameter values were invalid: Duplicate index name: %s", name)
+	}
+
+	schema, err := t.keySchema(elements)
+	if err != nil {
+		return err
+	}
+
+	if !global && (schema.hash != t.schema.hash || schema.rng == "") {
+		return validationErrorf("One or more parameter values were invalid: Index KeySchema does not have the same leading hash key as table KeySchema for index: %s", name)
+	}
+
+	idx := &index{
+		name:       name,
+		global:     global,
+		schema:     schema,
+		throughput: throughput,
+		projection: types.Projection{ProjectionType: types.ProjectionTypeAll},
+	}
+	if projection != nil {
+		idx.projection = *projection
+	}
+
+	t.indexes[name] = idx
+	return nil
+}
+
+func (t *table) describe() *types.TableDescription {
+	arn := "arn:aws:dynamodb:local:000000000000:table/" + t.name
+	size := int64(0)
+	for _, item := range t.items {
+		size += int64(itemSize(item))
+	}
+
+	desc := &types.TableDescription{
+		TableName:            aws.String(t.name),
+		TableArn:             aws.String(arn),
+		TableId:              aws.String(t.name),
+		TableStatus:          types.TableStatusActive,
+		CreationDateTime:     aws.Time(t.created),
+		KeySchema:            t.input.KeySchema,
+		AttributeDefinitions: t.input.AttributeDefinitions,
+		ItemCount:            aws.Int64(int64(len(t.items))),
+		TableSizeBytes:       aws.Int64(size),
+		StreamSpecification:  t.input.StreamSpecification,
+	}
+
+	if spec := t.input.StreamSpecification; spec != nil && aws.ToBool(spec.StreamEnabled) {
+		label := t.created.UTC().Format("2006-01-02T15:04:05.000")
+		desc.LatestStreamLabel = aws.String(label)
+		desc.LatestStreamArn = aws.String(arn + "/stream/" + label)
+	}
+
+	if t.input.BillingMode != "" {
+		desc.BillingModeSummary = &types.BillingModeSummary{BillingMode: t.input.BillingMode}
+	}
+
+	if p := t.input.ProvisionedThroughput; p != nil {
+		desc.ProvisionedThroughput = &types.ProvisionedThroughputDescription{
+			ReadCapacityUnits:  p.ReadCapacityUnits,
+			WriteCapacityUnits: p.WriteCapacityUnits,
+		}
+	}
+
+	names := make([]string, 0, len(t.indexes))
+	for name := range t.indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		idx := t.indexes[name]
+		projection := idx.projection
+		count := int64(0)
+		for _, item := range t.items {
+			if idx.contains(item) {
+				count++
+			}
+		}
+
+		var keySchema []types.KeySchemaElement
+		for i, k := range idx.schema.names() {
+			keyType := types.KeyTypeHash
+			if i > 0 {
+				keyType = types.KeyTypeRange
+			}
+			keySchema = append(keySchema, types.KeySchemaElement{AttributeName: aws.String(k), KeyType: keyType})
+		}
+
+		if !idx.global {
+			desc.LocalSecondaryIndexes = append(desc.LocalSecondaryIndexes, types.LocalSecondaryIndexDescription{
+				IndexName:  aws.String(name),
+				IndexArn:   aws.String(arn + "/index/" + name),
+				KeySchema:  keySchema,
+				Projection: &projection,
+				ItemCount:  aws.Int64(count),
+			})
+			continue
+		}
+
+		gsi := types.GlobalSecondaryIndexDescription{
+			IndexName:   aws.String(name),
+			IndexArn:    aws.String(arn + "/index/" + name),
+			IndexStatus: types.IndexStatusActive,
+			KeySchema:   keySchema,
+			Projection:  &projection,
+			ItemCount:   aws.Int64(count),
+		}
+		if idx.throughput != nil {
+			gsi.ProvisionedThroughput = &types.ProvisionedThroughputDescription{
+				ReadCapacityUnits:  idx.throughput.ReadCapacityUnits,
+				WriteCapacityUnits: idx.throughput.WriteCapacityUnits,
+			}
+		}
+		desc.GlobalSecondaryIndexes = append(desc.GlobalSecondaryIndexes, gsi)
+	}
+
+	return desc
+}
+
+// validateKey checks that a key matches exactly the key schema of the table.
+func (t *table) validateKey(key map[string]types.AttributeValue) error {
+	if len(key) != len(t.schema.names()) {
+		return validationErrorf("The provided key element does not match the schema")
+	}
+
+	return t.validateKeyAttributes(key)
+}
+
+// validateKeyAttributes checks that an item has the key attributes of the table with the right types,
+// and that the index key attributes (if any) have the right types.
+func (t *table) validateKeyAttributes(item map[string]types.AttributeValue) error {
+	for _, name := range t.schema.names() {
+		v, ok := item[name]
+		if !ok || !hasType(v, t.attributes[name]) {
+			return validationErrorf("One or more parameter values were invalid: Missing the key %s in the item or the key type does not match the schema", name)
+		}
+
+		if isEmptyKey(v) {
+			return validationErrorf("One or more parameter values are not valid. The AttributeValue for a key attribute cannot contain an empty string value. Key: %s", name)
+		}
+	}
+
+	for _, idx := range t.indexes {
+		for _, name := range idx.schema.names() {
+			if v, ok := item[name]; ok && (!hasType(v, t.attributes[name]) || isEmptyKey(v)) {
+				return validationErrorf("One or more parameter values were invalid: Type mismatch for Index Key %s Expected: %s IndexName: %s", name, t.attributes[name], idx.name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasType(v types.AttributeValue, attrType types.ScalarAttributeType) bool {
+	return expr.TypeOf(v) == string(attrType)
+}
+
+func isEmptyKey(v types.AttributeValue) bool {
+	switch x := v.(type) {
+	case *types.AttributeValueMemberS:
+		return x.Value == ""
+	case *types.AttributeValueMemberB:
+		return len(x.Value) == 0
+	}
+
+	return false
+}
+
+// primaryKey extracts the primary key of an item.
+func (t *table) primaryKey(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := make(map[string]types.AttributeValue, 2)
+	for _, name := range t.schema.names() {
+		key[name] = expr.Copy(item[name])
+	}
+
+	return key
+}
+
+// keyString the storage key of an item.
+func (t *table) keyString(item map[string]types.AttributeValue) string {
+	parts := make([]string, 0, 2)
+	for _, name := range t.schema.names() {
+		parts = append(parts, scalarString(item[name]))
+	}
+
+	return strings.Join(parts, "|")
+}
+
+func scalarString(v types.AttributeValue) string {
+	switch x := v.(type) {
+	case *types.AttributeValueMemberS:
+		return "S:" + x.Value
+	case *types.AttributeValueMemberN:
+		// normalize the number so that 1 and 1.0 are the same key
+		if n, err := expr.ParseNumber(x.Value); err == nil {
+			return "N:" + expr.FormatNumber(n)
+		}
+		return "N:" + x.Value
+	case *types.AttributeValueMemberB:
+		return "B:" + base64.StdEncoding.EncodeToString(x.Value)
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+// contains checks whether an item is projected into the (sparse) index.
+func (idx *index) contains(item map[string]types.AttributeValue) bool {
+	for _, name := range idx.schema.names() {
+		if _, ok := item[name]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// project restricts an item to the attributes projected into the index.
+func (idx *index) project(t *table, item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if idx.projection.ProjectionType == types.ProjectionTypeAll || idx.projection.ProjectionType == "" {
+		return item
+	}
+
+	projected := make(map[string]types.AttributeValue)
+	names := append(t.schema.names(), idx.schema.names()...)
+	if idx.projection.ProjectionType == types.ProjectionTypeInclude {
+		names = append(names, idx.projection.NonKeyAttributes...)
+	}
+
+	for _, name := range names {
+		if v, ok := item[name]; ok {
+			projected[name] = v
+		}
+	}
+
+	return projected
+}
+
+// compareKeys orders two items by the keys of the provided schemas.
+func compareKeys(a, b map[string]types.AttributeValue, schemas ...keySchema) int {
+	for _, schema := range schemas {
+		for _, name := range schema.names() {
+			x, okX := a[name]
+			y, okY := b[name]
+			switch {
+			case !okX && !okY:
+				continue
+			case !okX:
+				return -1
+			case !okY:
+				return 1
+			}
+
+			if cmp, ok := expr.CompareValues(x, y); ok && cmp != 0 {
+				return cmp
+			}
+		}
+	}
+
+	return 0
+}
+
+// itemSize approximates the size of an item as computed by dynamodb.
+func itemSize(item map[string]types.AttributeValue) int {
+	size := 0
+	for name, value := range item {
+		size += len(name) + attributeSize(value)
+	}
+
+	return size
+}
+
+func attributeSize(av types.AttributeValue) int {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return len(v.Value)/2 + 1
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberSS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		size := 0
+		for _, n := range v.Value {
+			size += len(n)/2 + 1
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		size := 0
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := 3
+		for _, e := range v.Value {
+			size += 1 + attributeSize(e)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		return 3 + itemSize(v.Value) + len(v.Value)
+	}
+
+	// BOOL and NULL
+	return 1
+}
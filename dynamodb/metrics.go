@@ -0,0 +1,205 @@
+package dy
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MetricLabels identifies the dynamodb call a metric refers to.
+type MetricLabels struct {
+	Table     string
+	Index     string
+	Operation string
+}
+
+// Metrics receives the dynamodb call metrics.
+type Metrics interface {
+	// ObserveCall records the outcome and the latency of a dynamodb call.
+	ObserveCall(labels MetricLabels, latency time.Duration, err error)
+	// AddConsumedCapacity records the read and write capacity units consumed by a dynamodb call.
+	AddConsumedCapacity(labels MetricLabels, read, write float64)
+}
+
+// WithMetrics emits the calls count, latency and consumed capacity metrics of every DynamoClient call.
+//
+// The consumed capacity is only reported by dynamodb if requested, see WithConsumedCapacity.
+func WithMetrics(metrics Metrics) Option {
+	return func(o *options) {
+		o.metrics = metrics
+	}
+}
+
+// WithConsumedCapacity sets the ReturnConsumedCapacity mode of every DynamoClient call.
+// Use types.ReturnConsumedCapacityIndexes to get the consumed capacity per index.
+func WithConsumedCapacity(mode types.ReturnConsumedCapacity) Option {
+	return func(o *options) {
+		o.consumedCapacity = mode
+	}
+}
+
+// metricsMiddleware reports the call metrics to m.
+func metricsMiddleware(m Metrics) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (interface{}, error) {
+			start := time.Now()
+			out, err := next(ctx, call)
+
+			m.ObserveCall(MetricLabels{
+				Table:     call.Table,
+				Index:     call.Index,
+				Operation: call.Operation,
+			}, time.Since(start), err)
+
+			for _, c := range consumedCapacityOf(out) {
+				addConsumedCapacity(m, call, c)
+			}
+
+			return out, err
+		}
+	}
+}
+
+// consumedCapacityMiddleware requests the consumed capacity for every call.
+func consumedCapacityMiddleware(mode types.ReturnConsumedCapacity) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (interface{}, error) {
+			call.Input = withConsumedCapacity(call.Input, mode, true)
+			return next(ctx, call)
+		}
+	}
+}
+
+// withConsumedCapacity returns the input requesting the consumed capacity mode. The input is copied, not modified,
+// as it belongs to the caller. The mode already set in the input is kept unless override.
+func withConsumedCapacity(input interface{}, mode types.ReturnConsumedCapacity, override bool) interface{} {
+	switch in := input.(type) {
+	case *dynamodb.ScanInput:
+		return setConsumedCapacity(in, func(in *dynamodb.ScanInput) *types.ReturnConsumedCapacity {
+			return &in.ReturnConsumedCapacity
+		}, mode, override)
+	case *dynamodb.QueryInput:
+		return setConsumedCapacity(in, func(in *dynamodb.QueryInput) *types.ReturnConsumedCapacity {
+			return &in.ReturnConsumedCapacity
+		}, mode, override)
+	case *dynamodb.GetItemInput:
+		return setConsumedCapacity(in, func(in *dynamodb.GetItemInput) *types.ReturnConsumedCapacity {
+			return &in.ReturnConsumedCapacity
+		}, mode, override)
+	case *dynamodb.BatchGetItemInput:
+		return setConsumedCapacity(in, func(in *dynamodb.BatchGetItemInput) *types.ReturnConsumedCapacity {
+			return &in.ReturnConsumedCapacity
+		}, mode, override)
+	case *dynamodb.PutItemInput:
+		return setConsumedCapacity(in, func(in *dynamodb.PutItemInput) *types.ReturnConsumedCapacity {
+			return &in.ReturnConsumedCapacity
+		}, mode, override)
+	case *dynamodb.UpdateItemInput:
+		return setConsumedCapacity(in, func(in *dynamodb.UpdateItemInput) *types.ReturnConsumedCapacity {
+			return &in.ReturnConsumedCapacity
+		}, mode, override)
+	case *dynamodb.DeleteItemInput:
+		return setConsumedCapacity(in, func(in *dynamodb.DeleteItemInput) *types.ReturnConsumedCapacity {
+			return &in.ReturnConsumedCapacity
+		}, mode, override)
+	}
+
+	return input
+}
+
+// setConsumedCapacity returns a copy of in requesting the consumed capacity mode, or in if unchanged.
+func setConsumedCapacity[In any](in *In, field func(*In) *types.ReturnConsumedCapacity,
+	mode types.ReturnConsumedCapacity, override bool) *In {
+	if current := *field(in); current == mode || (current != "" && !override) {
+		return in
+	}
+
+	clone := *in
+	*field(&clone) = mode
+	return &clone
+}
+
+// addConsumedCapacity reports the consumed capacity of the table and, if detailed, of its indexes.
+func addConsumedCapacity(m Metrics, call *Call, c types.ConsumedCapacity) {
+	labels := MetricLabels{
+		Table:     aws.ToString(c.TableName),
+		Index:     call.Index,
+		Operation: call.Operation,
+	}
+
+	write := isWriteOperation(call.Operation)
+
+	// without the details per index, the whole consumption is attributed to the targeted index (if any)
+	if c.Table == nil && len(c.GlobalSecondaryIndexes) == 0 && len(c.LocalSecondaryIndexes) == 0 {
+		read, written := splitCapacity(c.CapacityUnits, c.ReadCapacityUnits, c.WriteCapacityUnits, write)
+		m.AddConsumedCapacity(labels, read, written)
+		return
+	}
+
+	if c.Table != nil {
+		labels.Index = ""
+		read, written := splitCapacity(c.Table.CapacityUnits, c.Table.ReadCapacityUnits, c.Table.WriteCapacityUnits, write)
+		m.AddConsumedCapacity(labels, read, written)
+	}
+
+	for _, indexes := range []map[string]types.Capacity{c.GlobalSecondaryIndexes, c.LocalSecondaryIndexes} {
+		for name, capacity := range indexes {
+			labels.Index = name
+			read, written := splitCapacity(capacity.CapacityUnits, capacity.ReadCapacityUnits, capacity.WriteCapacityUnits, write)
+			m.AddConsumedCapacity(labels, read, written)
+		}
+	}
+}
+
+// splitCapacity returns the read and write units, attributing the total units according to the operation
+// if the read/write details are not provided.
+func splitCapacity(total, read, write *float64, isWrite bool) (float64, float64) {
+	if read != nil || write != nil {
+		return aws.ToFloat64(read), aws.ToFloat64(write)
+	}
+
+	if isWrite {
+		return 0, aws.ToFloat64(total)
+	}
+
+	return aws.ToFloat64(total), 0
+}
+
+func isWriteOperation(operation string) bool {
+	switch operation {
+	case OperationPutItem, OperationUpdateItem, OperationDeleteItem:
+		return true
+	}
+
+	return false
+}
+
+// consumedCapacityOf extracts the consumed capacity from an operation output.
+func consumedCapacityOf(out interface{}) []types.ConsumedCapacity {
+	var c *types.ConsumedCapacity
+	switch o := out.(type) {
+	case *dynamodb.ScanOutput:
+		c = o.ConsumedCapacity
+	case *dynamodb.QueryOutput:
+		c = o.ConsumedCapacity
+	case *dynamodb.GetItemOutput:
+		c = o.ConsumedCapacity
+	case *dynamodb.BatchGetItemOutput:
+		return o.ConsumedCapacity
+	case *dynamodb.PutItemOutput:
+		c = o.ConsumedCapacity
+	case *dynamodb.UpdateItemOutput:
+		c = o.ConsumedCapacity
+	case *dynamodb.DeleteItemOutput:
+		c = o.ConsumedCapacity
+	}
+
+	if c == nil {
+		return nil
+	}
+
+	return []types.ConsumedCapacity{*c}
+}
@@ -0,0 +1,174 @@
+package dy_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type capacity struct {
+	read, write float64
+}
+
+type metricsRecorder struct {
+	mu       sync.Mutex
+	calls    map[dy.MetricLabels]int
+	errors   map[dy.MetricLabels]int
+	capacity map[dy.MetricLabels]capacity
+}
+
+func newMetricsRecorder() *metricsRecorder {
+	return &metricsRecorder{
+		calls:    make(map[dy.MetricLabels]int),
+		errors:   make(map[dy.MetricLabels]int),
+		capacity: make(map[dy.MetricLabels]capacity),
+	}
+}
+
+func (r *metricsRecorder) ObserveCall(labels dy.MetricLabels, _ time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls[labels]++
+	if err != nil {
+		r.errors[labels]++
+	}
+}
+
+func (r *metricsRecorder) AddConsumedCapacity(labels dy.MetricLabels, read, write float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.capacity[labels]
+	c.read += read
+	c.write += write
+	r.capacity[labels] = c
+}
+
+func TestWithMetrics(t *testing.T) {
+	withCapacity := func(mode types.ReturnConsumedCapacity) interface{} {
+		return mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+			return in.ReturnConsumedCapacity == mode
+		})
+	}
+
+	m := mocks.NewDynamoClient(t)
+	m.On("Query", mock.Anything, withCapacity(types.ReturnConsumedCapacityIndexes)).Return(&dynamodb.QueryOutput{
+		ConsumedCapacity: &types.ConsumedCapacity{
+			TableName:     aws.String("tableName"),
+			CapacityUnits: aws.Float64(3),
+			Table:         &types.Capacity{CapacityUnits: aws.Float64(1)},
+			GlobalSecondaryIndexes: map[string]types.Capacity{
+				"byGroup": {CapacityUnits: aws.Float64(2)},
+			},
+		},
+	}, nil).Once()
+	m.On("PutItem", mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{
+		ConsumedCapacity: &types.ConsumedCapacity{
+			TableName:     aws.String("tableName"),
+			CapacityUnits: aws.Float64(2),
+		},
+	}, nil).Once()
+	m.On("BatchGetItem", mock.Anything, mock.Anything).Return(&dynamodb.BatchGetItemOutput{
+		ConsumedCapacity: []types.ConsumedCapacity{
+			{
+				TableName:         aws.String("tableName"),
+				ReadCapacityUnits: aws.Float64(0.5),
+			},
+		},
+	}, nil).Once()
+	m.On("DeleteItem", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("error")).Once()
+
+	recorder := newMetricsRecorder()
+	db := dy.NewClient[entity](m, dbConfig,
+		dy.WithMetrics(recorder),
+		dy.WithConsumedCapacity(types.ReturnConsumedCapacityIndexes),
+	)
+
+	ctx := context.Background()
+	key := dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	}
+
+	_, err := db.Find(ctx, dy.Request{
+		Size:         1,
+		Index:        aws.String("byGroup"),
+		PartitionKey: dy.NewDynamoNumberAttrib("groupID", "1234"),
+	})
+	assert.NoError(t, err)
+	_, err = db.Create(ctx, entity{Id: "123", GroupID: aws.Int(1234)})
+	assert.NoError(t, err)
+	_, _, err = db.GetItems(ctx, []dy.DynamoPrimaryKey{key})
+	assert.NoError(t, err)
+	assert.Error(t, db.Delete(ctx, key))
+
+	query := dy.MetricLabels{Table: "tableName", Index: "byGroup", Operation: dy.OperationQuery}
+	put := dy.MetricLabels{Table: "tableName", Operation: dy.OperationPutItem}
+	batch := dy.MetricLabels{Table: "tableName", Operation: dy.OperationBatchGetItem}
+	del := dy.MetricLabels{Table: "tableName", Operation: dy.OperationDeleteItem}
+
+	assert.Equal(t, map[dy.MetricLabels]int{query: 1, put: 1, batch: 1, del: 1}, recorder.calls)
+	assert.Equal(t, map[dy.MetricLabels]int{del: 1}, recorder.errors)
+	assert.Equal(t, map[dy.MetricLabels]capacity{
+		{Table: "tableName", Operation: dy.OperationQuery}: {read: 1},
+		query: {read: 2},
+		put:   {write: 2},
+		batch: {read: 0.5},
+	}, recorder.capacity)
+}
+
+func TestWithMetrics_WithoutConsumedCapacity(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("GetItem", mock.Anything, mock.MatchedBy(func(in *dynamodb.GetItemInput) bool {
+		return in.ReturnConsumedCapacity == ""
+	})).Return(&dynamodb.GetItemOutput{}, nil).Once()
+
+	recorder := newMetricsRecorder()
+	db := dy.NewClient[entity](m, dbConfig, dy.WithMetrics(recorder))
+
+	_, err := db.GetItem(context.Background(), dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	})
+	assert.ErrorIs(t, err, dy.ErrNotFound)
+
+	assert.Equal(t, map[dy.MetricLabels]int{
+		{Table: "tableName", Operation: dy.OperationGetItem}: 1,
+	}, recorder.calls)
+	assert.Empty(t, recorder.capacity)
+}
+
+func TestWithConsumedCapacity_KeepsInput(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("GetItem", mock.Anything, mock.MatchedBy(func(in *dynamodb.GetItemInput) bool {
+		return in.ReturnConsumedCapacity == types.ReturnConsumedCapacityIndexes
+	})).Return(&dynamodb.GetItemOutput{}, nil).Once()
+
+	// the input seen by the outer middlewares is not modified
+	var input *dynamodb.GetItemInput
+	observe := func(next dy.Handler) dy.Handler {
+		return func(ctx context.Context, call *dy.Call) (interface{}, error) {
+			input = call.Input.(*dynamodb.GetItemInput)
+			return next(ctx, call)
+		}
+	}
+
+	db := dy.NewClient[entity](m, dbConfig,
+		dy.WithMiddleware(observe), dy.WithConsumedCapacity(types.ReturnConsumedCapacityIndexes))
+
+	_, err := db.GetItem(context.Background(), dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	})
+	assert.ErrorIs(t, err, dy.ErrNotFound)
+	assert.Empty(t, input.ReturnConsumedCapacity)
+}
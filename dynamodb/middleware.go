@@ -0,0 +1,185 @@
+package dy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// The DynamoClient operation names.
+const (
+	OperationScan         = "Scan"
+	OperationQuery        = "Query"
+	OperationGetItem      = "GetItem"
+	OperationBatchGetItem = "BatchGetItem"
+	OperationPutItem      = "PutItem"
+	OperationUpdateItem   = "UpdateItem"
+	OperationDeleteItem   = "DeleteItem"
+)
+
+// Call describes a DynamoClient call going through the middleware chain.
+type Call struct {
+	// Operation the DynamoClient operation name (e.g. OperationQuery).
+	Operation string
+	// Table the targeted table name.
+	Table string
+	// Index the targeted index name, empty if the call does not target an index.
+	Index string
+	// Input the operation input (e.g. *dynamodb.QueryInput).
+	Input interface{}
+}
+
+// Handler handles a DynamoClient call and returns the operation output (e.g. *dynamodb.QueryOutput).
+type Handler func(ctx context.Context, call *Call) (interface{}, error)
+
+// Middleware wraps a Handler to add logging, metrics, tracing...
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middlewares around every DynamoClient call.
+// The first middleware is the outermost one.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(o *options) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// middlewareClient a DynamoClient running every call through a middleware chain.
+type middlewareClient struct {
+	client      DynamoClient
+	middlewares []Middleware
+}
+
+var _ DynamoClient = (*middlewareClient)(nil)
+
+func newMiddlewareClient(client DynamoClient, middlewares []Middleware) DynamoClient {
+	if len(middlewares) == 0 {
+		return client
+	}
+
+	return &middlewareClient{
+		client:      client,
+		middlewares: middlewares,
+	}
+}
+
+func (c *middlewareClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	call := &Call{
+		Operation: OperationScan,
+		Table:     aws.ToString(params.TableName),
+		Index:     aws.ToString(params.IndexName),
+		Input:     params,
+	}
+
+	return invoke(ctx, c, call, func(ctx context.Context, in *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+		return c.client.Scan(ctx, in, optFns...)
+	})
+}
+
+func (c *middlewareClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	call := &Call{
+		Operation: OperationQuery,
+		Table:     aws.ToString(params.TableName),
+		Index:     aws.ToString(params.IndexName),
+		Input:     params,
+	}
+
+	return invoke(ctx, c, call, func(ctx context.Context, in *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		return c.client.Query(ctx, in, optFns...)
+	})
+}
+
+func (c *middlewareClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	call := &Call{
+		Operation: OperationGetItem,
+		Table:     aws.ToString(params.TableName),
+		Input:     params,
+	}
+
+	return invoke(ctx, c, call, func(ctx context.Context, in *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		return c.client.GetItem(ctx, in, optFns...)
+	})
+}
+
+func (c *middlewareClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	tables := make([]string, 0, len(params.RequestItems))
+	for table := range params.RequestItems {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	call := &Call{
+		Operation: OperationBatchGetItem,
+		Table:     strings.Join(tables, ","),
+		Input:     params,
+	}
+
+	return invoke(ctx, c, call, func(ctx context.Context, in *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+		return c.client.BatchGetItem(ctx, in, optFns...)
+	})
+}
+
+func (c *middlewareClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	call := &Call{
+		Operation: OperationPutItem,
+		Table:     aws.ToString(params.TableName),
+		Input:     params,
+	}
+
+	return invoke(ctx, c, call, func(ctx context.Context, in *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		return c.client.PutItem(ctx, in, optFns...)
+	})
+}
+
+func (c *middlewareClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	call := &Call{
+		Operation: OperationUpdateItem,
+		Table:     aws.ToString(params.TableName),
+		Input:     params,
+	}
+
+	return invoke(ctx, c, call, func(ctx context.Context, in *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		return c.client.UpdateItem(ctx, in, optFns...)
+	})
+}
+
+func (c *middlewareClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	call := &Call{
+		Operation: OperationDeleteItem,
+		Table:     aws.ToString(params.TableName),
+		Input:     params,
+	}
+
+	return invoke(ctx, c, call, func(ctx context.Context, in *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+		return c.client.DeleteItem(ctx, in, optFns...)
+	})
+}
+
+// invoke runs the call through the middleware chain, the innermost handler being fn.
+func invoke[In, Out any](ctx context.Context, c *middlewareClient, call *Call, fn func(context.Context, In) (*Out, error)) (*Out, error) {
+	var handler Handler = func(ctx context.Context, call *Call) (interface{}, error) {
+		in, ok := call.Input.(In)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s input type %T", call.Operation, call.Input)
+		}
+
+		out, err := fn(ctx, in)
+		if out == nil {
+			// avoid handing a typed nil to the middlewares
+			return nil, err
+		}
+
+		return out, err
+	}
+
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		handler = c.middlewares[i](handler)
+	}
+
+	res, err := handler(ctx, call)
+	out, _ := res.(*Out)
+	return out, err
+}
@@ -0,0 +1,116 @@
+package dy_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("Scan", mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{}, nil)
+	m.On("Query", mock.Anything, mock.Anything).Return(&dynamodb.QueryOutput{}, nil)
+	m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{
+		Item: getItemAttributeValuesTestData()[0],
+	}, nil)
+	m.On("BatchGetItem", mock.Anything, mock.Anything).Return(&dynamodb.BatchGetItemOutput{}, nil)
+	m.On("PutItem", mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil)
+	m.On("UpdateItem", mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil)
+	m.On("DeleteItem", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("error"))
+
+	var calls []string
+	recorder := func(name string) dy.Middleware {
+		return func(next dy.Handler) dy.Handler {
+			return func(ctx context.Context, call *dy.Call) (interface{}, error) {
+				calls = append(calls, fmt.Sprintf("%s>%s:%s:%s", name, call.Operation, call.Table, call.Index))
+				out, err := next(ctx, call)
+				assert.Equal(t, err == nil, out != nil)
+				calls = append(calls, fmt.Sprintf("%s<%s:%v", name, call.Operation, err))
+				return out, err
+			}
+		}
+	}
+
+	db := dy.NewClient[entity](m, dbConfig, dy.WithMiddleware(recorder("a"), recorder("b")))
+	ctx := context.Background()
+	key := dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+		SortKey:      &dy.DynamoAttribute{KeyName: "id", Type: dy.String, Value: "123"},
+	}
+
+	_, err := db.Find(ctx, dy.Request{
+		Size:       1,
+		Index:      aws.String("byName"),
+		Conditions: []dy.Criteria{*dy.NewCriteria().And("firstName", "f1", dy.EQUAL)},
+	})
+	assert.NoError(t, err)
+	_, err = db.Find(ctx, dy.Request{Size: 1, PartitionKey: dy.NewDynamoNumberAttrib("groupID", "1234")})
+	assert.NoError(t, err)
+	_, err = db.GetItem(ctx, key)
+	assert.NoError(t, err)
+	_, _, err = db.GetItems(ctx, []dy.DynamoPrimaryKey{key})
+	assert.NoError(t, err)
+	_, err = db.Create(ctx, entity{Id: "123", GroupID: aws.Int(1234)})
+	assert.NoError(t, err)
+	err = db.Update(ctx, key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("firstName", "name")})
+	assert.NoError(t, err)
+	err = db.Delete(ctx, key)
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{
+		"a>Scan:tableName:byName", "b>Scan:tableName:byName", "b<Scan:<nil>", "a<Scan:<nil>",
+		"a>Query:tableName:", "b>Query:tableName:", "b<Query:<nil>", "a<Query:<nil>",
+		"a>GetItem:tableName:", "b>GetItem:tableName:", "b<GetItem:<nil>", "a<GetItem:<nil>",
+		"a>BatchGetItem:tableName:", "b>BatchGetItem:tableName:", "b<BatchGetItem:<nil>", "a<BatchGetItem:<nil>",
+		"a>PutItem:tableName:", "b>PutItem:tableName:", "b<PutItem:<nil>", "a<PutItem:<nil>",
+		"a>UpdateItem:tableName:", "b>UpdateItem:tableName:", "b<UpdateItem:<nil>", "a<UpdateItem:<nil>",
+		"a>DeleteItem:tableName:", "b>DeleteItem:tableName:", "b<DeleteItem:error", "a<DeleteItem:error",
+	}, calls)
+}
+
+func TestWithMiddleware_ShortCircuit(t *testing.T) {
+	cached := func(next dy.Handler) dy.Handler {
+		return func(ctx context.Context, call *dy.Call) (interface{}, error) {
+			if call.Operation != dy.OperationGetItem {
+				return next(ctx, call)
+			}
+
+			return &dynamodb.GetItemOutput{
+				Item: map[string]types.AttributeValue{
+					"id": &types.AttributeValueMemberS{Value: "from-middleware"},
+				},
+			}, nil
+		}
+	}
+
+	db := dy.NewClient[entity](mocks.NewDynamoClient(t), dbConfig, dy.WithMiddleware(cached))
+	e, err := db.GetItem(context.Background(), dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "from-middleware", e.Id)
+}
+
+func TestWithMiddleware_InvalidInput(t *testing.T) {
+	replace := func(next dy.Handler) dy.Handler {
+		return func(ctx context.Context, call *dy.Call) (interface{}, error) {
+			call.Input = &dynamodb.ScanInput{}
+			return next(ctx, call)
+		}
+	}
+
+	db := dy.NewClient[entity](mocks.NewDynamoClient(t), dbConfig, dy.WithMiddleware(replace))
+	_, err := db.GetItem(context.Background(), dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,227 @@
+package dy
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// NamingStrategy names the dynamodb attribute of an entity field.
+//
+// The strategy is applied to the attributes written by Create and read by Find, GetItem and GetItems, the key names
+// of the config being the resulting attribute names. The Update attributes, the Criteria and the query partition key
+// can be named after the Go field names, which are resolved to their attribute names.
+// The created items keys are matched case-insensitively when no attribute has the exact key name (e.g. the "id" key
+// of an untagged Id field), the key attribute being written with the key name.
+type NamingStrategy func(field reflect.StructField) string
+
+// StructTagNaming names the attributes after their dynamodbav tag, the field name being used otherwise.
+func StructTagNaming(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("dynamodbav"), ","); name != "" {
+		return name
+	}
+
+	return field.Name
+}
+
+// JSONTagNaming names the attributes after their json tag, falling back to StructTagNaming.
+func JSONTagNaming(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+
+	return StructTagNaming(field)
+}
+
+// CamelCaseNaming names the untagged fields in camelCase (e.g. GroupID as groupID), the dynamodbav tags being kept.
+func CamelCaseNaming(field reflect.StructField) string {
+	return untagged(field, func(words []string) string {
+		words[0] = strings.ToLower(words[0])
+		return strings.Join(words, "")
+	})
+}
+
+// SnakeCaseNaming names the untagged fields in snake_case (e.g. GroupID as group_id), the dynamodbav tags being kept.
+func SnakeCaseNaming(field reflect.StructField) string {
+	return untagged(field, func(words []string) string {
+		return strings.ToLower(strings.Join(words, "_"))
+	})
+}
+
+// LowerCaseNaming names the untagged fields in lowercase (e.g. GroupID as groupid), the dynamodbav tags being kept.
+func LowerCaseNaming(field reflect.StructField) string {
+	return untagged(field, func(words []string) string {
+		return strings.ToLower(strings.Join(words, ""))
+	})
+}
+
+func untagged(field reflect.StructField, join func(words []string) string) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("dynamodbav"), ","); name != "" {
+		return name
+	}
+
+	return join(splitWords(field.Name))
+}
+
+// splitWords splits a Go name into its words, the acronyms being kept together (e.g. URLPath as URL and Path).
+func splitWords(name string) []string {
+	runes := []rune(name)
+
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		lowerToUpper := !unicode.IsUpper(prev) && unicode.IsUpper(cur)
+		acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	return append(words, string(runes[start:]))
+}
+
+// attributeNames maps the attributes marshaled by attributevalue to the attributes named by a strategy.
+type attributeNames struct {
+	// byField the attribute names by field name.
+	byField map[string]string
+	// toAttribute the names of the renamed attributes by marshaled name.
+	toAttribute map[string]string
+	// toMarshaled the marshaled names of the renamed attributes.
+	toMarshaled map[string]string
+}
+
+// newAttributeNames maps the fields of the entity type to their attribute names, the fields being marshaled after
+// their tagKey tag. The names are left unchanged for the entities that are not structs.
+func newAttributeNames[T Entity](strategy NamingStrategy, tagKey string) attributeNames {
+	names := attributeNames{
+		byField:     make(map[string]string),
+		toAttribute: make(map[string]string),
+		toMarshaled: make(map[string]string),
+	}
+
+	for _, field := range entityFields[T](tagKey) {
+		name := field.marshaled
+		if strategy != nil {
+			name = strategy(field.StructField)
+		}
+
+		names.byField[field.Name] = name
+		if name != field.marshaled {
+			names.toAttribute[field.marshaled] = name
+			names.toMarshaled[name] = field.marshaled
+		}
+	}
+
+	return names
+}
+
+// entityField a marshaled field of an entity.
+type entityField struct {
+	reflect.StructField
+	// index the index sequence of the field in the entity struct.
+	index []int
+	// marshaled the name of the attribute marshaled by attributevalue.
+	marshaled string
+}
+
+// entityFields returns the marshaled fields of the entity type, named after their tagKey tag (the dynamodbav tag
+// being used otherwise) as done by attributevalue.
+func entityFields[T Entity](tagKey string) []entityField {
+	t := indirect(reflect.TypeOf((*T)(nil)).Elem())
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return appendFields(nil, t, nil, tagKey)
+}
+
+// fieldTag returns the name and the options of the tagKey tag of a field, the dynamodbav tag being used otherwise.
+func fieldTag(field reflect.StructField, tagKey string) (name, opts string) {
+	tag, ok := field.Tag.Lookup(tagKey)
+	if !ok || tag == "" {
+		tag = field.Tag.Get("dynamodbav")
+	}
+
+	name, opts, _ = strings.Cut(tag, ",")
+	return name, opts
+}
+
+func appendFields(fields []entityField, t reflect.Type, index []int, tagKey string) []entityField {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, _ := fieldTag(field, tagKey)
+		if name == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+
+		// the embedded structs fields are flattened, as done by attributevalue
+		if field.Anonymous && name == "" && indirect(field.Type).Kind() == reflect.Struct {
+			fields = appendFields(fields, indirect(field.Type), fieldIndex, tagKey)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, entityField{StructField: field, index: fieldIndex, marshaled: name})
+	}
+
+	return fields
+}
+
+// attribute resolves a field name to its attribute name, the other names being returned unchanged.
+func (n attributeNames) attribute(name string) string {
+	if attribute, ok := n.byField[name]; ok {
+		return attribute
+	}
+	if attribute, ok := n.toAttribute[name]; ok {
+		return attribute
+	}
+
+	return name
+}
+
+// encode renames the marshaled attributes of an item.
+func (n attributeNames) encode(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if len(n.toMarshaled) == 0 {
+		return item
+	}
+
+	renamed := make(map[string]types.AttributeValue, len(item))
+	for name, value := range item {
+		if attribute, ok := n.toAttribute[name]; ok {
+			name = attribute
+		}
+		renamed[name] = value
+	}
+
+	return renamed
+}
+
+// decode renames the attributes of an item to their marshaled names, to be unmarshaled by attributevalue.
+func (n attributeNames) decode(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if len(n.toMarshaled) == 0 {
+		return item
+	}
+
+	renamed := make(map[string]types.AttributeValue, len(item))
+	for name, value := range item {
+		if marshaled, ok := n.toMarshaled[name]; ok {
+			name = marshaled
+		}
+		renamed[name] = value
+	}
+
+	return renamed
+}
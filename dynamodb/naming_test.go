@@ -0,0 +1,202 @@
+package dy_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ownership struct {
+	CreatedBy string
+}
+
+type account struct {
+	AccountID   string
+	DisplayName string `json:"name"`
+	URLPath     string `dynamodbav:"path"`
+	Ignored     string `dynamodbav:"-"`
+	ownership
+}
+
+func (a account) IsEmpty() bool {
+	return a.AccountID == ""
+}
+
+func TestNamingStrategies(t *testing.T) {
+	cases := []struct {
+		name     string
+		strategy dy.NamingStrategy
+		want     []string
+	}{
+		{"struct tags", dy.StructTagNaming, []string{"AccountID", "DisplayName", "path"}},
+		{"json tags", dy.JSONTagNaming, []string{"AccountID", "name", "path"}},
+		{"camelCase", dy.CamelCaseNaming, []string{"accountID", "displayName", "path"}},
+		{"snake_case", dy.SnakeCaseNaming, []string{"account_id", "display_name", "path"}},
+		{"lowercase", dy.LowerCaseNaming, []string{"accountid", "displayname", "path"}},
+	}
+
+	typ := reflect.TypeOf(account{})
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i, want := range tc.want {
+				assert.Equal(t, want, tc.strategy(typ.Field(i)))
+			}
+		})
+	}
+
+	assert.Equal(t, "url_path", dy.SnakeCaseNaming(reflect.StructField{Name: "URLPath"}))
+	assert.Equal(t, "id", dy.CamelCaseNaming(reflect.StructField{Name: "ID"}))
+	assert.Equal(t, "address2_line", dy.SnakeCaseNaming(reflect.StructField{Name: "Address2Line"}))
+}
+
+func TestDynamodb_NamingStrategy(t *testing.T) {
+	ctx := context.Background()
+	conf := dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName: "accounts",
+			PrimaryKey: dy.DBPrimaryKeyNames{
+				PartitionKey: dy.DynamoKeyMetadata{Name: "account_id", Type: dy.String},
+			},
+		},
+	}
+
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, conf))
+	db := dy.NewClient[account](client, conf, dy.WithNamingStrategy(dy.SnakeCaseNaming))
+
+	// the generated key is written with the item
+	key, err := db.Create(ctx, account{DisplayName: "Jane", URLPath: "/jane", ownership: ownership{CreatedBy: "admin"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, key.PartitionKey.Value)
+
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("accounts"),
+		Key:       map[string]types.AttributeValue{"account_id": &types.AttributeValueMemberS{Value: key.PartitionKey.Value.(string)}},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"account_id", "display_name", "path", "created_by"}, keysOf(out.Item))
+
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, account{
+		AccountID:   key.PartitionKey.Value.(string),
+		DisplayName: "Jane",
+		URLPath:     "/jane",
+		ownership:   ownership{CreatedBy: "admin"},
+	}, *item)
+
+	// the field names are resolved to their attribute names
+	require.NoError(t, db.Update(ctx, key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("DisplayName", "Janet")}))
+
+	page, err := db.Find(ctx, dy.Request{
+		Size:         10,
+		PartitionKey: &dy.DynamoAttribute{KeyName: "AccountID", Value: key.PartitionKey.Value},
+		Conditions:   []dy.Criteria{*dy.NewCriteria().And("DisplayName", "Janet", dy.EQUAL).And("path", "/jane", dy.EQUAL)},
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "Janet", page.Items[0].DisplayName)
+
+	items, _, err := db.GetItems(ctx, []dy.DynamoPrimaryKey{key})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "admin", items[0].CreatedBy)
+}
+
+func keysOf(item map[string]types.AttributeValue) []string {
+	keys := make([]string, 0, len(item))
+	for key := range item {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestDynamodb_CaseInsensitiveKeys(t *testing.T) {
+	ctx := context.Background()
+	conf := dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName: "entities",
+			PrimaryKey: dy.DBPrimaryKeyNames{
+				PartitionKey: dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+			},
+		},
+	}
+
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, conf))
+	db := dy.NewClient[entity](client, conf)
+
+	// the key of the untagged Id field is written as the configured key
+	key, err := db.Create(ctx, entity{Id: "e1", FirstName: "Jane"})
+	require.NoError(t, err)
+	assert.Equal(t, "e1", key.PartitionKey.Value)
+
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("entities"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "e1"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "e1"}, out.Item["id"])
+	assert.NotContains(t, out.Item, "Id")
+
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, entity{Id: "e1", FirstName: "Jane"}, *item)
+}
+
+func TestDynamodb_NamingStrategy_PrimaryKeys(t *testing.T) {
+	ctx := context.Background()
+	conf := dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName: "accounts",
+			PrimaryKey: dy.DBPrimaryKeyNames{
+				PartitionKey: dy.DynamoKeyMetadata{Name: "account_id", Type: dy.String},
+			},
+		},
+	}
+
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, conf))
+	db := dy.NewClient[account](client, conf,
+		dy.WithNamingStrategy(dy.SnakeCaseNaming), dy.WithCache(dy.NewLRUCache(10), time.Minute, time.Minute))
+
+	_, err := db.Create(ctx, account{AccountID: "a1", DisplayName: "Jane"})
+	require.NoError(t, err)
+
+	// the primary keys can be named after the Go field names
+	key := dy.DynamoPrimaryKey{PartitionKey: dy.NewDynamoStringAttrib("AccountID", "a1")}
+
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "Jane", item.DisplayName)
+
+	items, _, err := db.GetItems(ctx, []dy.DynamoPrimaryKey{key})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	require.NoError(t, db.Update(ctx, key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("DisplayName", "Janet")}))
+
+	// the cached item is invalidated by the update
+	item, err = db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "Janet", item.DisplayName)
+
+	require.NoError(t, db.Delete(ctx, key))
+
+	_, err = db.GetItem(ctx, key)
+	assert.ErrorIs(t, err, dy.ErrNotFound)
+
+	out, err := client.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("accounts")})
+	require.NoError(t, err)
+	assert.Empty(t, out.Items)
+}
@@ -0,0 +1,192 @@
+package dy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NumberType the Go type of the Number key values read from dynamodb, e.g. in the continuation and the unprocessed keys.
+type NumberType int
+
+// The Go types of the Number key values.
+const (
+	// NumberString the decimal string, e.g. "42".
+	NumberString NumberType = iota
+	// NumberInt64 an int64.
+	NumberInt64
+	// NumberUint64 an uint64.
+	NumberUint64
+	// NumberFloat64 a float64, the numbers losing digits as a float64 being rejected.
+	NumberFloat64
+	// NumberBigInt a *big.Int.
+	NumberBigInt
+	// NumberBigFloat a *big.Float, with a 128 bits precision.
+	NumberBigFloat
+	// NumberJSON a json.Number.
+	NumberJSON
+)
+
+var numberTypeNames = map[NumberType]string{
+	NumberString:   "string",
+	NumberInt64:    "int64",
+	NumberUint64:   "uint64",
+	NumberFloat64:  "float64",
+	NumberBigInt:   "big.Int",
+	NumberBigFloat: "big.Float",
+	NumberJSON:     "json.Number",
+}
+
+// String returns the Go type name.
+func (t NumberType) String() string {
+	if name, ok := numberTypeNames[t]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("NumberType(%d)", int(t))
+}
+
+// MarshalText encodes the Go type name.
+func (t NumberType) MarshalText() ([]byte, error) {
+	if name, ok := numberTypeNames[t]; ok {
+		return []byte(name), nil
+	}
+
+	return nil, fmt.Errorf("invalid number type %d", int(t))
+}
+
+// UnmarshalText decodes a Go type name (e.g. int64 or big.Int).
+func (t *NumberType) UnmarshalText(text []byte) error {
+	for numberType, name := range numberTypeNames {
+		if strings.EqualFold(name, string(text)) {
+			*t = numberType
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid number type %q", text)
+}
+
+// numberPattern the dynamodb number format.
+var numberPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// formatNumber returns the exact decimal representation of a number: a Go integer or float (or a named integer or
+// float type, e.g. time.Duration), a *big.Int, a *big.Float, a json.Number, a numeric string or a decimal type whose
+// String method returns a numeric string.
+func formatNumber(value interface{}) (string, error) {
+	var s string
+	switch v := value.(type) {
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return formatFloat(float64(v), 32)
+	case float64:
+		return formatFloat(v, 64)
+	case *big.Int:
+		if v == nil {
+			return "", fmt.Errorf("nil *big.Int cannot be casted to number")
+		}
+		return v.String(), nil
+	case *big.Float:
+		if v == nil || v.IsInf() {
+			return "", fmt.Errorf("%v cannot be casted to number", value)
+		}
+		return v.Text('g', -1), nil
+	case string:
+		s = v
+	case json.Number:
+		s = string(v)
+	default:
+		// the named numeric types (e.g. time.Duration) are formatted as their underlying number, not as their String
+		rv := reflect.ValueOf(value)
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(rv.Int(), 10), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return strconv.FormatUint(rv.Uint(), 10), nil
+		case reflect.Float32, reflect.Float64:
+			return formatFloat(rv.Float(), rv.Type().Bits())
+		}
+
+		stringer, ok := value.(fmt.Stringer)
+		if !ok {
+			return "", fmt.Errorf("%v cannot be casted to number", value)
+		}
+		s = stringer.String()
+	}
+
+	if !numberPattern.MatchString(s) {
+		return "", fmt.Errorf("%q is not a number", s)
+	}
+
+	return s, nil
+}
+
+func formatFloat(f float64, bitSize int) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("%v cannot be casted to number", f)
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, bitSize), nil
+}
+
+// parseNumber decodes a dynamodb number into the Go type.
+func parseNumber(s string, numberType NumberType) (interface{}, error) {
+	switch numberType {
+	case NumberString:
+		return s, nil
+	case NumberInt64:
+		return strconv.ParseInt(s, 10, 64)
+	case NumberUint64:
+		return strconv.ParseUint(s, 10, 64)
+	case NumberFloat64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		// the float must format back to the same number
+		want, ok := new(big.Rat).SetString(s)
+		got, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+		if !ok || got == nil || want.Cmp(got) != 0 {
+			return nil, fmt.Errorf("%s cannot be represented exactly as a float64", s)
+		}
+		return f, nil
+	case NumberBigInt:
+		i, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return nil, fmt.Errorf("%s is not an integer", s)
+		}
+		return i, nil
+	case NumberBigFloat:
+		f, _, err := big.ParseFloat(s, 10, 128, big.ToNearestEven)
+		return f, err
+	case NumberJSON:
+		return json.Number(s), nil
+	}
+
+	return nil, fmt.Errorf("invalid number type %d", int(numberType))
+}
@@ -0,0 +1,103 @@
+package dy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNumberType_Text(t *testing.T) {
+	for _, numberType := range []dy.NumberType{
+		dy.NumberString, dy.NumberInt64, dy.NumberUint64, dy.NumberFloat64, dy.NumberBigInt, dy.NumberBigFloat, dy.NumberJSON,
+	} {
+		text, err := numberType.MarshalText()
+		require.NoError(t, err)
+		assert.Equal(t, numberType.String(), string(text))
+
+		var decoded dy.NumberType
+		require.NoError(t, decoded.UnmarshalText(text))
+		assert.Equal(t, numberType, decoded)
+	}
+
+	var decoded dy.NumberType
+	require.NoError(t, decoded.UnmarshalText([]byte("BIG.INT")))
+	assert.Equal(t, dy.NumberBigInt, decoded)
+
+	assert.Error(t, decoded.UnmarshalText([]byte("decimal")))
+
+	_, err := dy.NumberType(42).MarshalText()
+	assert.Error(t, err)
+	assert.Equal(t, "NumberType(42)", dy.NumberType(42).String())
+}
+
+type (
+	points int
+	ratio  float32
+)
+
+type scoreboard struct {
+	ID    string  `dynamodbav:"id"`
+	Score float64 `dynamodbav:"score"`
+}
+
+func (s scoreboard) IsEmpty() bool {
+	return s.ID == ""
+}
+
+func TestDynamodb_NamedNumbers(t *testing.T) {
+	ctx := context.Background()
+	conf := dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName: "scoreboards",
+			PrimaryKey: dy.DBPrimaryKeyNames{
+				PartitionKey: dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+			},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "named int", value: points(5), want: "5"},
+		{name: "named float", value: ratio(0.1), want: "0.1"},
+		{name: "duration", value: time.Second, want: "1000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := memdb.New()
+			require.NoError(t, client.CreateTableFromConfig(ctx, conf))
+			db := dy.NewClient[scoreboard](client, conf)
+
+			// the named numbers are written as their underlying number
+			key := dy.DynamoPrimaryKey{PartitionKey: dy.NewDynamoStringAttrib("id", "s1")}
+			require.NoError(t, db.Update(ctx, key, []dy.DynamoAttribute{dy.NewDynamoNumericAttrib("score", tt.value)}))
+
+			out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+				TableName: aws.String("scoreboards"),
+				Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "s1"}},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, &types.AttributeValueMemberN{Value: tt.want}, out.Item["score"])
+
+			// and compared as numbers by the criteria
+			page, err := db.Find(ctx, dy.Request{
+				Size:       10,
+				Conditions: []dy.Criteria{*dy.NewCriteria().And("score", tt.value, dy.EQUAL)},
+			})
+			require.NoError(t, err)
+			assert.Len(t, page.Items, 1)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package dy
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option configures the dynamodb client wrapper created by NewClient.
+type Option func(*options)
+
+type options struct {
+	middlewares      []Middleware
+	cache            Cache
+	cacheTTL         time.Duration
+	cacheNegativeTTL time.Duration
+	tracerProvider   trace.TracerProvider
+	metrics          Metrics
+	consumedCapacity types.ReturnConsumedCapacity
+	retryPolicy      *RetryPolicy
+	rateLimiter      *RateLimiter
+	circuitBreaker   *CircuitBreaker
+	naming           NamingStrategy
+	tagKey           string
+	encoderOptions   []func(*attributevalue.EncoderOptions)
+	decoderOptions   []func(*attributevalue.DecoderOptions)
+	omitEmpty        bool
+	codecs           map[reflect.Type]valueCodec
+	timeEncoding     TimeEncoding
+	timeEncodings    map[string]TimeEncoding
+	now              func() time.Time
+}
+
+// chain returns the user middlewares followed by the built-in ones.
+func (o options) chain() []Middleware {
+	middlewares := append([]Middleware{}, o.middlewares...)
+
+	// an open circuit fails fast, without going through the retries
+	if o.circuitBreaker != nil {
+		middlewares = append(middlewares, o.circuitBreaker.middleware())
+	}
+
+	if o.retryPolicy != nil {
+		middlewares = append(middlewares, retryMiddleware(*o.retryPolicy))
+	}
+
+	// every attempt is rate limited
+	if o.rateLimiter != nil {
+		middlewares = append(middlewares, o.rateLimiter.middleware())
+	}
+
+	// the metrics are reported per attempt
+	if o.metrics != nil {
+		middlewares = append(middlewares, metricsMiddleware(o.metrics))
+	}
+
+	if o.consumedCapacity != "" {
+		middlewares = append(middlewares, consumedCapacityMiddleware(o.consumedCapacity))
+	}
+
+	return middlewares
+}
+
+// WithCache enables the read-through cache in front of GetItem and GetItems.
+//
+// Found items are cached for ttl, while ErrNotFound results are cached for negativeTTL.
+// A negativeTTL <= 0 disables the negative caching.
+func WithCache(cache Cache, ttl, negativeTTL time.Duration) Option {
+	return func(o *options) {
+		o.cache = cache
+		o.cacheTTL = ttl
+		o.cacheNegativeTTL = negativeTTL
+	}
+}
+
+// WithNamingStrategy names the attributes of the entity fields with strategy. Defaults to StructTagNaming.
+func WithNamingStrategy(strategy NamingStrategy) Option {
+	return func(o *options) {
+		o.naming = strategy
+	}
+}
@@ -3,15 +3,22 @@ package dy_test
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/big"
 	"testing"
 
 	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/chaos"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
 	"github.com/AhmedBenCharrada/awsgo/mocks"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 var dbConfig = dy.DBConfig{
@@ -853,3 +860,165 @@ func getLastEvaluatedKeysTestData() map[string]types.AttributeValue {
 		"groupID": &types.AttributeValueMemberN{Value: "1234"},
 	}
 }
+
+type blob struct {
+	Key  []byte `dynamodbav:"key"`
+	Hash []byte `dynamodbav:"hash"`
+	Name string `dynamodbav:"name"`
+}
+
+func (b blob) IsEmpty() bool {
+	return len(b.Key) == 0
+}
+
+func TestDynamodb_BinaryKeys(t *testing.T) {
+	ctx := context.Background()
+	conf := dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName: "blobs",
+			PrimaryKey: dy.DBPrimaryKeyNames{
+				PartitionKey: dy.DynamoKeyMetadata{Name: "key", Type: dy.Binary},
+				SortKey:      &dy.DynamoKeyMetadata{Name: "hash", Type: dy.Binary},
+			},
+		},
+	}
+
+	// the missing binary keys are generated
+	m := mocks.NewDynamoClient(t)
+	m.On("PutItem", mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{}, nil)
+	generated, err := dy.NewClient[blob](m, conf).Create(ctx, blob{Name: "generated"})
+	require.NoError(t, err)
+	assert.Len(t, generated.PartitionKey.Value, 16)
+	assert.Len(t, generated.SortKey.Value, 16)
+
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, conf))
+	db := dy.NewClient[blob](client, conf)
+
+	id := uuid.New()
+	key := dy.DynamoPrimaryKey{
+		PartitionKey: dy.NewDynamoBinaryAttrib("key", id[:]),
+		SortKey:      &dy.DynamoAttribute{KeyName: "hash", Type: dy.Binary, Value: id},
+	}
+	_, err = db.Create(ctx, blob{Key: id[:], Hash: id[:], Name: "uuid"})
+	require.NoError(t, err)
+
+	other := dy.DynamoPrimaryKey{
+		PartitionKey: dy.NewDynamoBinaryAttrib("key", []byte{1}),
+		SortKey:      &dy.DynamoAttribute{KeyName: "hash", Type: dy.Binary, Value: []byte{2}},
+	}
+	_, err = db.Create(ctx, blob{Key: []byte{1}, Hash: []byte{2}, Name: "other"})
+	require.NoError(t, err)
+
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "uuid", item.Name)
+
+	require.NoError(t, db.Update(ctx, key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("name", "updated")}))
+
+	items, unprocessed, err := db.GetItems(ctx, []dy.DynamoPrimaryKey{key, other})
+	require.NoError(t, err)
+	assert.Empty(t, unprocessed)
+	assert.Len(t, items, 2)
+
+	// the unprocessed binary keys are returned
+	chaotic := dy.NewClient[blob](chaos.New(client, []chaos.Rule{{Operation: dy.OperationBatchGetItem, Fault: chaos.UnprocessedKeys(1), Probability: 1}}), conf)
+	_, unprocessed, err = chaotic.GetItems(ctx, []dy.DynamoPrimaryKey{key})
+	require.NoError(t, err)
+	if assert.Len(t, unprocessed, 1) {
+		assert.Equal(t, id[:], unprocessed[0].PartitionKey.Value)
+		assert.Equal(t, dy.Binary, unprocessed[0].SortKey.Type)
+	}
+
+	// the binary keys are paginated
+	page, err := db.Find(ctx, dy.Request{Size: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.NotNil(t, page.LastEvaluatedKey)
+	assert.Equal(t, dy.Binary, page.LastEvaluatedKey.PartitionKey.Type)
+
+	next, err := db.Find(ctx, dy.Request{Size: 1, LastEvaluatedKey: page.LastEvaluatedKey})
+	require.NoError(t, err)
+	require.Len(t, next.Items, 1)
+	assert.NotEqual(t, page.Items[0].Name, next.Items[0].Name)
+
+	_, err = db.GetItem(ctx, dy.DynamoPrimaryKey{PartitionKey: dy.DynamoAttribute{KeyName: "key", Type: dy.Binary, Value: "not bytes"}})
+	assert.Error(t, err)
+}
+
+type measure struct {
+	Sensor  int64   `dynamodbav:"sensor"`
+	At      float64 `dynamodbav:"at"`
+	Reading string  `dynamodbav:"reading"`
+}
+
+func (m measure) IsEmpty() bool {
+	return m.Sensor == 0
+}
+
+func TestDynamodb_NumericKeys(t *testing.T) {
+	ctx := context.Background()
+	conf := dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName: "measures",
+			PrimaryKey: dy.DBPrimaryKeyNames{
+				PartitionKey: dy.DynamoKeyMetadata{Name: "sensor", Type: dy.Number, NumberType: dy.NumberInt64},
+				SortKey:      &dy.DynamoKeyMetadata{Name: "at", Type: dy.Number, NumberType: dy.NumberFloat64},
+			},
+		},
+	}
+
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, conf))
+	db := dy.NewClient[measure](client, conf)
+
+	for _, m := range []measure{{Sensor: 7, At: 0.1}, {Sensor: 7, At: 2.5}, {Sensor: 9, At: 1e21}} {
+		_, err := db.Create(ctx, m)
+		require.NoError(t, err)
+	}
+
+	key := dy.DynamoPrimaryKey{
+		PartitionKey: dy.NewDynamoNumericAttrib("sensor", int8(7)),
+		SortKey:      &dy.DynamoAttribute{KeyName: "at", Type: dy.Number, Value: big.NewFloat(0.1)},
+	}
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, measure{Sensor: 7, At: 0.1}, *item)
+
+	// the big numbers are stored exactly
+	reading, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	require.NoError(t, db.Update(ctx, key, []dy.DynamoAttribute{dy.NewDynamoNumericAttrib("reading", reading)}))
+
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("measures"),
+		Key: map[string]types.AttributeValue{
+			"sensor": &types.AttributeValueMemberN{Value: "7"},
+			"at":     &types.AttributeValueMemberN{Value: "0.1"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "123456789012345678901234567890"}, out.Item["reading"])
+
+	// the numeric partition keys are queried as numbers, and the continuation keys read as their number type
+	page, err := db.Find(ctx, dy.Request{Size: 1, PartitionKey: &dy.DynamoAttribute{KeyName: "sensor", Type: dy.Number, Value: "7"}})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.NotNil(t, page.LastEvaluatedKey)
+	assert.Equal(t, int64(7), page.LastEvaluatedKey.PartitionKey.Value)
+	assert.Equal(t, page.Items[0].At, page.LastEvaluatedKey.SortKey.Value)
+
+	// the unprocessed keys are read as their number type
+	chaotic := dy.NewClient[measure](chaos.New(client, []chaos.Rule{{Operation: dy.OperationBatchGetItem, Fault: chaos.UnprocessedKeys(1), Probability: 1}}), conf)
+	_, unprocessed, err := chaotic.GetItems(ctx, []dy.DynamoPrimaryKey{{
+		PartitionKey: dy.NewDynamoNumericAttrib("sensor", uint64(9)),
+		SortKey:      &dy.DynamoAttribute{KeyName: "at", Type: dy.Number, Value: 1e21},
+	}})
+	require.NoError(t, err)
+	if assert.Len(t, unprocessed, 1) {
+		assert.Equal(t, int64(9), unprocessed[0].PartitionKey.Value)
+		assert.Equal(t, 1e21, unprocessed[0].SortKey.Value)
+	}
+
+	_, err = db.GetItem(ctx, dy.DynamoPrimaryKey{PartitionKey: dy.NewDynamoNumericAttrib("sensor", math.NaN())})
+	assert.Error(t, err)
+}
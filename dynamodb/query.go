@@ -4,21 +4,35 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/AhmedBenCharrada/awsgo/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 type DB[T Entity] struct {
 	client DynamoClient
 	conf   DBConfig
+	cache  *itemCache
+	tracer trace.Tracer
+	names  attributeNames
+	codec  entityCodec
+	now    func() time.Time
+	// keys the generator of the keys without configured generator.
+	keys KeyGenerator
 }
 
+// maxBatchGetKeys the maximum number of keys of a BatchGetItem request.
+const maxBatchGetKeys = 25
+
 type findOutput struct {
 	Items            []map[string]types.AttributeValue
 	LastEvaluatedKey map[string]types.AttributeValue
+	ConsumedCapacity *types.ConsumedCapacity
 }
 
 type resp[T Entity] struct {
@@ -28,21 +42,35 @@ type resp[T Entity] struct {
 }
 
 // Find scans or queries items from dynamodb.
-func (d *DB[T]) Find(ctx context.Context, req Request) (Page[T], error) {
+func (d *DB[T]) Find(ctx context.Context, req Request) (_ Page[T], err error) {
 	if req.Size == 0 {
 		return Page[T]{}, nil
 	}
 
-	out, err := find(ctx, d.client, d.conf.TableInfo.TableName, req)
+	operation := OperationQuery
+	if req.PartitionKey == nil {
+		operation = OperationScan
+	}
+
+	ctx, span := d.startSpan(ctx, "Find", operation, req.Index)
+	defer func() {
+		err = d.opError("Find", aws.ToString(req.Index), nil, err)
+		endSpan(span, err)
+	}()
+
+	out, err := find(ctx, d.client, d.conf.TableInfo.TableName, d.resolve(req))
 	if err != nil {
 		return Page[T]{}, err
 	}
 
+	recordCount(span, len(out.Items))
+	recordConsumedCapacity(span, out.ConsumedCapacity)
+
 	// parse response and accumulate returned items
 	data := make([]T, 0, len(out.Items))
 	for _, item := range out.Items {
 		var entity T
-		if err := attributevalue.UnmarshalMap(item, &entity); err != nil {
+		if err := d.decode(item, &entity); err != nil {
 			return Page[T]{}, err
 		}
 
@@ -68,7 +96,15 @@ func (d *DB[T]) Find(ctx context.Context, req Request) (Page[T], error) {
 }
 
 // GetItem retrieves an item.
-func (d *DB[T]) GetItem(ctx context.Context, primaryKey DynamoPrimaryKey) (*T, error) {
+func (d *DB[T]) GetItem(ctx context.Context, primaryKey DynamoPrimaryKey) (_ *T, err error) {
+	ctx, span := d.startSpan(ctx, "GetItem", OperationGetItem, nil)
+	defer func() {
+		err = d.opError("GetItem", "", &primaryKey, err)
+		endSpan(span, err)
+	}()
+
+	primaryKey = d.primaryKey(primaryKey)
+
 	// prepare the partition and the sort keys
 	partKey, sortKey, err := preparePartSortKey(primaryKey)
 	if err != nil {
@@ -84,32 +120,63 @@ func (d *DB[T]) GetItem(ctx context.Context, primaryKey DynamoPrimaryKey) (*T, e
 		return nil, err
 	}
 
+	// serve the item from the cache if possible
+	key := cacheKey(d.conf.TableInfo.TableName, req.Key)
+	if entry, ok := d.cache.get(ctx, key); ok {
+		if entry.Item == nil {
+			return nil, ErrNotFound
+		}
+
+		return d.unmarshal(entry.Item)
+	}
+
+	version := d.cache.current()
 	res, err := d.client.GetItem(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	recordConsumedCapacity(span, res.ConsumedCapacity)
+
 	if len(res.Item) < 1 {
+		d.cache.setMissing(ctx, version, key)
 		return nil, ErrNotFound
 	}
 
+	d.cache.set(ctx, version, key, res.Item)
+
 	// unmarshal the found item
-	var entity T
-	err = attributevalue.UnmarshalMap(res.Item, &entity)
-	return &entity, err
+	return d.unmarshal(res.Item)
 }
 
 // GetItems retrieves items by their primary keys.
-func (d *DB[T]) GetItems(ctx context.Context, ids []DynamoPrimaryKey) ([]T, []DynamoPrimaryKey, error) {
-	partitions := utils.Partition(ids, 25)
+func (d *DB[T]) GetItems(ctx context.Context, ids []DynamoPrimaryKey) (_ []T, _ []DynamoPrimaryKey, err error) {
+	ctx, span := d.startSpan(ctx, "GetItems", OperationBatchGetItem, nil)
+	defer func() {
+		err = d.opError("GetItems", "", nil, err)
+		endSpan(span, err)
+	}()
+
+	ids = d.primaryKeys(ids)
+
+	// serve the cached items and only load the remaining ones
+	version := d.cache.current()
+	cached, missing := d.lookup(ctx, ids)
+	cachedItems, err := d.parse(cached)
+	if err != nil {
+		return nil, ids, err
+	}
+
+	partitions := utils.Partition(missing, maxBatchGetKeys)
 
-	ch := make(chan resp[T])
+	// buffered so that the loads do not block once an error is returned
+	ch := make(chan resp[T], (len(missing)+maxBatchGetKeys-1)/maxBatchGetKeys)
 
 	wg := &sync.WaitGroup{}
 
 	for part := range partitions {
 		wg.Add(1)
-		go d.load(ctx, wg, ch, part...)
+		go d.load(ctx, version, wg, ch, part...)
 	}
 
 	go func(wg *sync.WaitGroup, ch chan resp[T]) {
@@ -118,6 +185,7 @@ func (d *DB[T]) GetItems(ctx context.Context, ids []DynamoPrimaryKey) ([]T, []Dy
 	}(wg, ch)
 
 	res := make([]T, 0, len(ids))
+	res = append(res, cachedItems...)
 	unprocessedKeys := make([]DynamoPrimaryKey, 0, len(ids))
 
 	for out := range ch {
@@ -129,6 +197,7 @@ func (d *DB[T]) GetItems(ctx context.Context, ids []DynamoPrimaryKey) ([]T, []Dy
 		unprocessedKeys = append(unprocessedKeys, out.unprocessedKeys...)
 	}
 
+	recordCount(span, len(res))
 	return res, unprocessedKeys, nil
 }
 
@@ -152,6 +221,7 @@ func find(ctx context.Context, client DynamoClient, table string, req Request) (
 		return &findOutput{
 			Items:            out.Items,
 			LastEvaluatedKey: out.LastEvaluatedKey,
+			ConsumedCapacity: out.ConsumedCapacity,
 		}, err
 	}
 
@@ -168,12 +238,20 @@ func find(ctx context.Context, client DynamoClient, table string, req Request) (
 	return &findOutput{
 		Items:            out.Items,
 		LastEvaluatedKey: out.LastEvaluatedKey,
+		ConsumedCapacity: out.ConsumedCapacity,
 	}, err
 }
 
-func (d *DB[T]) load(ctx context.Context, wg *sync.WaitGroup, ch chan<- resp[T], ids ...DynamoPrimaryKey) {
+func (d *DB[T]) load(ctx context.Context, version uint64, wg *sync.WaitGroup, ch chan<- resp[T], ids ...DynamoPrimaryKey) {
 	defer wg.Done()
 
+	// trace the batch as a child span of the GetItems span
+	ctx, span := d.startSpan(ctx, "GetItems.batch", OperationBatchGetItem, nil)
+	span.SetAttributes(attribute.Int("awsgo.dynamodb.batch_size", len(ids)))
+
+	var err error
+	defer func() { endSpan(span, err) }()
+
 	// build the batch get item query
 	query, err := NewExpressionBuilder(d.conf.TableInfo.TableName).BuildBatchGetItemInput(ids...)
 	if err != nil {
@@ -191,8 +269,16 @@ func (d *DB[T]) load(ctx context.Context, wg *sync.WaitGroup, ch chan<- resp[T],
 		return
 	}
 
+	recordCount(span, len(out.Responses[d.conf.TableInfo.TableName]))
+	capacities := make([]*types.ConsumedCapacity, 0, len(out.ConsumedCapacity))
+	for i := range out.ConsumedCapacity {
+		capacities = append(capacities, &out.ConsumedCapacity[i])
+	}
+	recordConsumedCapacity(span, capacities...)
+
 	// parse response and accumulate returned items
-	data, err := d.parse(out.Responses[d.conf.TableInfo.TableName])
+	items := out.Responses[d.conf.TableInfo.TableName]
+	data, err := d.parse(items)
 	if err != nil {
 		ch <- resp[T]{
 			err: err,
@@ -204,35 +290,113 @@ func (d *DB[T]) load(ctx context.Context, wg *sync.WaitGroup, ch chan<- resp[T],
 		data: data,
 	}
 
-	if out.UnprocessedKeys == nil {
-		ch <- res
-		return
+	// Todo: check whether it is better to use d.conf.TableInfo.PrimaryKey
+	partKeyMeta := d.keyMetadata(&ids[0].PartitionKey)
+	sortKeyMeta := d.keyMetadata(ids[0].SortKey)
+
+	var unprocessed []map[string]types.AttributeValue
+	if out.UnprocessedKeys != nil {
+		unprocessed = out.UnprocessedKeys[d.conf.TableInfo.TableName].Keys
 	}
 
-	if len(out.UnprocessedKeys[d.conf.TableInfo.TableName].Keys) == 0 {
+	d.populate(ctx, version, query.RequestItems[d.conf.TableInfo.TableName].Keys, items, unprocessed, *partKeyMeta, sortKeyMeta)
+
+	if len(unprocessed) == 0 {
 		ch <- res
 		return
 	}
 
-	// Todo: check whether it is better to use d.conf.TableInfo.PrimaryKey
-	partKeyMeta := extractMetadata(&ids[0].PartitionKey)
-	sortKeyMeta := extractMetadata(ids[0].SortKey)
-	res.unprocessedKeys, res.err = extractUnprocessedKeys(out.UnprocessedKeys[d.conf.TableInfo.TableName].Keys, *partKeyMeta, sortKeyMeta)
+	res.unprocessedKeys, err = extractUnprocessedKeys(unprocessed, *partKeyMeta, sortKeyMeta)
+	res.err = err
 
 	ch <- res
 }
 
+// keyMetadata returns the metadata of a requested key, with the number type of the configured key.
+func (d *DB[T]) keyMetadata(attrib *DynamoAttribute) *DynamoKeyMetadata {
+	meta := extractMetadata(attrib)
+	if meta == nil {
+		return nil
+	}
+
+	primaryKey := d.conf.TableInfo.PrimaryKey
+	for _, key := range []*DynamoKeyMetadata{&primaryKey.PartitionKey, primaryKey.SortKey} {
+		if key != nil && key.Name == meta.Name {
+			meta.NumberType = key.NumberType
+		}
+	}
+
+	return meta
+}
+
+// lookup splits the ids into the items found in the cache and the ids to be loaded from dynamodb.
+func (d *DB[T]) lookup(ctx context.Context, ids []DynamoPrimaryKey) ([]map[string]types.AttributeValue, []DynamoPrimaryKey) {
+	if d.cache == nil {
+		return nil, ids
+	}
+
+	found := make([]map[string]types.AttributeValue, 0, len(ids))
+	missing := make([]DynamoPrimaryKey, 0, len(ids))
+	for _, id := range ids {
+		key, ok := d.cacheKeyOf(id)
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+
+		entry, ok := d.cache.get(ctx, key)
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+
+		if entry.Item != nil {
+			found = append(found, entry.Item)
+		}
+	}
+
+	return found, missing
+}
+
+// populate caches the items loaded at version and marks the requested but missing (and processed) keys as not found.
+func (d *DB[T]) populate(ctx context.Context, version uint64, requested, items, unprocessed []map[string]types.AttributeValue, partKey DynamoKeyMetadata, sortKey *DynamoKeyMetadata) {
+	if d.cache == nil {
+		return
+	}
+
+	table := d.conf.TableInfo.TableName
+	seen := make(map[string]bool, len(requested))
+	for _, item := range items {
+		if key, ok := itemCacheKey(table, item, partKey, sortKey); ok {
+			d.cache.set(ctx, version, key, item)
+			seen[key] = true
+		}
+	}
+
+	for _, keys := range unprocessed {
+		if key, ok := itemCacheKey(table, keys, partKey, sortKey); ok {
+			seen[key] = true
+		}
+	}
+
+	for _, keys := range requested {
+		if key := cacheKey(table, keys); !seen[key] {
+			d.cache.setMissing(ctx, version, key)
+		}
+	}
+}
+
 func (d *DB[T]) parse(items []map[string]types.AttributeValue) ([]T, error) {
 	// parse response and accumulate returned items
 	data := make([]T, 0, len(items))
 	for _, item := range items {
 		var entity T
-		if err := attributevalue.UnmarshalMap(item, &entity); err != nil {
+		if err := d.decode(item, &entity); err != nil {
 			return nil, err
 		}
 
 		if entity.IsEmpty() {
-			return nil, fmt.Errorf("failed to parse response")
+			return nil, fmt.Errorf("%w: empty entity", ErrUnmarshal)
 		}
 
 		data = append(data, entity)
@@ -241,6 +405,77 @@ func (d *DB[T]) parse(items []map[string]types.AttributeValue) ([]T, error) {
 	return data, nil
 }
 
+func (d *DB[T]) unmarshal(item map[string]types.AttributeValue) (*T, error) {
+	var entity T
+	if err := d.decode(item, &entity); err != nil {
+		return nil, err
+	}
+
+	return &entity, nil
+}
+
+// decode unmarshals an item into the entity, the attributes being renamed to their marshaled names.
+func (d *DB[T]) decode(item map[string]types.AttributeValue, entity *T) error {
+	return d.codec.decode(d.names.decode(item), entity)
+}
+
+// resolve resolves the attribute names of the query partition key, of the last evaluated key and of the conditions,
+// and the compared values.
+func (d *DB[T]) resolve(req Request) Request {
+	if req.PartitionKey != nil {
+		partitionKey := *req.PartitionKey
+		partitionKey.KeyName = DBKey(d.names.attribute(string(partitionKey.KeyName)))
+		req.PartitionKey = &partitionKey
+	}
+
+	if req.LastEvaluatedKey != nil {
+		lastEvaluatedKey := d.primaryKey(*req.LastEvaluatedKey)
+		req.LastEvaluatedKey = &lastEvaluatedKey
+	}
+
+	conditions := make([]Criteria, 0, len(req.Conditions))
+	for i := range req.Conditions {
+		conditions = append(conditions, *req.Conditions[i].withResolver(d.resolveCondition))
+	}
+	req.Conditions = conditions
+
+	return req
+}
+
+// primaryKey resolves the attribute names of the keys of a primary key.
+func (d *DB[T]) primaryKey(primaryKey DynamoPrimaryKey) DynamoPrimaryKey {
+	primaryKey.PartitionKey.KeyName = DBKey(d.names.attribute(string(primaryKey.PartitionKey.KeyName)))
+	if primaryKey.SortKey != nil {
+		sortKey := *primaryKey.SortKey
+		sortKey.KeyName = DBKey(d.names.attribute(string(sortKey.KeyName)))
+		primaryKey.SortKey = &sortKey
+	}
+
+	return primaryKey
+}
+
+// primaryKeys resolves the attribute names of the keys of primary keys.
+func (d *DB[T]) primaryKeys(primaryKeys []DynamoPrimaryKey) []DynamoPrimaryKey {
+	resolved := make([]DynamoPrimaryKey, 0, len(primaryKeys))
+	for _, primaryKey := range primaryKeys {
+		resolved = append(resolved, d.primaryKey(primaryKey))
+	}
+
+	return resolved
+}
+
+// resolveCondition resolves the attribute name of a condition, and encodes its value with the codec of the field.
+func (d *DB[T]) resolveCondition(name string, value interface{}) (string, interface{}, error) {
+	attribute := d.names.attribute(name)
+
+	av, ok, err := d.codec.fieldValue(attribute, value)
+	if err != nil || !ok {
+		return attribute, value, err
+	}
+
+	return attribute, av, nil
+}
+
 func mergeConditions(conditions []Criteria) *Criteria {
 	if len(conditions) == 0 {
 		return nil
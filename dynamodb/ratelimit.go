@@ -0,0 +1,364 @@
+package dy
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// RateLimiterConfig the capacity budget of a table.
+type RateLimiterConfig struct {
+	// ReadCapacity the read capacity units per second. A value <= 0 disables the read limiting.
+	ReadCapacity float64
+	// WriteCapacity the write capacity units per second. A value <= 0 disables the write limiting.
+	WriteCapacity float64
+	// Burst the number of seconds of unused capacity that can be accumulated. Defaults to 1.
+	Burst float64
+	// MinRatio the lowest fraction of the capacity the rate is adapted down to on throttling. Defaults to 0.1.
+	MinRatio float64
+	// IncreaseRatio the fraction of the capacity the rate is increased by after each successful call. Defaults to 0.05.
+	IncreaseRatio float64
+}
+
+// RateLimiter a client-side token-bucket limiter budgeted in read and write capacity units.
+//
+// The capacity units of a call are estimated upfront (from the item size or from the previous calls)
+// and reconciled with the consumed capacity returned by dynamodb.
+// The rate is halved on throttling and slowly recovers on success.
+//
+// A RateLimiter can be shared by several clients to share the budget of the same table.
+type RateLimiter struct {
+	mu       sync.Mutex
+	defaults RateLimiterConfig
+	configs  map[string]RateLimiterConfig
+	tables   map[string]*tableLimiter
+	now      func() time.Time
+}
+
+type tableLimiter struct {
+	read  *bucket
+	write *bucket
+	// the average read units consumed per Scan/Query call
+	avgRead map[string]float64
+}
+
+// NewRateLimiter creates a new rate limiter applying defaults to every table.
+func NewRateLimiter(defaults RateLimiterConfig) *RateLimiter {
+	return &RateLimiter{
+		defaults: defaults,
+		configs:  make(map[string]RateLimiterConfig),
+		tables:   make(map[string]*tableLimiter),
+		now:      time.Now,
+	}
+}
+
+// WithRateLimiter limits the DynamoClient calls with the provided (potentially shared) rate limiter.
+// The calls request the total consumed capacity unless a mode is already set, on a copy of their input.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(o *options) {
+		o.rateLimiter = limiter
+	}
+}
+
+// SetTableConfig overrides the default budget of a table.
+// It must be called before the table is used.
+func (l *RateLimiter) SetTableConfig(table string, conf RateLimiterConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.configs[table] = conf
+	delete(l.tables, table)
+}
+
+// Rate returns the current (adapted) read and write rates of a table, in capacity units per second.
+func (l *RateLimiter) Rate(table string) (read, write float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	t := l.table(table)
+	return t.read.rate, t.write.rate
+}
+
+// table returns the limiter of a table. l.mu must be held.
+func (l *RateLimiter) table(name string) *tableLimiter {
+	if t, ok := l.tables[name]; ok {
+		return t
+	}
+
+	conf, ok := l.configs[name]
+	if !ok {
+		conf = l.defaults
+	}
+
+	now := l.now()
+	t := &tableLimiter{
+		read:    newBucket(conf, conf.ReadCapacity, now),
+		write:   newBucket(conf, conf.WriteCapacity, now),
+		avgRead: make(map[string]float64),
+	}
+	l.tables[name] = t
+	return t
+}
+
+// reserve takes n units from the bucket and returns the delay to wait before the call.
+func (l *RateLimiter) reserve(b *bucket, n float64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return b.reserve(n, l.now())
+}
+
+func (l *RateLimiter) middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (interface{}, error) {
+			requestConsumedCapacity(call)
+
+			l.mu.Lock()
+			t := l.table(call.Table)
+			b, units := t.write, estimateWriteUnits(call)
+			if !isWriteOperation(call.Operation) {
+				b, units = t.read, t.estimateReadUnits(call)
+			}
+			l.mu.Unlock()
+
+			if b.unlimited() {
+				return next(ctx, call)
+			}
+
+			if err := sleep(ctx, l.reserve(b, units)); err != nil {
+				l.mu.Lock()
+				b.refund(units)
+				l.mu.Unlock()
+				return nil, err
+			}
+
+			out, err := next(ctx, call)
+
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			switch {
+			case err == nil:
+				b.increase()
+				if consumed, ok := consumedUnits(call, out); ok {
+					b.refund(units - consumed)
+					t.observe(call, consumed)
+				}
+			case ClassifyError(err) == ErrorClassThrottling:
+				// throttled calls do not consume capacity
+				b.refund(units)
+				b.decrease()
+			}
+
+			return out, err
+		}
+	}
+}
+
+// estimateReadUnits estimates the read capacity units of a read call. l.mu must be held.
+func (t *tableLimiter) estimateReadUnits(call *Call) float64 {
+	switch in := call.Input.(type) {
+	case *dynamodb.GetItemInput:
+		return readUnitsPerItem(in.ConsistentRead)
+	case *dynamodb.BatchGetItemInput:
+		var units float64
+		for _, keys := range in.RequestItems {
+			units += float64(len(keys.Keys)) * readUnitsPerItem(keys.ConsistentRead)
+		}
+		return units
+	}
+
+	if avg, ok := t.avgRead[call.Operation+"/"+call.Index]; ok {
+		return avg
+	}
+
+	return 1
+}
+
+// observe tracks the average units consumed by the Scan and Query calls. l.mu must be held.
+func (t *tableLimiter) observe(call *Call, consumed float64) {
+	if call.Operation != OperationScan && call.Operation != OperationQuery {
+		return
+	}
+
+	key := call.Operation + "/" + call.Index
+	if avg, ok := t.avgRead[key]; ok {
+		t.avgRead[key] = 0.8*avg + 0.2*consumed
+		return
+	}
+
+	t.avgRead[key] = consumed
+}
+
+func readUnitsPerItem(consistentRead *bool) float64 {
+	if aws.ToBool(consistentRead) {
+		return 1
+	}
+
+	return 0.5
+}
+
+// estimateWriteUnits estimates the write capacity units of a write call (1 WCU per started KB).
+func estimateWriteUnits(call *Call) float64 {
+	if in, ok := call.Input.(*dynamodb.PutItemInput); ok {
+		return math.Max(1, math.Ceil(float64(itemSize(in.Item))/1024))
+	}
+
+	return 1
+}
+
+// consumedUnits returns the units consumed by a call, if reported by dynamodb.
+func consumedUnits(call *Call, out interface{}) (float64, bool) {
+	capacities := consumedCapacityOf(out)
+	if len(capacities) == 0 {
+		return 0, false
+	}
+
+	write := isWriteOperation(call.Operation)
+
+	var units float64
+	for _, c := range capacities {
+		read, written := splitCapacity(c.CapacityUnits, c.ReadCapacityUnits, c.WriteCapacityUnits, write)
+		if write {
+			units += written
+			continue
+		}
+		units += read
+	}
+
+	return units, true
+}
+
+// requestConsumedCapacity requests the total consumed capacity unless already requested.
+func requestConsumedCapacity(call *Call) {
+	call.Input = withConsumedCapacity(call.Input, types.ReturnConsumedCapacityTotal, false)
+}
+
+// bucket a token bucket whose rate adapts to throttling. Tokens can go negative (debt).
+type bucket struct {
+	capacity      float64
+	rate          float64
+	minRate       float64
+	increaseRatio float64
+	burst         float64
+	tokens        float64
+	last          time.Time
+}
+
+func newBucket(conf RateLimiterConfig, capacity float64, now time.Time) *bucket {
+	burst, minRatio, increaseRatio := conf.Burst, conf.MinRatio, conf.IncreaseRatio
+	if burst <= 0 {
+		burst = 1
+	}
+
+	if minRatio <= 0 {
+		minRatio = 0.1
+	}
+
+	if increaseRatio <= 0 {
+		increaseRatio = 0.05
+	}
+
+	return &bucket{
+		capacity:      capacity,
+		rate:          capacity,
+		minRate:       capacity * minRatio,
+		increaseRatio: increaseRatio,
+		burst:         burst,
+		tokens:        capacity * burst,
+		last:          now,
+	}
+}
+
+func (b *bucket) unlimited() bool {
+	return b.capacity <= 0
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens = math.Min(b.tokens+elapsed*b.rate, b.rate*b.burst)
+	b.last = now
+}
+
+// reserve takes n tokens and returns the time needed to pay back the debt, if any.
+func (b *bucket) reserve(n float64, now time.Time) time.Duration {
+	b.refill(now)
+	b.tokens -= n
+
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *bucket) refund(n float64) {
+	b.tokens += n
+}
+
+func (b *bucket) decrease() {
+	b.rate = math.Max(b.rate/2, b.minRate)
+}
+
+func (b *bucket) increase() {
+	b.rate = math.Min(b.rate+b.capacity*b.increaseRatio, b.capacity)
+}
+
+// itemSize approximates the size of an item as computed by dynamodb.
+func itemSize(item map[string]types.AttributeValue) int {
+	size := 0
+	for name, value := range item {
+		size += len(name) + attributeSize(value)
+	}
+
+	return size
+}
+
+func attributeSize(av types.AttributeValue) int {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return len(v.Value)/2 + 1
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberSS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		size := 0
+		for _, n := range v.Value {
+			size += len(n)/2 + 1
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		size := 0
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := 3
+		for _, e := range v.Value {
+			size += 1 + attributeSize(e)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		return 3 + itemSize(v.Value) + len(v.Value)
+	}
+
+	// BOOL and NULL
+	return 1
+}
@@ -0,0 +1,155 @@
+package dy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRateLimiter_AdaptsToThrottling(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("GetItem", mock.Anything, mock.Anything).Return(nil, &types.ProvisionedThroughputExceededException{}).Twice()
+	m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{
+		Item: getItemAttributeValuesTestData()[0],
+	}, nil).Once()
+
+	limiter := dy.NewRateLimiter(dy.RateLimiterConfig{ReadCapacity: 100, WriteCapacity: 10})
+
+	// both clients share the budget of the table
+	db1 := dy.NewClient[entity](m, dbConfig, dy.WithRateLimiter(limiter))
+	db2 := dy.NewClient[entity](m, dbConfig, dy.WithRateLimiter(limiter))
+
+	key := dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	}
+
+	_, err := db1.GetItem(context.Background(), key)
+	assert.Error(t, err)
+	read, write := limiter.Rate(dbConfig.TableInfo.TableName)
+	assert.Equal(t, 50.0, read)
+	assert.Equal(t, 10.0, write)
+
+	_, err = db2.GetItem(context.Background(), key)
+	assert.Error(t, err)
+	read, _ = limiter.Rate(dbConfig.TableInfo.TableName)
+	assert.Equal(t, 25.0, read)
+
+	_, err = db1.GetItem(context.Background(), key)
+	assert.NoError(t, err)
+	read, _ = limiter.Rate(dbConfig.TableInfo.TableName)
+	assert.Equal(t, 30.0, read)
+}
+
+func TestRateLimiter_MinRate(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("DeleteItem", mock.Anything, mock.Anything).Return(nil, &types.ProvisionedThroughputExceededException{}).Times(3)
+
+	limiter := dy.NewRateLimiter(dy.RateLimiterConfig{})
+	limiter.SetTableConfig(dbConfig.TableInfo.TableName, dy.RateLimiterConfig{WriteCapacity: 100, MinRatio: 0.3})
+
+	db := dy.NewClient[entity](m, dbConfig, dy.WithRateLimiter(limiter))
+	for i := 0; i < 3; i++ {
+		assert.Error(t, db.Delete(context.Background(), dy.DynamoPrimaryKey{
+			PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+		}))
+	}
+
+	_, write := limiter.Rate(dbConfig.TableInfo.TableName)
+	assert.Equal(t, 30.0, write)
+}
+
+func TestRateLimiter_Waits(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("GetItem", mock.Anything, mock.MatchedBy(func(in *dynamodb.GetItemInput) bool {
+		return in.ReturnConsumedCapacity == types.ReturnConsumedCapacityTotal
+	})).Return(&dynamodb.GetItemOutput{
+		Item: getItemAttributeValuesTestData()[0],
+	}, nil).Times(5)
+
+	// 1 RCU available upfront, then 100 RCU per second
+	limiter := dy.NewRateLimiter(dy.RateLimiterConfig{ReadCapacity: 100, Burst: 0.01})
+	db := dy.NewClient[entity](m, dbConfig, dy.WithRateLimiter(limiter))
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := db.GetItem(context.Background(), dy.DynamoPrimaryKey{
+			PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+		})
+		assert.NoError(t, err)
+	}
+
+	// 5 eventually consistent reads cost 2.5 RCU
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+}
+
+func TestRateLimiter_UsesConsumedCapacity(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("PutItem", mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{
+		ConsumedCapacity: &types.ConsumedCapacity{
+			TableName:     aws.String(dbConfig.TableInfo.TableName),
+			CapacityUnits: aws.Float64(2),
+		},
+	}, nil).Once()
+
+	limiter := dy.NewRateLimiter(dy.RateLimiterConfig{WriteCapacity: 1})
+	db := dy.NewClient[entity](m, dbConfig, dy.WithRateLimiter(limiter))
+
+	_, err := db.Create(context.Background(), entity{Id: "123", GroupID: aws.Int(1234)})
+	assert.NoError(t, err)
+
+	// the next write must wait for the 1 WCU debt to be paid back
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = db.Create(ctx, entity{Id: "124", GroupID: aws.Int(1234)})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("Scan", mock.Anything, mock.Anything).Return(&dynamodb.ScanOutput{}, nil).Times(10)
+
+	limiter := dy.NewRateLimiter(dy.RateLimiterConfig{WriteCapacity: 1})
+	db := dy.NewClient[entity](m, dbConfig, dy.WithRateLimiter(limiter))
+
+	for i := 0; i < 10; i++ {
+		_, err := db.Find(context.Background(), dy.Request{Size: 10})
+		assert.NoError(t, err)
+	}
+}
+
+func TestRateLimiter_KeepsInput(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("GetItem", mock.Anything, mock.MatchedBy(func(in *dynamodb.GetItemInput) bool {
+		return in.ReturnConsumedCapacity == types.ReturnConsumedCapacityTotal
+	})).Return(&dynamodb.GetItemOutput{
+		Item: getItemAttributeValuesTestData()[0],
+	}, nil).Once()
+
+	// the input seen by the outer middlewares is not modified
+	var input *dynamodb.GetItemInput
+	observe := func(next dy.Handler) dy.Handler {
+		return func(ctx context.Context, call *dy.Call) (interface{}, error) {
+			input = call.Input.(*dynamodb.GetItemInput)
+			return next(ctx, call)
+		}
+	}
+
+	limiter := dy.NewRateLimiter(dy.RateLimiterConfig{ReadCapacity: 100})
+	db := dy.NewClient[entity](m, dbConfig, dy.WithMiddleware(observe), dy.WithRateLimiter(limiter))
+
+	_, err := db.GetItem(context.Background(), dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, input.ReturnConsumedCapacity)
+}
@@ -0,0 +1,239 @@
+// Package replay records the DynamoClient calls to a cassette file and replays them, so tests can run
+// against captured real interactions without any dynamodb access.
+//
+// The requests and the responses are stored in the DynamoDB JSON format. On replay, the calls are matched on
+// the operation and the normalized input: the expression placeholders (e.g. #0 or :0) are substituted with
+// the attribute names and values they stand for, so inputs only differing by the placeholder naming match.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/ddbjson"
+	"github.com/AhmedBenCharrada/awsgo/internal/expr"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+var (
+	// ErrUnexpectedCall the call does not match any of the remaining recorded interactions.
+	ErrUnexpectedCall = errors.New("replay: unexpected call")
+	// ErrUnusedInteractions some recorded interactions were not replayed.
+	ErrUnusedInteractions = errors.New("replay: unused interactions")
+)
+
+// cassetteVersion the version of the cassette file format.
+const cassetteVersion = 1
+
+// Cassette the recorded interactions.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction a recorded call.
+type Interaction struct {
+	// Operation the DynamoClient operation name (e.g. dy.OperationQuery).
+	Operation string `json:"operation"`
+	// Request the operation input in the DynamoDB JSON format.
+	Request json.RawMessage `json:"request"`
+	// Response the operation output in the DynamoDB JSON format, empty if the call failed.
+	Response json.RawMessage `json:"response,omitempty"`
+	// Error the call error, nil if the call succeeded.
+	Error *Error `json:"error,omitempty"`
+}
+
+// Error a recorded call error.
+type Error struct {
+	// Code the API error code, empty if the error is not an API error (e.g. a context cancellation).
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+	Fault   string `json:"fault,omitempty"`
+	// Exception the members of the modeled exceptions (e.g. the Item of a ConditionalCheckFailedException).
+	Exception json.RawMessage `json:"exception,omitempty"`
+}
+
+// exceptions the modeled exceptions restored on replay, so errors.As behaves as with the real client.
+var exceptions = map[string]func() error{
+	"ConditionalCheckFailedException":          func() error { return &types.ConditionalCheckFailedException{} },
+	"InternalServerError":                      func() error { return &types.InternalServerError{} },
+	"ItemCollectionSizeLimitExceededException": func() error { return &types.ItemCollectionSizeLimitExceededException{} },
+	"LimitExceededException":                   func() error { return &types.LimitExceededException{} },
+	"ProvisionedThroughputExceededException":   func() error { return &types.ProvisionedThroughputExceededException{} },
+	"RequestLimitExceeded":                     func() error { return &types.RequestLimitExceeded{} },
+	"ResourceInUseException":                   func() error { return &types.ResourceInUseException{} },
+	"ResourceNotFoundException":                func() error { return &types.ResourceNotFoundException{} },
+	"TransactionCanceledException":             func() error { return &types.TransactionCanceledException{} },
+	"TransactionConflictException":             func() error { return &types.TransactionConflictException{} },
+}
+
+var faults = map[string]smithy.ErrorFault{
+	smithy.FaultClient.String(): smithy.FaultClient,
+	smithy.FaultServer.String(): smithy.FaultServer,
+}
+
+func newError(err error) *Error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return &Error{Message: err.Error()}
+	}
+
+	e := &Error{
+		Code:    apiErr.ErrorCode(),
+		Message: apiErr.ErrorMessage(),
+		Fault:   apiErr.ErrorFault().String(),
+	}
+
+	if _, ok := exceptions[e.Code]; ok {
+		if data, err := ddbjson.Marshal(apiErr); err == nil {
+			e.Exception = data
+		}
+	}
+
+	return e
+}
+
+// err restores the recorded error.
+func (e *Error) err() error {
+	if e.Code == "" {
+		return errors.New(e.Message)
+	}
+
+	if newException, ok := exceptions[e.Code]; ok && e.Exception != nil {
+		exception := newException()
+		if err := ddbjson.Unmarshal(e.Exception, exception); err == nil {
+			return exception
+		}
+	}
+
+	return &smithy.GenericAPIError{Code: e.Code, Message: e.Message, Fault: faults[e.Fault]}
+}
+
+// Load reads a cassette file.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("replay: invalid cassette %s: %w", path, err)
+	}
+
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("replay: unsupported cassette version %d", c.Version)
+	}
+
+	return &c, nil
+}
+
+// Save writes the cassette to a file.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// expressionFields the input members holding an expression.
+var expressionFields = []string{
+	"ConditionExpression",
+	"FilterExpression",
+	"KeyConditionExpression",
+	"ProjectionExpression",
+	"UpdateExpression",
+}
+
+// normalize returns the canonical form of an input in the DynamoDB JSON format:
+// the expression placeholders are substituted and the members are sorted.
+func normalize(input []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(input))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(normalizeDocument(doc))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func normalizeDocument(doc interface{}) interface{} {
+	switch doc := doc.(type) {
+	case []interface{}:
+		for i, elem := range doc {
+			doc[i] = normalizeDocument(elem)
+		}
+
+	case map[string]interface{}:
+		names, _ := doc["ExpressionAttributeNames"].(map[string]interface{})
+		values, _ := doc["ExpressionAttributeValues"].(map[string]interface{})
+
+		for name, value := range doc {
+			if s, ok := value.(string); ok && isExpressionField(name) {
+				doc[name] = normalizeExpression(s, names, values)
+			} else if name != "ExpressionAttributeValues" {
+				doc[name] = normalizeDocument(value)
+			}
+		}
+
+		delete(doc, "ExpressionAttributeNames")
+		delete(doc, "ExpressionAttributeValues")
+	}
+
+	return doc
+}
+
+func isExpressionField(name string) bool {
+	for _, field := range expressionFields {
+		if name == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeExpression substitutes the placeholders of an expression. The expression is kept as is if invalid.
+func normalizeExpression(s string, names, values map[string]interface{}) string {
+	tokens, err := expr.Lex(s)
+	if err != nil {
+		return s
+	}
+
+	parts := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		text := token.Text
+		switch token.Kind {
+		case expr.TokenEOF:
+			continue
+		case expr.TokenNameRef:
+			if name, ok := names[text].(string); ok {
+				text = name
+			}
+		case expr.TokenValueRef:
+			if value, ok := values[text]; ok {
+				if data, err := json.Marshal(value); err == nil {
+					text = ":" + string(data)
+				}
+			}
+		}
+		parts = append(parts, text)
+	}
+
+	return strings.Join(parts, " ")
+}
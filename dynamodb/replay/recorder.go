@@ -0,0 +1,101 @@
+package replay
+
+import (
+	"context"
+	"sync"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/internal/ddbjson"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Recorder a DynamoClient recording the calls to the wrapped client. It is safe for concurrent use.
+type Recorder struct {
+	client dy.DynamoClient
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+var _ dy.DynamoClient = (*Recorder)(nil)
+
+// NewRecorder creates a new recorder of the calls to the provided client.
+func NewRecorder(client dy.DynamoClient) *Recorder {
+	return &Recorder{client: client}
+}
+
+// Cassette returns the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Cassette{
+		Version:      cassetteVersion,
+		Interactions: append([]Interaction(nil), r.interactions...),
+	}
+}
+
+// Save writes the interactions recorded so far to a cassette file.
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+func record[In, Out any](ctx context.Context, r *Recorder, op string, in *In, optFns []func(*dynamodb.Options),
+	call func(context.Context, *In, ...func(*dynamodb.Options)) (*Out, error)) (*Out, error) {
+	// the input is encoded before the call, the wrapped client could alter it
+	request, err := ddbjson.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	out, callErr := call(ctx, in, optFns...)
+
+	interaction := Interaction{Operation: op, Request: request}
+	if callErr != nil {
+		interaction.Error = newError(callErr)
+	} else if interaction.Response, err = ddbjson.Marshal(out); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+
+	return out, callErr
+}
+
+// Scan records a Scan call.
+func (r *Recorder) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return record(ctx, r, dy.OperationScan, params, optFns, r.client.Scan)
+}
+
+// Query records a Query call.
+func (r *Recorder) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return record(ctx, r, dy.OperationQuery, params, optFns, r.client.Query)
+}
+
+// GetItem records a GetItem call.
+func (r *Recorder) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return record(ctx, r, dy.OperationGetItem, params, optFns, r.client.GetItem)
+}
+
+// BatchGetItem records a BatchGetItem call.
+func (r *Recorder) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	return record(ctx, r, dy.OperationBatchGetItem, params, optFns, r.client.BatchGetItem)
+}
+
+// PutItem records a PutItem call.
+func (r *Recorder) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return record(ctx, r, dy.OperationPutItem, params, optFns, r.client.PutItem)
+}
+
+// UpdateItem records an UpdateItem call.
+func (r *Recorder) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return record(ctx, r, dy.OperationUpdateItem, params, optFns, r.client.UpdateItem)
+}
+
+// DeleteItem records a DeleteItem call.
+func (r *Recorder) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	return record(ctx, r, dy.OperationDeleteItem, params, optFns, r.client.DeleteItem)
+}
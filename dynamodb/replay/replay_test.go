@@ -0,0 +1,191 @@
+package replay_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/replay"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	GroupID int    `dynamodbav:"groupID"`
+	ID      string `dynamodbav:"id"`
+	Name    string `dynamodbav:"name"`
+}
+
+func (u user) IsEmpty() bool {
+	return u.GroupID == 0 && u.ID == ""
+}
+
+var conf = dy.DBConfig{
+	TableInfo: dy.TableInfo{
+		TableName: "users",
+		PrimaryKey: dy.DBPrimaryKeyNames{
+			PartitionKey: dy.DynamoKeyMetadata{Name: "groupID", Type: dy.Number},
+			SortKey:      &dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+		},
+	},
+}
+
+func key(id string) dy.DynamoPrimaryKey {
+	sortKey := dy.NewDynamoStringAttrib("id", id)
+	return dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1"),
+		SortKey:      &sortKey,
+	}
+}
+
+// scenario the calls recorded then replayed.
+func scenario(t *testing.T, client dy.DynamoClient) []user {
+	ctx := context.Background()
+	db := dy.NewClient[user](client, conf)
+
+	for i := 0; i < 3; i++ {
+		_, err := db.Create(ctx, user{GroupID: 1, ID: fmt.Sprintf("u%d", i), Name: fmt.Sprintf("name%d", i)})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, db.Update(ctx, key("u1"), []dy.DynamoAttribute{dy.NewDynamoStringAttrib("name", "updated")}))
+
+	_, err := db.GetItem(ctx, key("missing"))
+	assert.ErrorIs(t, err, dy.ErrNotFound)
+
+	page, err := db.Find(ctx, dy.Request{Size: 10, Conditions: []dy.Criteria{*dy.NewCriteria().And("name", "name0", dy.EQUAL)}})
+	require.NoError(t, err)
+
+	items, _, err := db.GetItems(ctx, []dy.DynamoPrimaryKey{key("u1"), key("u2")})
+	require.NoError(t, err)
+
+	return append(page.Items, items...)
+}
+
+func TestRecordReplay(t *testing.T) {
+	backend := memdb.New()
+	require.NoError(t, backend.CreateTableFromConfig(context.Background(), conf))
+
+	recorder := replay.NewRecorder(backend)
+	recorded := scenario(t, recorder)
+	require.Len(t, recorded, 3)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, recorder.Save(path))
+
+	replayer, err := replay.LoadReplayer(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, recorded, scenario(t, replayer))
+	assert.NoError(t, replayer.Done())
+
+	// every interaction is replayed once
+	_, err = replayer.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String("users"),
+		Key: map[string]types.AttributeValue{
+			"groupID": &types.AttributeValueMemberN{Value: "1"},
+			"id":      &types.AttributeValueMemberS{Value: "missing"},
+		},
+	})
+	assert.ErrorIs(t, err, replay.ErrUnexpectedCall)
+}
+
+func TestReplay_Placeholders(t *testing.T) {
+	ctx := context.Background()
+	backend := memdb.New()
+	require.NoError(t, backend.CreateTableFromConfig(ctx, conf))
+
+	query := func(name, value string) *dynamodb.QueryInput {
+		return &dynamodb.QueryInput{
+			TableName:                 aws.String("users"),
+			KeyConditionExpression:    aws.String(fmt.Sprintf("%s = %s", name, value)),
+			ExpressionAttributeNames:  map[string]string{name: "groupID"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{value: &types.AttributeValueMemberN{Value: "1"}},
+		}
+	}
+
+	recorder := replay.NewRecorder(backend)
+	_, err := recorder.Query(ctx, query("#0", ":0"))
+	require.NoError(t, err)
+
+	replayer, err := replay.NewReplayer(recorder.Cassette())
+	require.NoError(t, err)
+
+	_, err = replayer.Query(ctx, query("#group", ":id"))
+	assert.NoError(t, err)
+
+	unexpected := query("#0", ":0")
+	unexpected.ExpressionAttributeValues[":0"] = &types.AttributeValueMemberN{Value: "2"}
+	_, err = replayer.Query(ctx, unexpected)
+	if assert.ErrorIs(t, err, replay.ErrUnexpectedCall) {
+		assert.Contains(t, err.Error(), `"KeyConditionExpression":"groupID = :{\"N\":\"2\"}"`)
+	}
+}
+
+func TestReplay_Errors(t *testing.T) {
+	ctx := context.Background()
+	backend := memdb.New()
+	require.NoError(t, backend.CreateTableFromConfig(ctx, conf))
+
+	item := map[string]types.AttributeValue{
+		"groupID": &types.AttributeValueMemberN{Value: "1"},
+		"id":      &types.AttributeValueMemberS{Value: "a"},
+	}
+	put := &dynamodb.PutItemInput{
+		TableName:                           aws.String("users"),
+		Item:                                item,
+		ConditionExpression:                 aws.String("attribute_not_exists(id)"),
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	}
+
+	recorder := replay.NewRecorder(backend)
+	_, err := recorder.PutItem(ctx, put)
+	require.NoError(t, err)
+	_, err = recorder.PutItem(ctx, put)
+	require.Error(t, err)
+	_, err = recorder.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("unknown")})
+	require.Error(t, err)
+	_, err = recorder.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("users"), FilterExpression: aws.String("a = ")})
+	require.Error(t, err)
+
+	replayer, err := replay.NewReplayer(recorder.Cassette())
+	require.NoError(t, err)
+
+	_, err = replayer.PutItem(ctx, put)
+	require.NoError(t, err)
+
+	_, err = replayer.PutItem(ctx, put)
+	var conditionErr *types.ConditionalCheckFailedException
+	if assert.True(t, errors.As(err, &conditionErr), "%v", err) {
+		assert.Equal(t, item, conditionErr.Item)
+	}
+
+	_, err = replayer.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("unknown")})
+	var notFound *types.ResourceNotFoundException
+	assert.True(t, errors.As(err, &notFound), "%v", err)
+
+	assert.ErrorIs(t, replayer.Done(), replay.ErrUnusedInteractions)
+
+	_, err = replayer.Scan(ctx, &dynamodb.ScanInput{TableName: aws.String("users"), FilterExpression: aws.String("a = ")})
+	assert.Error(t, err)
+	assert.NoError(t, replayer.Done())
+}
+
+func TestLoad(t *testing.T) {
+	_, err := replay.Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	require.NoError(t, (&replay.Cassette{Version: 2}).Save(path))
+
+	_, err = replay.Load(path)
+	assert.Error(t, err)
+}
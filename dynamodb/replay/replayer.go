@@ -0,0 +1,168 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/internal/ddbjson"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// Replayer a DynamoClient serving recorded interactions. It is safe for concurrent use.
+//
+// A call is served by the first unused interaction having the same operation and the same normalized input,
+// each interaction being replayed once. The calls matching no interaction fail with ErrUnexpectedCall.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	normalized   []string
+	used         []bool
+}
+
+var _ dy.DynamoClient = (*Replayer)(nil)
+
+// NewReplayer creates a new replayer of the cassette interactions.
+func NewReplayer(c *Cassette) (*Replayer, error) {
+	r := &Replayer{
+		interactions: c.Interactions,
+		normalized:   make([]string, len(c.Interactions)),
+		used:         make([]bool, len(c.Interactions)),
+	}
+
+	for i, interaction := range c.Interactions {
+		normalized, err := normalize(interaction.Request)
+		if err != nil {
+			return nil, fmt.Errorf("replay: invalid request of interaction %d: %w", i, err)
+		}
+		r.normalized[i] = normalized
+	}
+
+	return r, nil
+}
+
+// LoadReplayer creates a new replayer of the interactions of a cassette file.
+func LoadReplayer(path string) (*Replayer, error) {
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReplayer(c)
+}
+
+// Done returns an error listing the interactions which were not replayed, nil if all were.
+func (r *Replayer) Done() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unused []string
+	for i, used := range r.used {
+		if !used {
+			unused = append(unused, fmt.Sprintf("  #%d %s %s", i, r.interactions[i].Operation, r.normalized[i]))
+		}
+	}
+
+	if len(unused) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w:\n%s", ErrUnusedInteractions, strings.Join(unused, "\n"))
+}
+
+// next returns the first unused interaction matching the call and marks it used.
+func (r *Replayer) next(op string, in interface{}) (*Interaction, error) {
+	request, err := ddbjson.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized, err := normalize(request)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	candidate := -1
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Operation != op {
+			continue
+		}
+
+		if r.normalized[i] == normalized {
+			r.used[i] = true
+			return &r.interactions[i], nil
+		}
+
+		if candidate < 0 {
+			candidate = i
+		}
+	}
+
+	msg := fmt.Sprintf("%s %s", op, normalized)
+	if candidate >= 0 {
+		msg += fmt.Sprintf("\n  next recorded %s (#%d): %s", op, candidate, r.normalized[candidate])
+	} else {
+		msg += fmt.Sprintf("\n  no remaining recorded %s", op)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnexpectedCall, msg)
+}
+
+func replay[Out any](r *Replayer, op string, in interface{}) (*Out, error) {
+	interaction, err := r.next(op, in)
+	if err != nil {
+		return nil, err
+	}
+
+	if interaction.Error != nil {
+		return nil, interaction.Error.err()
+	}
+
+	out := new(Out)
+	if err := ddbjson.Unmarshal(interaction.Response, out); err != nil {
+		return nil, fmt.Errorf("replay: invalid response of %s: %w", op, err)
+	}
+
+	return out, nil
+}
+
+// Scan replays a Scan call.
+func (r *Replayer) Scan(_ context.Context, params *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return replay[dynamodb.ScanOutput](r, dy.OperationScan, params)
+}
+
+// Query replays a Query call.
+func (r *Replayer) Query(_ context.Context, params *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return replay[dynamodb.QueryOutput](r, dy.OperationQuery, params)
+}
+
+// GetItem replays a GetItem call.
+func (r *Replayer) GetItem(_ context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return replay[dynamodb.GetItemOutput](r, dy.OperationGetItem, params)
+}
+
+// BatchGetItem replays a BatchGetItem call.
+func (r *Replayer) BatchGetItem(_ context.Context, params *dynamodb.BatchGetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	return replay[dynamodb.BatchGetItemOutput](r, dy.OperationBatchGetItem, params)
+}
+
+// PutItem replays a PutItem call.
+func (r *Replayer) PutItem(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return replay[dynamodb.PutItemOutput](r, dy.OperationPutItem, params)
+}
+
+// UpdateItem replays an UpdateItem call.
+func (r *Replayer) UpdateItem(_ context.Context, params *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return replay[dynamodb.UpdateItemOutput](r, dy.OperationUpdateItem, params)
+}
+
+// DeleteItem replays a DeleteItem call.
+func (r *Replayer) DeleteItem(_ context.Context, params *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	return replay[dynamodb.DeleteItemOutput](r, dy.OperationDeleteItem, params)
+}
@@ -0,0 +1,245 @@
+package dy
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/expr"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go"
+)
+
+// ErrorClass classifies the errors returned by dynamodb to decide whether a call can be retried.
+type ErrorClass int
+
+const (
+	// ErrorClassPermanent errors that are never retried (validation, conditional check, ...).
+	ErrorClassPermanent ErrorClass = iota
+	// ErrorClassThrottling the request was rejected before being applied. It is always safe to retry.
+	ErrorClassThrottling
+	// ErrorClassTransient the request may have been applied (server errors, timeouts, ...).
+	// It is only retried for idempotent calls.
+	ErrorClassTransient
+)
+
+var throttlingErrorCodes = map[string]bool{
+	"ProvisionedThroughputExceededException": true,
+	"ThrottlingException":                    true,
+	"RequestLimitExceeded":                   true,
+}
+
+var transientErrorCodes = map[string]bool{
+	"InternalServerError": true,
+	"InternalFailure":     true,
+	"ServiceUnavailable":  true,
+}
+
+// ClassifyError returns the class of an error returned by a DynamoClient call.
+func ClassifyError(err error) ErrorClass {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassPermanent
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if throttlingErrorCodes[apiErr.ErrorCode()] {
+			return ErrorClassThrottling
+		}
+
+		if transientErrorCodes[apiErr.ErrorCode()] || apiErr.ErrorFault() == smithy.FaultServer {
+			return ErrorClassTransient
+		}
+	}
+
+	var statusErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &statusErr) && statusErr.HTTPStatusCode() >= 500 {
+		return ErrorClassTransient
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorClassTransient
+	}
+
+	return ErrorClassPermanent
+}
+
+// RetryPolicy the retry policy of the DynamoClient calls.
+//
+// Throttled calls are always retried, while transient failures are only retried for idempotent calls:
+// conditional writes and updates using ADD, arithmetic, list_append or removing list elements are never blindly
+// replayed.
+type RetryPolicy struct {
+	// MaxAttempts the maximum number of attempts per call, including the first one. Defaults to 3.
+	MaxAttempts int
+	// OperationMaxAttempts overrides MaxAttempts per operation (e.g. OperationBatchGetItem).
+	OperationMaxAttempts map[string]int
+	// BaseDelay the backoff base delay. Defaults to 25ms.
+	BaseDelay time.Duration
+	// MaxDelay the backoff maximum delay. Defaults to 2s.
+	MaxDelay time.Duration
+	// Classify classifies the returned errors. Defaults to ClassifyError.
+	Classify func(error) ErrorClass
+}
+
+// WithRetryPolicy retries the failed DynamoClient calls according to the provided policy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = &policy
+	}
+}
+
+func (p RetryPolicy) maxAttempts(operation string) int {
+	if n, ok := p.OperationMaxAttempts[operation]; ok {
+		return n
+	}
+
+	if p.MaxAttempts > 0 {
+		return p.MaxAttempts
+	}
+
+	return 3
+}
+
+// backoff returns the full-jitter exponential backoff delay of the provided (zero based) retry.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	base, maxDelay := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = 25 * time.Millisecond
+	}
+
+	if maxDelay <= 0 {
+		maxDelay = 2 * time.Second
+	}
+
+	delay := maxDelay
+	if retry < 32 && base<<retry > 0 && base<<retry < maxDelay {
+		delay = base << retry
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+func (p RetryPolicy) classify(err error) ErrorClass {
+	if p.Classify != nil {
+		return p.Classify(err)
+	}
+
+	return ClassifyError(err)
+}
+
+func retryMiddleware(policy RetryPolicy) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (interface{}, error) {
+			attempts := policy.maxAttempts(call.Operation)
+
+			for attempt := 1; ; attempt++ {
+				out, err := next(ctx, call)
+				if err == nil || attempt >= attempts {
+					return out, err
+				}
+
+				switch policy.classify(err) {
+				case ErrorClassThrottling:
+				case ErrorClassTransient:
+					if !isIdempotent(call) {
+						return out, err
+					}
+				default:
+					return out, err
+				}
+
+				if sleepErr := sleep(ctx, policy.backoff(attempt-1)); sleepErr != nil {
+					return out, err
+				}
+			}
+		}
+	}
+}
+
+// isIdempotent checks whether replaying a call that may have been applied leads to the same state.
+func isIdempotent(call *Call) bool {
+	switch in := call.Input.(type) {
+	case *dynamodb.PutItemInput:
+		return in.ConditionExpression == nil
+	case *dynamodb.DeleteItemInput:
+		return in.ConditionExpression == nil
+	case *dynamodb.UpdateItemInput:
+		return in.ConditionExpression == nil && isIdempotentUpdate(aws.ToString(in.UpdateExpression))
+	}
+
+	// reads are idempotent
+	return true
+}
+
+// isIdempotentUpdate checks whether an update expression can be applied twice: it has no ADD action, no arithmetic
+// nor list_append in its SET actions, and no REMOVE of a list element. The invalid expressions are not idempotent.
+func isIdempotentUpdate(expression string) bool {
+	update, err := expr.ParseUpdate(expression)
+	if err != nil {
+		return false
+	}
+
+	if len(update.Add) > 0 {
+		return false
+	}
+
+	for _, action := range update.Set {
+		if !isIdempotentOperand(action.Value) {
+			return false
+		}
+	}
+
+	// removing a list element shifts the following ones
+	for _, path := range update.Remove {
+		for _, element := range path {
+			if element.IsIndex {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func isIdempotentOperand(operand expr.Operand) bool {
+	switch o := operand.(type) {
+	case expr.ArithOperand:
+		return false
+	case expr.FuncOperand:
+		return isIdempotentFunc(o)
+	}
+
+	return true
+}
+
+func isIdempotentFunc(f expr.FuncOperand) bool {
+	if strings.EqualFold(f.Name, "list_append") {
+		return false
+	}
+
+	for _, arg := range f.Args {
+		if !isIdempotentOperand(arg) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
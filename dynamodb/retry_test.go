@@ -0,0 +1,234 @@
+package dy_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		name  string
+		err   error
+		class dy.ErrorClass
+	}{
+		{name: "nil", err: nil, class: dy.ErrorClassPermanent},
+		{name: "generic", err: fmt.Errorf("error"), class: dy.ErrorClassPermanent},
+		{name: "canceled", err: context.Canceled, class: dy.ErrorClassPermanent},
+		{name: "provisioned throughput", err: &types.ProvisionedThroughputExceededException{}, class: dy.ErrorClassThrottling},
+		{name: "request limit", err: &types.RequestLimitExceeded{}, class: dy.ErrorClassThrottling},
+		{name: "throttling", err: &smithy.GenericAPIError{Code: "ThrottlingException"}, class: dy.ErrorClassThrottling},
+		{name: "wrapped throttling", err: fmt.Errorf("wrapped: %w", &types.RequestLimitExceeded{}), class: dy.ErrorClassThrottling},
+		{name: "internal server error", err: &types.InternalServerError{}, class: dy.ErrorClassTransient},
+		{name: "server fault", err: &smithy.GenericAPIError{Code: "Unknown", Fault: smithy.FaultServer}, class: dy.ErrorClassTransient},
+		{name: "conditional check", err: &types.ConditionalCheckFailedException{}, class: dy.ErrorClassPermanent},
+		{name: "timeout", err: timeoutError{}, class: dy.ErrorClassTransient},
+		{
+			name: "http 503",
+			err: &awshttp.ResponseError{ResponseError: &smithyhttp.ResponseError{
+				Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}},
+				Err:      fmt.Errorf("unavailable"),
+			}},
+			class: dy.ErrorClassTransient,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.class, dy.ClassifyError(tc.err))
+		})
+	}
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	policy := dy.RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    2 * time.Millisecond,
+	}
+
+	key := dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	}
+
+	t.Run("retries throttled calls", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(nil, &types.ProvisionedThroughputExceededException{}).Twice()
+		m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{
+			Item: getItemAttributeValuesTestData()[0],
+		}, nil).Once()
+
+		db := dy.NewClient[entity](m, dbConfig, dy.WithRetryPolicy(policy))
+		_, err := db.GetItem(context.Background(), key)
+		assert.NoError(t, err)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(nil, &types.ProvisionedThroughputExceededException{}).Times(3)
+
+		db := dy.NewClient[entity](m, dbConfig, dy.WithRetryPolicy(policy))
+		_, err := db.GetItem(context.Background(), key)
+		assert.Error(t, err)
+	})
+
+	t.Run("per operation max attempts", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(nil, &types.ProvisionedThroughputExceededException{}).Once()
+
+		p := policy
+		p.OperationMaxAttempts = map[string]int{dy.OperationGetItem: 1}
+		db := dy.NewClient[entity](m, dbConfig, dy.WithRetryPolicy(p))
+		_, err := db.GetItem(context.Background(), key)
+		assert.Error(t, err)
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("DeleteItem", mock.Anything, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+
+		db := dy.NewClient[entity](m, dbConfig, dy.WithRetryPolicy(policy))
+		assert.Error(t, db.Delete(context.Background(), key))
+	})
+
+	t.Run("retries transient errors of idempotent updates", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("UpdateItem", mock.Anything, mock.Anything).Return(nil, &types.InternalServerError{}).Once()
+		m.On("UpdateItem", mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+		db := dy.NewClient[entity](m, dbConfig, dy.WithRetryPolicy(policy))
+		err := db.Update(context.Background(), key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("firstName", "name")})
+		assert.NoError(t, err)
+	})
+
+	t.Run("does not retry transient errors of non idempotent updates", func(t *testing.T) {
+		// turn the update into an ADD update before reaching the retry policy
+		add := func(next dy.Handler) dy.Handler {
+			return func(ctx context.Context, call *dy.Call) (interface{}, error) {
+				call.Input.(*dynamodb.UpdateItemInput).UpdateExpression = aws.String("ADD #0 :0\n")
+				return next(ctx, call)
+			}
+		}
+
+		m := mocks.NewDynamoClient(t)
+		m.On("UpdateItem", mock.Anything, mock.Anything).Return(nil, &types.InternalServerError{}).Once()
+
+		db := dy.NewClient[entity](m, dbConfig, dy.WithMiddleware(add), dy.WithRetryPolicy(policy))
+		err := db.Update(context.Background(), key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("firstName", "name")})
+		assert.Error(t, err)
+	})
+
+	t.Run("retries throttled non idempotent updates", func(t *testing.T) {
+		add := func(next dy.Handler) dy.Handler {
+			return func(ctx context.Context, call *dy.Call) (interface{}, error) {
+				call.Input.(*dynamodb.UpdateItemInput).UpdateExpression = aws.String("SET #0 = #0 + :0\n")
+				return next(ctx, call)
+			}
+		}
+
+		m := mocks.NewDynamoClient(t)
+		m.On("UpdateItem", mock.Anything, mock.Anything).Return(nil, &types.ProvisionedThroughputExceededException{}).Once()
+		m.On("UpdateItem", mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+
+		db := dy.NewClient[entity](m, dbConfig, dy.WithMiddleware(add), dy.WithRetryPolicy(policy))
+		err := db.Update(context.Background(), key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("firstName", "name")})
+		assert.NoError(t, err)
+	})
+
+	t.Run("with custom classification", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("flaky")).Once()
+		m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil).Once()
+
+		p := policy
+		p.Classify = func(error) dy.ErrorClass { return dy.ErrorClassTransient }
+		db := dy.NewClient[entity](m, dbConfig, dy.WithRetryPolicy(p))
+		_, err := db.GetItem(context.Background(), key)
+		assert.ErrorIs(t, err, dy.ErrNotFound)
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		m := mocks.NewDynamoClient(t)
+		m.On("GetItem", mock.Anything, mock.Anything).Return(nil, &types.ProvisionedThroughputExceededException{}).Once()
+
+		p := policy
+		p.BaseDelay, p.MaxDelay = time.Hour, time.Hour
+		db := dy.NewClient[entity](m, dbConfig, dy.WithRetryPolicy(p))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := db.GetItem(ctx, key)
+		assert.Error(t, err)
+	})
+}
+
+func TestWithRetryPolicy_UpdateIdempotency(t *testing.T) {
+	cases := []struct {
+		expression string
+		idempotent bool
+	}{
+		{"SET #0 = :0\n", true},
+		{"SET #0 = :0, #1 = :1\n", true},
+		{"SET #add = :add, #ADD_count = :1", true},
+		{"SET #0 = if_not_exists(#0, :0)", true},
+		{"SET #a.#b[2] = :v REMOVE #c, #d.#e", true},
+		{"DELETE #tags :removed", true},
+		{"SET #0 = #0 + :0", false},
+		{"SET a = a + :x", false},
+		{"SET #0 = #0-:0", false},
+		{"SET #0 = :0, #n = if_not_exists(#n, :zero) + :one", false},
+		{"SET #l = list_append(#l, :v)", false},
+		{"SET #0 = :0 ADD #c :one", false},
+		{"add #c :one", false},
+		{"REMOVE #l[0]", false},
+		{"SET #0 =", false},
+	}
+
+	sortKey := dy.NewDynamoStringAttrib("id", "abc")
+	key := dy.DynamoPrimaryKey{PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"), SortKey: &sortKey}
+	policy := dy.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.expression, func(t *testing.T) {
+			rewrite := func(next dy.Handler) dy.Handler {
+				return func(ctx context.Context, call *dy.Call) (interface{}, error) {
+					call.Input.(*dynamodb.UpdateItemInput).UpdateExpression = aws.String(tc.expression)
+					return next(ctx, call)
+				}
+			}
+
+			m := mocks.NewDynamoClient(t)
+			m.On("UpdateItem", mock.Anything, mock.Anything).Return(nil, &types.InternalServerError{}).Once()
+			if tc.idempotent {
+				m.On("UpdateItem", mock.Anything, mock.Anything).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+			}
+
+			db := dy.NewClient[entity](m, dbConfig, dy.WithMiddleware(rewrite), dy.WithRetryPolicy(policy))
+			err := db.Update(context.Background(), key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("firstName", "name")})
+			assert.Equal(t, tc.idempotent, err == nil)
+		})
+	}
+}
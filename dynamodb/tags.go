@@ -0,0 +1,246 @@
+package dy
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ConfigFor builds the config of the table storing the entity T from the dy struct tags of its fields:
+//
+//	type User struct {
+//		GroupID int    `dynamodbav:"groupID" dy:"pk"`
+//		ID      string `dynamodbav:"id" dy:"sk"`
+//		Email   string `dynamodbav:"email" dy:"gsi=byEmail,pk"`
+//		Age     int    `dynamodbav:"age" dy:"lsi=byAge"`
+//	}
+//
+// A tag holds the key roles of a field separated by ";" (e.g. `dy:"sk;gsi=byEmail,sk"`):
+//   - pk, sk: the table partition and sort keys.
+//   - gsi=<name>,pk and gsi=<name>,sk: the partition and sort keys of a global secondary index.
+//   - lsi=<name>: the sort key of a local secondary index.
+//
+// The key names are the attribute names of the fields, following the dynamodbav tags (the field name being used
+// otherwise) unless the client options opts (see WithTagKey and WithNamingStrategy) name the attributes differently.
+// The key types are inferred from the field types: strings, time.Time and the numbers tagged with the "string"
+// option are String keys, the other numbers Number keys read as int64, uint64 or float64 values, and the byte
+// slices and arrays (e.g. uuid.UUID) Binary keys. The time.Time fields encoded as epochs (see WithTimeEncoding) are
+// Number keys read as int64 values.
+// ErrInvalidConfig is returned if the tags do not describe a valid config.
+func ConfigFor[T Entity](tableName string, opts ...Option) (DBConfig, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	entity := reflect.TypeOf((*T)(nil)).Elem()
+
+	conf := DBConfig{TableInfo: TableInfo{TableName: tableName}}
+	b := &configBuilder{
+		conf:    &conf,
+		indexes: make(map[DBIndexName]*DBPrimaryKeyNames),
+		options: o,
+		tagKey:  o.entityTagKey(),
+		names:   newAttributeNames[T](o.naming, o.entityTagKey()),
+	}
+
+	if err := b.walk(indirect(entity)); err != nil {
+		return DBConfig{}, fmt.Errorf("%w: %v: %w", ErrInvalidConfig, entity, err)
+	}
+
+	if err := b.build(); err != nil {
+		return DBConfig{}, fmt.Errorf("%w: %v: %w", ErrInvalidConfig, entity, err)
+	}
+
+	if err := conf.Validate(); err != nil {
+		return DBConfig{}, fmt.Errorf("%v: %w", entity, err)
+	}
+
+	return conf, nil
+}
+
+type configBuilder struct {
+	conf         *DBConfig
+	partitionKey *DynamoKeyMetadata
+	indexes      map[DBIndexName]*DBPrimaryKeyNames
+	options      options
+	tagKey       string
+	names        attributeNames
+}
+
+func (b *configBuilder) walk(t reflect.Type) error {
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("the entity is not a struct")
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts := fieldTag(field, b.tagKey)
+		if name == "-" {
+			continue
+		}
+
+		// the embedded structs fields are flattened, as done by attributevalue
+		if field.Anonymous && name == "" && indirect(field.Type).Kind() == reflect.Struct {
+			if err := b.walk(indirect(field.Type)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("dy")
+		if !ok || !field.IsExported() {
+			continue
+		}
+
+		key, err := keyOf(field.Type, strings.Contains(","+opts+",", ",string,"))
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		key.Name = DBKey(b.names.attribute(field.Name))
+
+		// the times encoded as epochs are Number keys
+		marshaled := name
+		if marshaled == "" {
+			marshaled = field.Name
+		}
+		switch b.options.timeEncodingOf(entityField{StructField: field, marshaled: marshaled}, string(key.Name)) {
+		case TimeEpochSeconds, TimeEpochMillis:
+			key.Type, key.NumberType = Number, NumberInt64
+		}
+		for _, role := range strings.Split(tag, ";") {
+			if err := b.add(strings.TrimSpace(role), key); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// add assigns a key role to the key.
+func (b *configBuilder) add(role string, key DynamoKeyMetadata) error {
+	primaryKey := &b.conf.TableInfo.PrimaryKey
+	kind, index, _ := strings.Cut(role, "=")
+
+	switch {
+	case role == "pk":
+		if b.partitionKey != nil {
+			return fmt.Errorf("duplicate partition key")
+		}
+		b.partitionKey = &key
+
+	case role == "sk":
+		if primaryKey.SortKey != nil {
+			return fmt.Errorf("duplicate sort key")
+		}
+		primaryKey.SortKey = &key
+
+	case kind == "gsi":
+		name, part, _ := strings.Cut(index, ",")
+		if name == "" {
+			return fmt.Errorf("missing index name in %q", role)
+		}
+
+		keys, ok := b.indexes[DBIndexName(name)]
+		if !ok {
+			keys = &DBPrimaryKeyNames{}
+			b.indexes[DBIndexName(name)] = keys
+		}
+
+		switch part {
+		case "pk":
+			if keys.PartitionKey.Name != "" {
+				return fmt.Errorf("duplicate partition key of index %s", name)
+			}
+			keys.PartitionKey = key
+		case "sk":
+			if keys.SortKey != nil {
+				return fmt.Errorf("duplicate sort key of index %s", name)
+			}
+			keys.SortKey = &key
+		default:
+			return fmt.Errorf("invalid index key %q, expecting pk or sk", role)
+		}
+
+	case kind == "lsi":
+		if index == "" {
+			return fmt.Errorf("missing index name in %q", role)
+		}
+
+		if b.conf.LocalIndexes == nil {
+			b.conf.LocalIndexes = make(map[DBIndexName]DynamoKeyMetadata)
+		}
+		if _, ok := b.conf.LocalIndexes[DBIndexName(index)]; ok {
+			return fmt.Errorf("duplicate sort key of index %s", index)
+		}
+		b.conf.LocalIndexes[DBIndexName(index)] = key
+
+	default:
+		return fmt.Errorf("invalid key role %q", role)
+	}
+
+	return nil
+}
+
+func (b *configBuilder) build() error {
+	if b.partitionKey == nil {
+		return fmt.Errorf("missing partition key, tag a field with `dy:\"pk\"`")
+	}
+	b.conf.TableInfo.PrimaryKey.PartitionKey = *b.partitionKey
+
+	for name, keys := range b.indexes {
+		if b.conf.Indexes == nil {
+			b.conf.Indexes = make(map[DBIndexName]DBPrimaryKeyNames)
+		}
+		b.conf.Indexes[name] = *keys
+	}
+
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// keyOf infers the key type of a field type, and the number type of the Number keys.
+func keyOf(t reflect.Type, asString bool) (DynamoKeyMetadata, error) {
+	t = indirect(t)
+
+	var numberType NumberType
+	switch t.Kind() {
+	case reflect.String:
+		return DynamoKeyMetadata{Type: String}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		numberType = NumberInt64
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		numberType = NumberUint64
+	case reflect.Float32, reflect.Float64:
+		numberType = NumberFloat64
+	}
+
+	if numberType != NumberString {
+		if asString {
+			return DynamoKeyMetadata{Type: String}, nil
+		}
+		return DynamoKeyMetadata{Type: Number, NumberType: numberType}, nil
+	}
+
+	if t == timeType {
+		return DynamoKeyMetadata{Type: String}, nil
+	}
+
+	// the byte slices and arrays (e.g. uuid.UUID) are marshaled as binary values
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8 {
+		return DynamoKeyMetadata{Type: Binary}, nil
+	}
+
+	return DynamoKeyMetadata{}, fmt.Errorf("%w %v", ErrInvalidDBKeyType, t)
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,146 @@
+package dy_test
+
+import (
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type audit struct {
+	CreatedAt time.Time `dynamodbav:"createdAt" dy:"lsi=byCreation"`
+}
+
+type taggedUser struct {
+	audit
+	GroupID  int      `dynamodbav:"groupID" dy:"pk"`
+	ID       string   `dynamodbav:"id" dy:"sk;gsi=byEmail,sk"`
+	Email    *string  `dynamodbav:"email,omitempty" dy:"gsi=byEmail,pk"`
+	Zip      int      `dynamodbav:",string" dy:"gsi=byZip,pk"`
+	Token    [16]byte `dynamodbav:"token" dy:"gsi=byToken,pk"`
+	Name     string   `dynamodbav:"name"`
+	Internal string   `dynamodbav:"-" dy:"pk"`
+}
+
+func (u taggedUser) IsEmpty() bool {
+	return u.ID == ""
+}
+
+type noPartitionKey struct {
+	ID string `dy:"sk"`
+}
+
+func (noPartitionKey) IsEmpty() bool { return false }
+
+type boolKey struct {
+	Enabled bool `dy:"pk"`
+}
+
+func (boolKey) IsEmpty() bool { return false }
+
+type duplicateKey struct {
+	ID    string `dy:"pk"`
+	Email string `dy:"pk"`
+}
+
+func (duplicateKey) IsEmpty() bool { return false }
+
+type invalidRole struct {
+	ID string `dy:"pk;gsi=byID"`
+}
+
+func (invalidRole) IsEmpty() bool { return false }
+
+type localWithoutSortKey struct {
+	ID  string `dy:"pk"`
+	Age int    `dy:"lsi=byAge"`
+}
+
+func (localWithoutSortKey) IsEmpty() bool { return false }
+
+type missingIndexPartitionKey struct {
+	ID    string `dy:"pk"`
+	Email string `dy:"gsi=byEmail,sk"`
+}
+
+func (missingIndexPartitionKey) IsEmpty() bool { return false }
+
+func TestConfigFor(t *testing.T) {
+	conf, err := dy.ConfigFor[taggedUser]("users")
+	require.NoError(t, err)
+
+	assert.Equal(t, dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName: "users",
+			PrimaryKey: dy.DBPrimaryKeyNames{
+				PartitionKey: dy.DynamoKeyMetadata{Name: "groupID", Type: dy.Number, NumberType: dy.NumberInt64},
+				SortKey:      &dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+			},
+		},
+		Indexes: map[dy.DBIndexName]dy.DBPrimaryKeyNames{
+			"byEmail": {
+				PartitionKey: dy.DynamoKeyMetadata{Name: "email", Type: dy.String},
+				SortKey:      &dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+			},
+			"byZip":   {PartitionKey: dy.DynamoKeyMetadata{Name: "Zip", Type: dy.String}},
+			"byToken": {PartitionKey: dy.DynamoKeyMetadata{Name: "token", Type: dy.Binary}},
+		},
+		LocalIndexes: map[dy.DBIndexName]dy.DynamoKeyMetadata{
+			"byCreation": {Name: "createdAt", Type: dy.String},
+		},
+	}, conf)
+
+	// the pointer entities share the config of their struct
+	ptrConf, err := dy.ConfigFor[*taggedUser]("users")
+	require.NoError(t, err)
+	assert.Equal(t, conf, ptrConf)
+}
+
+func TestConfigFor_Invalid(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  func(string, ...dy.Option) (dy.DBConfig, error)
+		message string
+	}{
+		{"no partition key", dy.ConfigFor[noPartitionKey], "missing partition key"},
+		{"bool key", dy.ConfigFor[boolKey], "field Enabled: invalid key type bool"},
+		{"duplicate key", dy.ConfigFor[duplicateKey], "field Email: duplicate partition key"},
+		{"invalid role", dy.ConfigFor[invalidRole], `field ID: invalid index key "gsi=byID", expecting pk or sk`},
+		{"local index without sort key", dy.ConfigFor[localWithoutSortKey], "local index byAge: the table has no sort key"},
+		{"index without partition key", dy.ConfigFor[missingIndexPartitionKey], "global index byEmail: missing partition key name"},
+		{"not a struct", dy.ConfigFor[wrongEntity], "the entity is not a struct"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.config("users")
+			assert.ErrorIs(t, err, dy.ErrInvalidConfig)
+			assert.ErrorContains(t, err, tc.message)
+		})
+	}
+
+	_, err := dy.ConfigFor[boolKey]("users")
+	assert.ErrorIs(t, err, dy.ErrInvalidDBKeyType)
+}
+
+type jsonUser struct {
+	GroupID int    `json:"group" dy:"pk"`
+	UserID  string `dy:"sk"`
+}
+
+func (jsonUser) IsEmpty() bool { return false }
+
+func TestConfigFor_Options(t *testing.T) {
+	conf, err := dy.ConfigFor[jsonUser]("users", dy.WithTagKey("json"))
+	require.NoError(t, err)
+	assert.Equal(t, dy.DBKey("group"), conf.TableInfo.PrimaryKey.PartitionKey.Name)
+	assert.Equal(t, dy.DBKey("UserID"), conf.TableInfo.PrimaryKey.SortKey.Name)
+
+	conf, err = dy.ConfigFor[jsonUser]("users", dy.WithNamingStrategy(dy.SnakeCaseNaming))
+	require.NoError(t, err)
+	assert.Equal(t, dy.DBKey("group_id"), conf.TableInfo.PrimaryKey.PartitionKey.Name)
+	assert.Equal(t, dy.DBKey("user_id"), conf.TableInfo.PrimaryKey.SortKey.Name)
+}
@@ -0,0 +1,171 @@
+package dy
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TimeEncoding the encoding of the time.Time fields.
+type TimeEncoding int
+
+// The time encodings. The time.Time fields without encoding are marshaled by attributevalue, as RFC3339 strings
+// unless configured otherwise with WithEncoderOptions and WithDecoderOptions.
+const (
+	// TimeRFC3339 encodes the times as UTC RFC3339 strings with a fixed nanoseconds precision, ordered as the times.
+	// Any RFC3339 string is decoded.
+	TimeRFC3339 TimeEncoding = iota + 1
+	// TimeEpochSeconds encodes the times as numbers of seconds since the Unix epoch (e.g. for the TTL attributes).
+	TimeEpochSeconds
+	// TimeEpochMillis encodes the times as numbers of milliseconds since the Unix epoch.
+	TimeEpochMillis
+)
+
+var timeEncodingNames = map[TimeEncoding]string{
+	TimeRFC3339:      "RFC3339",
+	TimeEpochSeconds: "EpochSeconds",
+	TimeEpochMillis:  "EpochMillis",
+}
+
+// String returns the time encoding name.
+func (e TimeEncoding) String() string {
+	if name, ok := timeEncodingNames[e]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("TimeEncoding(%d)", int(e))
+}
+
+// MarshalText encodes the time encoding name.
+func (e TimeEncoding) MarshalText() ([]byte, error) {
+	if name, ok := timeEncodingNames[e]; ok {
+		return []byte(name), nil
+	}
+
+	return nil, fmt.Errorf("invalid time encoding %d", int(e))
+}
+
+// UnmarshalText decodes a time encoding name (e.g. RFC3339 or EpochSeconds).
+func (e *TimeEncoding) UnmarshalText(text []byte) error {
+	for encoding, name := range timeEncodingNames {
+		if strings.EqualFold(name, string(text)) {
+			*e = encoding
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid time encoding %q", text)
+}
+
+// rfc3339Fixed the RFC3339 layout with a fixed nanoseconds precision, whose UTC strings are ordered as the times.
+const rfc3339Fixed = "2006-01-02T15:04:05.000000000Z07:00"
+
+// WithTimeEncoding encodes the time.Time (or *time.Time) fields with encoding. The fields are given by their Go
+// field or attribute names, all the time fields being encoded when no field is given.
+//
+// The encoding applies to the written entities, the read items, the Update values and the Criteria values of the
+// fields. ConfigFor infers Number keys from the time fields encoded as epochs.
+func WithTimeEncoding(encoding TimeEncoding, fields ...string) Option {
+	return func(o *options) {
+		if len(fields) == 0 {
+			o.timeEncoding = encoding
+			return
+		}
+
+		if o.timeEncodings == nil {
+			o.timeEncodings = make(map[string]TimeEncoding)
+		}
+		for _, field := range fields {
+			o.timeEncodings[field] = encoding
+		}
+	}
+}
+
+// timeEncodingOf returns the encoding of a field, zero if the field is not an encoded time field.
+func (o options) timeEncodingOf(field entityField, attribute string) TimeEncoding {
+	if field.Type != timeType && field.Type != reflect.PointerTo(timeType) {
+		return 0
+	}
+
+	for _, name := range []string{field.Name, field.marshaled, attribute} {
+		if encoding, ok := o.timeEncodings[name]; ok {
+			return encoding
+		}
+	}
+
+	return o.timeEncoding
+}
+
+// timeCodec the codec of the times encoded with encoding.
+func timeCodec(encoding TimeEncoding) valueCodec {
+	return valueCodec{
+		encode: func(v reflect.Value) (types.AttributeValue, error) {
+			return encodeTime(v.Interface().(time.Time), encoding)
+		},
+		decode: func(av types.AttributeValue, v reflect.Value) error {
+			t, err := decodeTime(av, encoding)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(t))
+			return nil
+		},
+	}
+}
+
+func encodeTime(t time.Time, encoding TimeEncoding) (types.AttributeValue, error) {
+	switch encoding {
+	case TimeRFC3339:
+		return &types.AttributeValueMemberS{Value: t.UTC().Format(rfc3339Fixed)}, nil
+	case TimeEpochSeconds:
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}, nil
+	case TimeEpochMillis:
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.UnixMilli(), 10)}, nil
+	}
+
+	return nil, fmt.Errorf("invalid time encoding %v", encoding)
+}
+
+func decodeTime(av types.AttributeValue, encoding TimeEncoding) (time.Time, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		if encoding == TimeRFC3339 {
+			return time.Parse(time.RFC3339Nano, v.Value)
+		}
+	case *types.AttributeValueMemberN:
+		switch encoding {
+		case TimeEpochSeconds:
+			return parseEpoch(v.Value, time.Second)
+		case TimeEpochMillis:
+			return parseEpoch(v.Value, time.Millisecond)
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cannot decode %T as a %v time", av, encoding)
+}
+
+// parseEpoch parses a number of units since the Unix epoch, the fractional numbers being accepted.
+func parseEpoch(n string, unit time.Duration) (time.Time, error) {
+	if !strings.ContainsAny(n, ".eE") {
+		i, err := strconv.ParseInt(n, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if unit == time.Millisecond {
+			return time.UnixMilli(i).UTC(), nil
+		}
+		return time.Unix(i, 0).UTC(), nil
+	}
+
+	f, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, frac := math.Modf(f * unit.Seconds())
+	return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+}
@@ -0,0 +1,188 @@
+package dy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type session struct {
+	ID        string     `dynamodbav:"id"`
+	CreatedAt time.Time  `dynamodbav:"createdAt"`
+	ExpiresAt time.Time  `dynamodbav:"ttl"`
+	SeenAt    *time.Time `dynamodbav:"seenAt"`
+	StartedAt time.Time  `dynamodbav:"startedAt"`
+}
+
+func (s session) IsEmpty() bool {
+	return s.ID == ""
+}
+
+var sessionsConfig = dy.DBConfig{
+	TableInfo: dy.TableInfo{
+		TableName: "sessions",
+		PrimaryKey: dy.DBPrimaryKeyNames{
+			PartitionKey: dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+		},
+	},
+}
+
+var sessionOptions = []dy.Option{
+	dy.WithTimeEncoding(dy.TimeRFC3339, "CreatedAt"),
+	dy.WithTimeEncoding(dy.TimeEpochSeconds, "ttl"),
+	dy.WithTimeEncoding(dy.TimeEpochMillis, "SeenAt"),
+}
+
+func TestDynamodb_TimeEncoding(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, sessionsConfig))
+
+	db := dy.NewClient[session](client, sessionsConfig, sessionOptions...)
+
+	createdAt := time.Date(2024, 3, 1, 12, 30, 0, 5000, time.UTC)
+	seenAt := time.Date(2024, 3, 1, 13, 0, 0, int(250*time.Millisecond), time.UTC)
+	s := session{
+		ID:        "s1",
+		CreatedAt: createdAt,
+		ExpiresAt: time.Unix(1709300000, 0).UTC(),
+		SeenAt:    &seenAt,
+		StartedAt: createdAt,
+	}
+	key, err := db.Create(ctx, s)
+	require.NoError(t, err)
+
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("sessions"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "s1"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"id":        &types.AttributeValueMemberS{Value: "s1"},
+		"createdAt": &types.AttributeValueMemberS{Value: "2024-03-01T12:30:00.000005000Z"},
+		"ttl":       &types.AttributeValueMemberN{Value: "1709300000"},
+		"seenAt":    &types.AttributeValueMemberN{Value: "1709298000250"},
+		"startedAt": &types.AttributeValueMemberS{Value: "2024-03-01T12:30:00.000005Z"},
+	}, out.Item)
+
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, s, *item)
+
+	// the update values are encoded as their field
+	expiresAt := time.Unix(1709400000, 0).UTC()
+	require.NoError(t, db.Update(ctx, key, []dy.DynamoAttribute{
+		{KeyName: "ExpiresAt", Value: expiresAt},
+		{KeyName: "seenAt", Value: (*time.Time)(nil)},
+	}))
+
+	item, err = db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, expiresAt, item.ExpiresAt)
+	assert.Nil(t, item.SeenAt)
+
+	_, err = db.Create(ctx, session{ID: "s2", ExpiresAt: time.Unix(1709500000, 0).UTC()})
+	require.NoError(t, err)
+
+	// the criteria values are compared as encoded
+	page, err := db.Find(ctx, dy.Request{
+		Size:       10,
+		Conditions: []dy.Criteria{*dy.NewCriteria().And("ExpiresAt", time.Unix(1709450000, 0), dy.LT)},
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "s1", page.Items[0].ID)
+
+	page, err = db.Find(ctx, dy.Request{
+		Size:       10,
+		Conditions: []dy.Criteria{*dy.NewCriteria().And("CreatedAt", createdAt.Add(time.Second), dy.GT)},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+}
+
+func TestDynamodb_TimeEncoding_All(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, sessionsConfig))
+
+	// the field encoding takes precedence over the encoding of all the time fields
+	db := dy.NewClient[session](client, sessionsConfig,
+		dy.WithTimeEncoding(dy.TimeEpochMillis), dy.WithTimeEncoding(dy.TimeEpochSeconds, "ExpiresAt"))
+
+	at := time.UnixMilli(1709298000250).UTC()
+	key, err := db.Create(ctx, session{ID: "s1", CreatedAt: at, ExpiresAt: at, StartedAt: at})
+	require.NoError(t, err)
+
+	out, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String("sessions"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "s1"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1709298000250"}, out.Item["createdAt"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1709298000"}, out.Item["ttl"])
+	assert.Equal(t, &types.AttributeValueMemberNULL{Value: true}, out.Item["seenAt"])
+
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, at, item.StartedAt)
+	assert.Equal(t, at.Truncate(time.Second), item.ExpiresAt)
+}
+
+func TestDynamodb_TimeEncoding_Errors(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, sessionsConfig))
+
+	db := dy.NewClient[session](client, sessionsConfig, sessionOptions...)
+
+	_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String("sessions"),
+		Item: map[string]types.AttributeValue{
+			"id":  &types.AttributeValueMemberS{Value: "s1"},
+			"ttl": &types.AttributeValueMemberS{Value: "tomorrow"},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = db.GetItem(ctx, dy.DynamoPrimaryKey{PartitionKey: dy.NewDynamoStringAttrib("id", "s1")})
+	assert.ErrorIs(t, err, dy.ErrUnmarshal)
+
+	_, err = dy.NewClient[session](client, sessionsConfig, dy.WithTimeEncoding(dy.TimeEncoding(42), "ttl")).
+		Create(ctx, session{ID: "s3"})
+	assert.ErrorIs(t, err, dy.ErrMarshal)
+}
+
+func TestTimeEncoding_String(t *testing.T) {
+	assert.Equal(t, "EpochSeconds", dy.TimeEpochSeconds.String())
+	assert.Equal(t, "TimeEncoding(42)", dy.TimeEncoding(42).String())
+}
+
+func TestConfigFor_TimeEncoding(t *testing.T) {
+	conf, err := dy.ConfigFor[event]("events", dy.WithTimeEncoding(dy.TimeEpochMillis, "At"))
+	require.NoError(t, err)
+	assert.Equal(t, dy.DynamoKeyMetadata{Name: "At", Type: dy.Number, NumberType: dy.NumberInt64},
+		*conf.TableInfo.PrimaryKey.SortKey)
+
+	conf, err = dy.ConfigFor[event]("events")
+	require.NoError(t, err)
+	assert.Equal(t, dy.DynamoKeyMetadata{Name: "At", Type: dy.String}, *conf.TableInfo.PrimaryKey.SortKey)
+}
+
+type event struct {
+	Source string    `dy:"pk"`
+	At     time.Time `dy:"sk"`
+}
+
+func (e event) IsEmpty() bool {
+	return e.Source == ""
+}
@@ -0,0 +1,102 @@
+package dy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Timestamps the attributes holding the creation and the last update times of the items.
+//
+// The times are encoded as the entity fields of the attributes when they have a time encoding (see WithTimeEncoding),
+// with Encoding otherwise.
+type Timestamps struct {
+	// CreatedAt the attribute set by Create, and by Update if the item has none. Optional.
+	CreatedAt DBKey
+	// UpdatedAt the attribute set by Create and Update. Optional.
+	UpdatedAt DBKey
+	// Encoding the encoding of the times. Defaults to TimeRFC3339.
+	Encoding TimeEncoding
+}
+
+// enabled checks whether a timestamp attribute is configured.
+func (t Timestamps) enabled() bool {
+	return t.CreatedAt != "" || t.UpdatedAt != ""
+}
+
+// WithClock sets the clock of the client: the clock of the timestamps (see Timestamps) and of the Number keys
+// generated by the default key generator. Defaults to time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// timestamp encodes the time now of a timestamp attribute.
+func (d *DB[T]) timestamp(name DBKey, now time.Time) (types.AttributeValue, error) {
+	av, ok, err := d.codec.fieldValue(string(name), now)
+	if err == nil && !ok {
+		encoding := d.conf.Timestamps.Encoding
+		if encoding == 0 {
+			encoding = TimeRFC3339
+		}
+		av, err = encodeTime(now, encoding)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: timestamp %s: %w", ErrMarshal, name, err)
+	}
+
+	return av, nil
+}
+
+// stamp sets the timestamp attributes of a created item.
+func (d *DB[T]) stamp(item map[string]types.AttributeValue) error {
+	if !d.conf.Timestamps.enabled() {
+		return nil
+	}
+
+	now := d.now()
+	for _, name := range []DBKey{d.conf.Timestamps.CreatedAt, d.conf.Timestamps.UpdatedAt} {
+		if name == "" {
+			continue
+		}
+
+		av, err := d.timestamp(name, now)
+		if err != nil {
+			return err
+		}
+		item[string(name)] = av
+	}
+
+	return nil
+}
+
+// stampUpdate sets the updatedAt attribute of an updated item, and its createdAt attribute if it has none.
+// The timestamp attributes explicitly updated are left unchanged.
+func (d *DB[T]) stampUpdate(builder *DynamoExpressionBuilder, updated map[string]bool) error {
+	timestamps := d.conf.Timestamps
+	if !timestamps.enabled() {
+		return nil
+	}
+
+	now := d.now()
+	if name := timestamps.UpdatedAt; name != "" && !updated[string(name)] {
+		av, err := d.timestamp(name, now)
+		if err != nil {
+			return err
+		}
+		builder.WithUpdateField(string(name), av)
+	}
+
+	if name := timestamps.CreatedAt; name != "" && !updated[string(name)] {
+		av, err := d.timestamp(name, now)
+		if err != nil {
+			return err
+		}
+		builder.WithUpdateFieldIfNotExists(string(name), av)
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package dy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/dynamodb/memdb"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type post struct {
+	ID        string    `dynamodbav:"id"`
+	Title     string    `dynamodbav:"title"`
+	CreatedAt time.Time `dynamodbav:"createdAt"`
+	UpdatedAt time.Time `dynamodbav:"updatedAt"`
+}
+
+func (p post) IsEmpty() bool {
+	return p.ID == ""
+}
+
+var postsConfig = dy.DBConfig{
+	TableInfo: dy.TableInfo{
+		TableName: "posts",
+		PrimaryKey: dy.DBPrimaryKeyNames{
+			PartitionKey: dy.DynamoKeyMetadata{Name: "id", Type: dy.String},
+		},
+	},
+	Timestamps: dy.Timestamps{CreatedAt: "createdAt", UpdatedAt: "updatedAt"},
+}
+
+// clock returns a clock moving forward by a minute at every call, starting at start.
+func clock(start time.Time) func() time.Time {
+	now := start.Add(-time.Minute)
+	return func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+}
+
+func getRawItem(t *testing.T, client dy.DynamoClient, table, id string) map[string]types.AttributeValue {
+	out, err := client.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String(table),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: id}},
+	})
+	require.NoError(t, err)
+
+	return out.Item
+}
+
+func TestDynamodb_Timestamps(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+	require.NoError(t, client.CreateTableFromConfig(ctx, postsConfig))
+
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	db := dy.NewClient[post](client, postsConfig, dy.WithClock(clock(start)))
+
+	// the timestamps of the entity are overwritten
+	key, err := db.Create(ctx, post{ID: "p1", Title: "hello", UpdatedAt: time.Unix(0, 0)})
+	require.NoError(t, err)
+
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, post{ID: "p1", Title: "hello", CreatedAt: start, UpdatedAt: start}, *item)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "2024-05-01T09:00:00.000000000Z"},
+		getRawItem(t, client, "posts", "p1")["updatedAt"])
+
+	// the creation time is kept
+	require.NoError(t, db.Update(ctx, key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("title", "hello world")}))
+
+	item, err = db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, post{ID: "p1", Title: "hello world", CreatedAt: start, UpdatedAt: start.Add(time.Minute)}, *item)
+
+	// the explicitly updated timestamps are kept
+	updatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, db.Update(ctx, key, []dy.DynamoAttribute{{KeyName: "UpdatedAt", Value: updatedAt}}))
+
+	item, err = db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, updatedAt, item.UpdatedAt)
+	assert.Equal(t, start, item.CreatedAt)
+
+	// the items without creation time are given one
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String("posts"),
+		Item:      map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "p2"}},
+	})
+	require.NoError(t, err)
+
+	key = dy.DynamoPrimaryKey{PartitionKey: dy.NewDynamoStringAttrib("id", "p2")}
+	require.NoError(t, db.Update(ctx, key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("title", "upserted")}))
+
+	item, err = db.GetItem(ctx, key)
+	require.NoError(t, err)
+	at := start.Add(3 * time.Minute)
+	assert.Equal(t, post{ID: "p2", Title: "upserted", CreatedAt: at, UpdatedAt: at}, *item)
+}
+
+func TestDynamodb_Timestamps_Encoding(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+
+	conf := postsConfig
+	conf.Timestamps = dy.Timestamps{CreatedAt: "created", UpdatedAt: "updatedAt", Encoding: dy.TimeEpochMillis}
+	require.NoError(t, client.CreateTableFromConfig(ctx, conf))
+
+	// the timestamps are encoded as their entity field, with the config encoding otherwise
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	db := dy.NewClient[post](client, conf,
+		dy.WithClock(clock(start)), dy.WithTimeEncoding(dy.TimeEpochSeconds, "UpdatedAt"))
+
+	key, err := db.Create(ctx, post{ID: "p1"})
+	require.NoError(t, err)
+
+	raw := getRawItem(t, client, "posts", "p1")
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1714554000000"}, raw["created"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1714554000"}, raw["updatedAt"])
+
+	require.NoError(t, db.Update(ctx, key, []dy.DynamoAttribute{dy.NewDynamoStringAttrib("title", "hello")}))
+
+	raw = getRawItem(t, client, "posts", "p1")
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1714554000000"}, raw["created"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1714554060"}, raw["updatedAt"])
+
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, start.Add(time.Minute), item.UpdatedAt)
+}
+
+func TestDynamodb_Timestamps_Keys(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+
+	// the timestamps are set before the keys are extracted, even when a timestamp is a table key (rejected by Validate)
+	conf := postsConfig
+	conf.Timestamps = dy.Timestamps{}
+	conf.TableInfo.PrimaryKey.SortKey = &dy.DynamoKeyMetadata{Name: "createdAt", Type: dy.String}
+	require.NoError(t, client.CreateTableFromConfig(ctx, conf))
+	conf.Timestamps = postsConfig.Timestamps
+
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	db := dy.NewClient[post](client, conf, dy.WithClock(clock(start)))
+
+	key, err := db.Create(ctx, post{ID: "p1", Title: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, dy.NewDynamoStringAttrib("createdAt", "2024-05-01T09:00:00.000000000Z"), *key.SortKey)
+
+	item, err := db.GetItem(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, post{ID: "p1", Title: "hello", CreatedAt: start, UpdatedAt: start}, *item)
+}
+
+func TestDynamodb_Clock_KeyGenerator(t *testing.T) {
+	ctx := context.Background()
+	client := memdb.New()
+
+	// the clock of the client generates the Number keys without configured generator
+	conf := dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName: "posts",
+			PrimaryKey: dy.DBPrimaryKeyNames{
+				PartitionKey: dy.DynamoKeyMetadata{Name: "id", Type: dy.Number, NumberType: dy.NumberInt64},
+			},
+		},
+	}
+	require.NoError(t, client.CreateTableFromConfig(ctx, conf))
+
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	db := dy.NewClient[post](client, conf, dy.WithClock(func() time.Time { return at }))
+
+	key, err := db.Create(ctx, post{Title: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, at.UnixNano(), key.PartitionKey.Value)
+}
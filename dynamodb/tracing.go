@@ -0,0 +1,91 @@
+package dy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const tracerName = "github.com/AhmedBenCharrada/awsgo/dynamodb"
+
+// WithTracerProvider enables the OpenTelemetry tracing of the wrapper operations.
+//
+// Every operation (Create, Update, Delete, Find, GetItem and GetItems) gets its own span,
+// the GetItems batches being traced as child spans of the GetItems span.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = provider
+	}
+}
+
+func newTracer(opts options) trace.Tracer {
+	if opts.tracerProvider == nil {
+		return noop.NewTracerProvider().Tracer(tracerName)
+	}
+
+	return opts.tracerProvider.Tracer(tracerName)
+}
+
+// startSpan starts the span of a wrapper operation performing the provided dynamodb operation.
+func (d *DB[T]) startSpan(ctx context.Context, name, operation string, index *string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemDynamoDB,
+		semconv.DBOperation(operation),
+		semconv.AWSDynamoDBTableNames(d.conf.TableInfo.TableName),
+	}
+
+	if index != nil {
+		attrs = append(attrs, semconv.AWSDynamoDBIndexName(*index))
+	}
+
+	return d.tracer.Start(ctx, "dynamodb."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endSpan records the error (if any) and ends the span.
+// ErrNotFound is an expected outcome and is not recorded as a span error.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// recordCount records the number of returned items.
+func recordCount(span trace.Span, count int) {
+	span.SetAttributes(semconv.AWSDynamoDBCount(count))
+}
+
+// recordConsumedCapacity records the consumed capacity returned by dynamodb (if requested).
+func recordConsumedCapacity(span trace.Span, capacities ...*types.ConsumedCapacity) {
+	if !span.IsRecording() {
+		return
+	}
+
+	values := make([]string, 0, len(capacities))
+	for _, c := range capacities {
+		if c == nil {
+			continue
+		}
+
+		if b, err := json.Marshal(c); err == nil {
+			values = append(values, string(b))
+		}
+	}
+
+	if len(values) > 0 {
+		span.SetAttributes(semconv.AWSDynamoDBConsumedCapacity(values...))
+	}
+}
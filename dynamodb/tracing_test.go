@@ -0,0 +1,156 @@
+package dy_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newSpanRecorder() (*tracetest.SpanRecorder, dy.Option) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	return sr, dy.WithTracerProvider(tp)
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+func TestTracing_Create(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("PutItem", mock.Anything, mock.Anything).Return(&dynamodb.PutItemOutput{
+		ConsumedCapacity: &types.ConsumedCapacity{
+			TableName:     aws.String(dbConfig.TableInfo.TableName),
+			CapacityUnits: aws.Float64(1),
+		},
+	}, nil)
+
+	sr, opt := newSpanRecorder()
+	db := dy.NewClient[entity](m, dbConfig, opt)
+
+	_, err := db.Create(context.Background(), entity{Id: "123", GroupID: aws.Int(1)})
+	assert.NoError(t, err)
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "dynamodb.Create", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	attrs := spanAttributes(spans[0])
+	assert.Equal(t, "dynamodb", attrs["db.system"].AsString())
+	assert.Equal(t, "PutItem", attrs["db.operation"].AsString())
+	assert.Equal(t, []string{"tableName"}, attrs["aws.dynamodb.table_names"].AsStringSlice())
+	capacity := attrs["aws.dynamodb.consumed_capacity"].AsStringSlice()
+	assert.Len(t, capacity, 1)
+	assert.Contains(t, capacity[0], `"CapacityUnits":1`)
+}
+
+func TestTracing_Find(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("Query", mock.Anything, mock.Anything).Return(&dynamodb.QueryOutput{
+		Items: getItemAttributeValuesTestData(),
+	}, nil).Once()
+	m.On("Scan", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("scan error")).Once()
+
+	sr, opt := newSpanRecorder()
+	db := dy.NewClient[entity](m, dbConfig, opt)
+
+	_, err := db.Find(context.Background(), dy.Request{
+		Size:         10,
+		Index:        aws.String("byGroup"),
+		PartitionKey: dy.NewDynamoNumberAttrib("groupID", "1234"),
+	})
+	assert.NoError(t, err)
+
+	_, err = db.Find(context.Background(), dy.Request{Size: 10})
+	assert.Error(t, err)
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 2)
+
+	attrs := spanAttributes(spans[0])
+	assert.Equal(t, "Query", attrs["db.operation"].AsString())
+	assert.Equal(t, "byGroup", attrs["aws.dynamodb.index_name"].AsString())
+	assert.Equal(t, int64(1), attrs["aws.dynamodb.count"].AsInt64())
+
+	attrs = spanAttributes(spans[1])
+	assert.Equal(t, "Scan", attrs["db.operation"].AsString())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "Find tableName: scan error", spans[1].Status().Description)
+	assert.Len(t, spans[1].Events(), 1)
+}
+
+func TestTracing_GetItem_NotFound(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("GetItem", mock.Anything, mock.Anything).Return(&dynamodb.GetItemOutput{}, nil)
+
+	sr, opt := newSpanRecorder()
+	db := dy.NewClient[entity](m, dbConfig, opt)
+
+	_, err := db.GetItem(context.Background(), dy.DynamoPrimaryKey{
+		PartitionKey: *dy.NewDynamoNumberAttrib("groupID", "1234"),
+	})
+	assert.ErrorIs(t, err, dy.ErrNotFound)
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "dynamodb.GetItem", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+}
+
+func TestTracing_GetItems(t *testing.T) {
+	m := mocks.NewDynamoClient(t)
+	m.On("BatchGetItem", mock.Anything, mock.Anything).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]types.AttributeValue{
+			dbConfig.TableInfo.TableName: getItemAttributeValuesTestData(),
+		},
+	}, nil).Twice()
+
+	sr, opt := newSpanRecorder()
+	db := dy.NewClient[entity](m, dbConfig, opt)
+
+	keys := make([]dy.DynamoPrimaryKey, 0, 30)
+	for i := 0; i < 30; i++ {
+		keys = append(keys, dy.DynamoPrimaryKey{
+			PartitionKey: *dy.NewDynamoNumberAttrib("groupID", fmt.Sprint(i)),
+		})
+	}
+
+	items, _, err := db.GetItems(context.Background(), keys)
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	spans := sr.Ended()
+	assert.Len(t, spans, 3)
+
+	// the parent span ends last
+	parent := spans[2]
+	assert.Equal(t, "dynamodb.GetItems", parent.Name())
+	assert.Equal(t, int64(2), spanAttributes(parent)["aws.dynamodb.count"].AsInt64())
+
+	sizes := make([]int64, 0, 2)
+	for _, child := range spans[:2] {
+		assert.Equal(t, "dynamodb.GetItems.batch", child.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), child.Parent().SpanID())
+		sizes = append(sizes, spanAttributes(child)["awsgo.dynamodb.batch_size"].AsInt64())
+	}
+	assert.ElementsMatch(t, []int64{25, 5}, sizes)
+}
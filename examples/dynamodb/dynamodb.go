@@ -13,20 +13,8 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 )
 
-var conf = dy.DBConfig{
-	TableInfo: dy.TableInfo{
-		TableName: "User",
-		PrimaryKey: dy.DBPrimaryKeyNames{
-			PartitionKey: dy.DynamoKeyMetadata{
-				Name: "ID",
-				Type: dy.String,
-			},
-		},
-	},
-}
-
 type User struct {
-	ID         string `json:"id"`
+	ID         string `json:"id" dy:"pk"`
 	Enabled    bool   `json:"enabled"`
 	Name       string `json:"name"`
 	FamilyName string `json:"familyName"`
@@ -54,8 +42,22 @@ func main() {
 		o.BaseEndpoint = aws.String("http://localhost:8000")
 	})
 
+	// The User attributes are named after the json tags.
+	opts := []dy.Option{dy.WithTagKey("json")}
+
+	// Derive the table config from the User struct tags.
+	conf, err := dy.ConfigFor[User]("User", opts...)
+	if err != nil {
+		log.Fatalf("invalid User entity, %v", err)
+	}
+
+	// Create the User table unless it already exists.
+	if err := dy.EnsureTable(ctx, client, conf, dy.TableOptions{}); err != nil {
+		log.Fatalf("unable to provision the table, %v", err)
+	}
+
 	// Create the dynamodb client wrapper for the User entity.
-	svc := dy.NewClient[User](client, conf)
+	svc := dy.NewClient[User](client, conf, opts...)
 
 	// Insert 54 user items.
 	for i := 0; i < 54; i++ {
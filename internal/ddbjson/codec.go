@@ -0,0 +1,448 @@
+// Package ddbjson encodes the dynamodb SDK input and output structures in the DynamoDB JSON protocol
+// (awsJson1_0) format: the structure fields keep their name, the attribute values are tagged with
+// their type (e.g. {"S": "value"}), the binaries are base64 encoded and the timestamps are epoch seconds.
+package ddbjson
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	attributeValueType = reflect.TypeOf((*types.AttributeValue)(nil)).Elem()
+	timeType           = reflect.TypeOf(time.Time{})
+	bytesType          = reflect.TypeOf([]byte(nil))
+)
+
+// Marshal encodes an SDK structure. The nil and the empty fields are omitted.
+func Marshal(v interface{}) ([]byte, error) {
+	doc, err := encode(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+
+	return json.Marshal(doc)
+}
+
+// Unmarshal decodes a document into an SDK structure pointer. The unknown fields are ignored.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("ddbjson: unmarshal target must be a non-nil pointer, got %T", v)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+
+	return decode(doc, rv.Elem())
+}
+
+// MarshalAttributeValue encodes an attribute value.
+func MarshalAttributeValue(av types.AttributeValue) ([]byte, error) {
+	doc, err := encodeAttributeValue(av)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(doc)
+}
+
+// UnmarshalAttributeValue decodes an attribute value.
+func UnmarshalAttributeValue(data []byte) (types.AttributeValue, error) {
+	var av types.AttributeValue
+	if err := Unmarshal(data, &av); err != nil {
+		return nil, err
+	}
+
+	return av, nil
+}
+
+// encode converts a value to its JSON document representation, nil if the value is omitted.
+func encode(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if v.Type() == attributeValueType {
+		if v.IsNil() {
+			return nil, nil
+		}
+		return encodeAttributeValue(v.Interface().(types.AttributeValue))
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return encode(v.Elem())
+
+	case reflect.Struct:
+		if v.Type() == timeType {
+			t := v.Interface().(time.Time)
+			return json.Number(strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', -1, 64)), nil
+		}
+		return encodeStruct(v)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		if v.Type() == bytesType {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+
+		list := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem, err := encode(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, elem)
+		}
+		return list, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		doc := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := encode(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			doc[iter.Key().String()] = elem
+		}
+		return doc, nil
+
+	case reflect.String:
+		if v.Len() == 0 {
+			return nil, nil
+		}
+		return v.String(), nil
+
+	case reflect.Bool:
+		return v.Bool(), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(v.Int(), 10)), nil
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("ddbjson: unsupported number %v", f)
+		}
+		return json.Number(strconv.FormatFloat(f, 'f', -1, 64)), nil
+	}
+
+	return nil, fmt.Errorf("ddbjson: unsupported type %s", v.Type())
+}
+
+func encodeStruct(v reflect.Value) (interface{}, error) {
+	doc := make(map[string]interface{})
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Name == "ResultMetadata" {
+			continue
+		}
+
+		value, err := encode(v.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field.Name, err)
+		}
+
+		if value != nil {
+			doc[field.Name] = value
+		}
+	}
+
+	return doc, nil
+}
+
+func encodeAttributeValue(av types.AttributeValue) (interface{}, error) {
+	switch av := av.(type) {
+	case *types.AttributeValueMemberS:
+		return map[string]interface{}{"S": av.Value}, nil
+	case *types.AttributeValueMemberN:
+		return map[string]interface{}{"N": av.Value}, nil
+	case *types.AttributeValueMemberB:
+		return map[string]interface{}{"B": base64.StdEncoding.EncodeToString(av.Value)}, nil
+	case *types.AttributeValueMemberBOOL:
+		return map[string]interface{}{"BOOL": av.Value}, nil
+	case *types.AttributeValueMemberNULL:
+		return map[string]interface{}{"NULL": av.Value}, nil
+	case *types.AttributeValueMemberSS:
+		return map[string]interface{}{"SS": stringsOrEmpty(av.Value)}, nil
+	case *types.AttributeValueMemberNS:
+		return map[string]interface{}{"NS": stringsOrEmpty(av.Value)}, nil
+	case *types.AttributeValueMemberBS:
+		list := make([]interface{}, 0, len(av.Value))
+		for _, b := range av.Value {
+			list = append(list, base64.StdEncoding.EncodeToString(b))
+		}
+		return map[string]interface{}{"BS": list}, nil
+	case *types.AttributeValueMemberL:
+		list := make([]interface{}, 0, len(av.Value))
+		for _, elem := range av.Value {
+			doc, err := encodeAttributeValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, doc)
+		}
+		return map[string]interface{}{"L": list}, nil
+	case *types.AttributeValueMemberM:
+		m := make(map[string]interface{}, len(av.Value))
+		for name, elem := range av.Value {
+			doc, err := encodeAttributeValue(elem)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			m[name] = doc
+		}
+		return map[string]interface{}{"M": m}, nil
+	}
+
+	return nil, fmt.Errorf("ddbjson: unsupported attribute value %T", av)
+}
+
+func stringsOrEmpty(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+
+	return s
+}
+
+// decode assigns a JSON document to a value.
+func decode(doc interface{}, v reflect.Value) error {
+	if doc == nil {
+		return nil
+	}
+
+	if v.Type() == attributeValueType {
+		av, err := decodeAttributeValue(doc)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(av))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := decode(doc, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+
+	case reflect.Struct:
+		if v.Type() == timeType {
+			n, ok := doc.(json.Number)
+			if !ok {
+				return typeError(doc, v)
+			}
+
+			f, err := n.Float64()
+			if err != nil {
+				return err
+			}
+
+			sec, frac := math.Modf(f)
+			v.Set(reflect.ValueOf(time.Unix(int64(sec), int64(math.Round(frac*float64(time.Second)))).UTC()))
+			return nil
+		}
+
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return typeError(doc, v)
+		}
+
+		for name, value := range m {
+			field, ok := v.Type().FieldByName(name)
+			if !ok || !field.IsExported() {
+				continue
+			}
+
+			if err := decode(value, v.FieldByIndex(field.Index)); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		return nil
+
+	case reflect.Slice:
+		if v.Type() == bytesType {
+			s, ok := doc.(string)
+			if !ok {
+				return typeError(doc, v)
+			}
+
+			b, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(b)
+			return nil
+		}
+
+		list, ok := doc.([]interface{})
+		if !ok {
+			return typeError(doc, v)
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, elem := range list {
+			if err := decode(elem, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+
+	case reflect.Map:
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return typeError(doc, v)
+		}
+
+		result := reflect.MakeMapWithSize(v.Type(), len(m))
+		for name, value := range m {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := decode(value, elem); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			result.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), elem)
+		}
+		v.Set(result)
+		return nil
+
+	case reflect.String:
+		s, ok := doc.(string)
+		if !ok {
+			return typeError(doc, v)
+		}
+		v.SetString(s)
+		return nil
+
+	case reflect.Bool:
+		b, ok := doc.(bool)
+		if !ok {
+			return typeError(doc, v)
+		}
+		v.SetBool(b)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := doc.(json.Number)
+		if !ok {
+			return typeError(doc, v)
+		}
+
+		i, err := strconv.ParseInt(n.String(), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		n, ok := doc.(json.Number)
+		if !ok {
+			return typeError(doc, v)
+		}
+
+		f, err := strconv.ParseFloat(n.String(), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	}
+
+	return fmt.Errorf("ddbjson: unsupported type %s", v.Type())
+}
+
+func decodeAttributeValue(doc interface{}) (types.AttributeValue, error) {
+	m, ok := doc.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return nil, fmt.Errorf("ddbjson: an attribute value must be an object with a single member")
+	}
+
+	for kind, value := range m {
+		switch kind {
+		case "S", "N":
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("ddbjson: %s must be a string", kind)
+			}
+
+			if kind == "S" {
+				return &types.AttributeValueMemberS{Value: s}, nil
+			}
+			return &types.AttributeValueMemberN{Value: s}, nil
+
+		case "B":
+			av := &types.AttributeValueMemberB{}
+			return av, decode(value, reflect.ValueOf(&av.Value).Elem())
+
+		case "BOOL":
+			av := &types.AttributeValueMemberBOOL{}
+			return av, decode(value, reflect.ValueOf(&av.Value).Elem())
+
+		case "NULL":
+			av := &types.AttributeValueMemberNULL{}
+			return av, decode(value, reflect.ValueOf(&av.Value).Elem())
+
+		case "SS":
+			av := &types.AttributeValueMemberSS{}
+			return av, decode(value, reflect.ValueOf(&av.Value).Elem())
+
+		case "NS":
+			av := &types.AttributeValueMemberNS{}
+			return av, decode(value, reflect.ValueOf(&av.Value).Elem())
+
+		case "BS":
+			av := &types.AttributeValueMemberBS{}
+			return av, decode(value, reflect.ValueOf(&av.Value).Elem())
+
+		case "L":
+			av := &types.AttributeValueMemberL{Value: []types.AttributeValue{}}
+			return av, decode(value, reflect.ValueOf(&av.Value).Elem())
+
+		case "M":
+			av := &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}}
+			return av, decode(value, reflect.ValueOf(&av.Value).Elem())
+		}
+
+		return nil, fmt.Errorf("ddbjson: unknown attribute value type %q", kind)
+	}
+
+	return nil, nil
+}
+
+func typeError(doc interface{}, v reflect.Value) error {
+	return fmt.Errorf("ddbjson: cannot decode %T into %s", doc, v.Type())
+}
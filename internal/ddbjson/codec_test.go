@@ -0,0 +1,100 @@
+package ddbjson_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/ddbjson"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal(t *testing.T) {
+	in := &dynamodb.QueryInput{
+		TableName:              aws.String("users"),
+		KeyConditionExpression: aws.String("pk = :pk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":pk": &types.AttributeValueMemberN{Value: "1"},
+		},
+		Limit:  aws.Int32(10),
+		Select: types.SelectAllAttributes,
+	}
+
+	data, err := ddbjson.Marshal(in)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"TableName": "users",
+		"KeyConditionExpression": "pk = :pk",
+		"ExpressionAttributeValues": {":pk": {"N": "1"}},
+		"Limit": 10,
+		"Select": "ALL_ATTRIBUTES"
+	}`, string(data))
+
+	var decoded dynamodb.QueryInput
+	require.NoError(t, ddbjson.Unmarshal(data, &decoded))
+	assert.Equal(t, in, &decoded)
+}
+
+func TestUnmarshal(t *testing.T) {
+	var out dynamodb.DescribeTableOutput
+	err := ddbjson.Unmarshal([]byte(`{"Table": {
+		"TableName": "users",
+		"CreationDateTime": 1700000000.5,
+		"ItemCount": 3,
+		"KeySchema": [{"AttributeName": "pk", "KeyType": "HASH"}],
+		"Unknown": true
+	}}`), &out)
+	require.NoError(t, err)
+
+	assert.Equal(t, "users", aws.ToString(out.Table.TableName))
+	assert.Equal(t, time.Unix(1700000000, int64(time.Second/2)).UTC(), aws.ToTime(out.Table.CreationDateTime))
+	assert.Equal(t, int64(3), aws.ToInt64(out.Table.ItemCount))
+	assert.Equal(t, []types.KeySchemaElement{{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash}}, out.Table.KeySchema)
+
+	assert.Error(t, ddbjson.Unmarshal([]byte(`{"TableName": 1}`), &dynamodb.GetItemInput{}))
+	assert.Error(t, ddbjson.Unmarshal([]byte(`{}`), dynamodb.GetItemInput{}))
+}
+
+func TestAttributeValue(t *testing.T) {
+	av := &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"s":    &types.AttributeValueMemberS{Value: "text"},
+		"n":    &types.AttributeValueMemberN{Value: "1.5"},
+		"b":    &types.AttributeValueMemberB{Value: []byte("bin")},
+		"bool": &types.AttributeValueMemberBOOL{Value: true},
+		"null": &types.AttributeValueMemberNULL{Value: true},
+		"ss":   &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"ns":   &types.AttributeValueMemberNS{Value: []string{"1", "2"}},
+		"bs":   &types.AttributeValueMemberBS{Value: [][]byte{[]byte("x")}},
+		"l":    &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+		"m":    &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}},
+	}}
+
+	data, err := ddbjson.MarshalAttributeValue(av)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"M": {
+		"s": {"S": "text"},
+		"n": {"N": "1.5"},
+		"b": {"B": "Ymlu"},
+		"bool": {"BOOL": true},
+		"null": {"NULL": true},
+		"ss": {"SS": ["a", "b"]},
+		"ns": {"NS": ["1", "2"]},
+		"bs": {"BS": ["eA=="]},
+		"l": {"L": []},
+		"m": {"M": {}}
+	}}`, string(data))
+
+	decoded, err := ddbjson.UnmarshalAttributeValue(data)
+	require.NoError(t, err)
+	assert.Equal(t, av, decoded)
+
+	_, err = ddbjson.UnmarshalAttributeValue([]byte(`{"S": "a", "N": "1"}`))
+	assert.Error(t, err)
+
+	_, err = ddbjson.UnmarshalAttributeValue([]byte(`{"X": "a"}`))
+	assert.Error(t, err)
+}
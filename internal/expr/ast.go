@@ -0,0 +1,202 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PathElement an element of a document path: an attribute name (or #name placeholder) or a list index.
+type PathElement struct {
+	Name    string
+	Index   int
+	IsIndex bool
+}
+
+// Path a document path (e.g. a.b[1].#c).
+type Path []PathElement
+
+func (p Path) String() string {
+	var sb strings.Builder
+	for i, e := range p {
+		if e.IsIndex {
+			sb.WriteString("[")
+			sb.WriteString(strconv.Itoa(e.Index))
+			sb.WriteString("]")
+			continue
+		}
+
+		if i > 0 {
+			sb.WriteString(".")
+		}
+		sb.WriteString(e.Name)
+	}
+
+	return sb.String()
+}
+
+// Operand an operand of a condition or of an update action.
+type Operand interface {
+	String() string
+	operand()
+}
+
+// PathOperand an attribute of the item.
+type PathOperand struct {
+	Path Path
+}
+
+// ValueOperand an expression attribute value (e.g. :value).
+type ValueOperand struct {
+	Name string
+}
+
+// FuncOperand a function returning a value: size, if_not_exists or list_append.
+type FuncOperand struct {
+	Name string
+	Args []Operand
+}
+
+// ArithOperand an addition or a subtraction of an update action.
+type ArithOperand struct {
+	Op          string
+	Left, Right Operand
+}
+
+func (PathOperand) operand()  {}
+func (ValueOperand) operand() {}
+func (FuncOperand) operand()  {}
+func (ArithOperand) operand() {}
+
+func (o PathOperand) String() string  { return o.Path.String() }
+func (o ValueOperand) String() string { return o.Name }
+func (o FuncOperand) String() string  { return o.Name + "(" + join(o.Args) + ")" }
+func (o ArithOperand) String() string { return o.Left.String() + " " + o.Op + " " + o.Right.String() }
+
+// Condition a condition (key condition, filter or condition expression).
+type Condition interface {
+	String() string
+	condition()
+}
+
+// Compare a comparison: =, <>, <, <=, > or >=.
+type Compare struct {
+	Op          string
+	Left, Right Operand
+}
+
+// Between a BETWEEN condition.
+type Between struct {
+	Operand, Low, High Operand
+}
+
+// In an IN condition.
+type In struct {
+	Operand Operand
+	List    []Operand
+}
+
+// FuncCondition a function condition: attribute_exists, attribute_not_exists, attribute_type,
+// begins_with or contains.
+type FuncCondition struct {
+	Name string
+	Args []Operand
+}
+
+// And a logical AND.
+type And struct {
+	Left, Right Condition
+}
+
+// Or a logical OR.
+type Or struct {
+	Left, Right Condition
+}
+
+// Not a logical NOT.
+type Not struct {
+	Condition Condition
+}
+
+func (Compare) condition()       {}
+func (Between) condition()       {}
+func (In) condition()            {}
+func (FuncCondition) condition() {}
+func (And) condition()           {}
+func (Or) condition()            {}
+func (Not) condition()           {}
+
+func (c Compare) String() string { return c.Left.String() + " " + c.Op + " " + c.Right.String() }
+func (c Between) String() string {
+	return c.Operand.String() + " BETWEEN " + c.Low.String() + " AND " + c.High.String()
+}
+func (c In) String() string            { return c.Operand.String() + " IN (" + join(c.List) + ")" }
+func (c FuncCondition) String() string { return c.Name + "(" + join(c.Args) + ")" }
+func (c And) String() string           { return "(" + c.Left.String() + " AND " + c.Right.String() + ")" }
+func (c Or) String() string            { return "(" + c.Left.String() + " OR " + c.Right.String() + ")" }
+func (c Not) String() string           { return "NOT " + c.Condition.String() }
+
+// SetAction a SET action of an update expression.
+type SetAction struct {
+	Path  Path
+	Value Operand
+}
+
+// PathValueAction an ADD or a DELETE action of an update expression.
+type PathValueAction struct {
+	Path  Path
+	Value Operand
+}
+
+// Update an update expression.
+type Update struct {
+	Set    []SetAction
+	Remove []Path
+	Add    []PathValueAction
+	Delete []PathValueAction
+}
+
+func (u Update) String() string {
+	var clauses []string
+
+	if len(u.Set) > 0 {
+		actions := make([]string, 0, len(u.Set))
+		for _, a := range u.Set {
+			actions = append(actions, a.Path.String()+" = "+a.Value.String())
+		}
+		clauses = append(clauses, "SET "+strings.Join(actions, ", "))
+	}
+
+	if len(u.Remove) > 0 {
+		actions := make([]string, 0, len(u.Remove))
+		for _, p := range u.Remove {
+			actions = append(actions, p.String())
+		}
+		clauses = append(clauses, "REMOVE "+strings.Join(actions, ", "))
+	}
+
+	for _, c := range []struct {
+		keyword string
+		actions []PathValueAction
+	}{{"ADD", u.Add}, {"DELETE", u.Delete}} {
+		if len(c.actions) == 0 {
+			continue
+		}
+
+		actions := make([]string, 0, len(c.actions))
+		for _, a := range c.actions {
+			actions = append(actions, a.Path.String()+" "+a.Value.String())
+		}
+		clauses = append(clauses, c.keyword+" "+strings.Join(actions, ", "))
+	}
+
+	return strings.Join(clauses, " ")
+}
+
+func join(operands []Operand) string {
+	s := make([]string, 0, len(operands))
+	for _, o := range operands {
+		s = append(s, o.String())
+	}
+
+	return strings.Join(s, ", ")
+}
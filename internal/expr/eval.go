@@ -0,0 +1,472 @@
+package expr
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Env the expression attribute names and values the placeholders are resolved with.
+type Env struct {
+	Names  map[string]string
+	Values map[string]types.AttributeValue
+}
+
+// EvalError an expression that cannot be evaluated (unknown placeholder, type mismatch...).
+type EvalError struct {
+	Msg string
+}
+
+func (e *EvalError) Error() string {
+	return e.Msg
+}
+
+func evalErrorf(format string, args ...interface{}) error {
+	return &EvalError{Msg: fmt.Sprintf(format, args...)}
+}
+
+// Name resolves an attribute name, substituting the #name placeholders.
+func (e Env) Name(name string) (string, error) {
+	if !strings.HasPrefix(name, "#") {
+		return name, nil
+	}
+
+	resolved, ok := e.Names[name]
+	if !ok {
+		return "", evalErrorf("An expression attribute name used in the document path is not defined; attribute name: %s", name)
+	}
+
+	return resolved, nil
+}
+
+// Value resolves a :value placeholder.
+func (e Env) Value(name string) (types.AttributeValue, error) {
+	v, ok := e.Values[name]
+	if !ok {
+		return nil, evalErrorf("An expression attribute value used in expression is not defined; attribute value: %s", name)
+	}
+
+	return v, nil
+}
+
+// Resolve substitutes the placeholders of a path.
+func (e Env) Resolve(p Path) (Path, error) {
+	resolved := make(Path, 0, len(p))
+	for _, elem := range p {
+		if !elem.IsIndex {
+			name, err := e.Name(elem.Name)
+			if err != nil {
+				return nil, err
+			}
+			elem.Name = name
+		}
+		resolved = append(resolved, elem)
+	}
+
+	return resolved, nil
+}
+
+// Get returns the value at a resolved path.
+func Get(item map[string]types.AttributeValue, p Path) (types.AttributeValue, bool) {
+	var current types.AttributeValue = &types.AttributeValueMemberM{Value: item}
+
+	for _, elem := range p {
+		switch v := current.(type) {
+		case *types.AttributeValueMemberM:
+			if elem.IsIndex {
+				return nil, false
+			}
+
+			next, ok := v.Value[elem.Name]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case *types.AttributeValueMemberL:
+			if !elem.IsIndex || elem.Index >= len(v.Value) {
+				return nil, false
+			}
+			current = v.Value[elem.Index]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// Eval evaluates a condition against an item.
+func Eval(c Condition, item map[string]types.AttributeValue, env Env) (bool, error) {
+	switch c := c.(type) {
+	case And:
+		left, err := Eval(c.Left, item, env)
+		if err != nil || !left {
+			return false, err
+		}
+		return Eval(c.Right, item, env)
+	case Or:
+		left, err := Eval(c.Left, item, env)
+		if err != nil || left {
+			return left, err
+		}
+		return Eval(c.Right, item, env)
+	case Not:
+		ok, err := Eval(c.Condition, item, env)
+		return !ok, err
+	case Compare:
+		return evalCompare(c, item, env)
+	case Between:
+		v, ok, err := evalOperand(c.Operand, item, env)
+		if err != nil || !ok {
+			return false, err
+		}
+
+		low, okLow, err := evalOperand(c.Low, item, env)
+		if err != nil || !okLow {
+			return false, err
+		}
+
+		high, okHigh, err := evalOperand(c.High, item, env)
+		if err != nil || !okHigh {
+			return false, err
+		}
+
+		if cmp, ok := CompareValues(low, high); ok && cmp > 0 {
+			return false, evalErrorf("Invalid expression: The BETWEEN operator requires upper bound to be greater than or equal to lower bound")
+		}
+
+		cmpLow, okLow := CompareValues(v, low)
+		cmpHigh, okHigh := CompareValues(v, high)
+		return okLow && okHigh && cmpLow >= 0 && cmpHigh <= 0, nil
+	case In:
+		v, ok, err := evalOperand(c.Operand, item, env)
+		if err != nil || !ok {
+			return false, err
+		}
+
+		for _, o := range c.List {
+			candidate, ok, err := evalOperand(o, item, env)
+			if err != nil {
+				return false, err
+			}
+
+			if ok && Equal(v, candidate) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	case FuncCondition:
+		return evalFunction(c, item, env)
+	}
+
+	return false, evalErrorf("unsupported condition %T", c)
+}
+
+func evalCompare(c Compare, item map[string]types.AttributeValue, env Env) (bool, error) {
+	left, okLeft, err := evalOperand(c.Left, item, env)
+	if err != nil {
+		return false, err
+	}
+
+	right, okRight, err := evalOperand(c.Right, item, env)
+	if err != nil {
+		return false, err
+	}
+
+	if !okLeft || !okRight {
+		// a missing attribute is different from any value
+		return c.Op == "<>", nil
+	}
+
+	switch c.Op {
+	case "=":
+		return Equal(left, right), nil
+	case "<>":
+		return !Equal(left, right), nil
+	}
+
+	cmp, ok := CompareValues(left, right)
+	if !ok {
+		return false, nil
+	}
+
+	switch c.Op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func evalFunction(c FuncCondition, item map[string]types.AttributeValue, env Env) (bool, error) {
+	v, exists, err := evalOperand(c.Args[0], item, env)
+	if err != nil {
+		return false, err
+	}
+
+	switch c.Name {
+	case "attribute_exists":
+		return exists, nil
+	case "attribute_not_exists":
+		return !exists, nil
+	}
+
+	arg, ok, err := evalOperand(c.Args[1], item, env)
+	if err != nil || !ok || !exists {
+		return false, err
+	}
+
+	switch c.Name {
+	case "attribute_type":
+		t, ok := arg.(*types.AttributeValueMemberS)
+		if !ok {
+			return false, evalErrorf("Invalid attribute_type operand: the type must be a string")
+		}
+
+		return TypeOf(v) == t.Value, nil
+	case "begins_with":
+		switch x := v.(type) {
+		case *types.AttributeValueMemberS:
+			prefix, ok := arg.(*types.AttributeValueMemberS)
+			return ok && strings.HasPrefix(x.Value, prefix.Value), nil
+		case *types.AttributeValueMemberB:
+			prefix, ok := arg.(*types.AttributeValueMemberB)
+			return ok && bytes.HasPrefix(x.Value, prefix.Value), nil
+		}
+
+		return false, nil
+	default: // contains
+		switch x := v.(type) {
+		case *types.AttributeValueMemberS:
+			sub, ok := arg.(*types.AttributeValueMemberS)
+			return ok && strings.Contains(x.Value, sub.Value), nil
+		case *types.AttributeValueMemberB:
+			sub, ok := arg.(*types.AttributeValueMemberB)
+			return ok && bytes.Contains(x.Value, sub.Value), nil
+		case *types.AttributeValueMemberSS, *types.AttributeValueMemberNS, *types.AttributeValueMemberBS:
+			return containsValue(setElements(v), arg), nil
+		case *types.AttributeValueMemberL:
+			return containsValue(x.Value, arg), nil
+		}
+
+		return false, nil
+	}
+}
+
+// evalOperand evaluates an operand. ok is false if the operand refers to a missing attribute.
+func evalOperand(o Operand, item map[string]types.AttributeValue, env Env) (types.AttributeValue, bool, error) {
+	switch o := o.(type) {
+	case ValueOperand:
+		v, err := env.Value(o.Name)
+		return v, err == nil, err
+	case PathOperand:
+		p, err := env.Resolve(o.Path)
+		if err != nil {
+			return nil, false, err
+		}
+
+		v, ok := Get(item, p)
+		return v, ok, nil
+	case FuncOperand:
+		return evalFuncOperand(o, item, env)
+	case ArithOperand:
+		left, ok, err := evalOperand(o.Left, item, env)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			return nil, false, evalErrorf("The provided expression refers to an attribute that does not exist in the item")
+		}
+
+		right, ok, err := evalOperand(o.Right, item, env)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			return nil, false, evalErrorf("The provided expression refers to an attribute that does not exist in the item")
+		}
+
+		x, okX := left.(*types.AttributeValueMemberN)
+		y, okY := right.(*types.AttributeValueMemberN)
+		if !okX || !okY {
+			return nil, false, evalErrorf("An operand in the update expression has an incorrect data type")
+		}
+
+		nx, err := ParseNumber(x.Value)
+		if err != nil {
+			return nil, false, evalErrorf("%s", err)
+		}
+
+		ny, err := ParseNumber(y.Value)
+		if err != nil {
+			return nil, false, evalErrorf("%s", err)
+		}
+
+		if o.Op == "-" {
+			ny.Neg(ny)
+		}
+
+		return &types.AttributeValueMemberN{Value: FormatNumber(nx.Add(nx, ny))}, true, nil
+	}
+
+	return nil, false, evalErrorf("unsupported operand %T", o)
+}
+
+func evalFuncOperand(o FuncOperand, item map[string]types.AttributeValue, env Env) (types.AttributeValue, bool, error) {
+	switch o.Name {
+	case "size":
+		v, ok, err := evalOperand(o.Args[0], item, env)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+
+		var size int
+		switch x := v.(type) {
+		case *types.AttributeValueMemberS:
+			size = len(x.Value)
+		case *types.AttributeValueMemberB:
+			size = len(x.Value)
+		case *types.AttributeValueMemberSS, *types.AttributeValueMemberNS, *types.AttributeValueMemberBS:
+			size = len(setElements(v))
+		case *types.AttributeValueMemberL:
+			size = len(x.Value)
+		case *types.AttributeValueMemberM:
+			size = len(x.Value)
+		default:
+			return nil, false, nil
+		}
+
+		return &types.AttributeValueMemberN{Value: strconv.Itoa(size)}, true, nil
+	case "if_not_exists":
+		v, ok, err := evalOperand(o.Args[0], item, env)
+		if err != nil || ok {
+			return v, ok, err
+		}
+
+		return evalOperand(o.Args[1], item, env)
+	case "list_append":
+		var list []types.AttributeValue
+		for _, arg := range o.Args {
+			v, ok, err := evalOperand(arg, item, env)
+			if err != nil {
+				return nil, false, err
+			}
+			if !ok {
+				return nil, false, evalErrorf("The provided expression refers to an attribute that does not exist in the item")
+			}
+
+			l, ok := v.(*types.AttributeValueMemberL)
+			if !ok {
+				return nil, false, evalErrorf("An operand in the update expression has an incorrect data type")
+			}
+			list = append(list, l.Value...)
+		}
+
+		return &types.AttributeValueMemberL{Value: list}, true, nil
+	}
+
+	return nil, false, evalErrorf("unsupported function %s", o.Name)
+}
+
+// Project returns a copy of the item restricted to the provided paths.
+func Project(item map[string]types.AttributeValue, paths []Path, env Env) (map[string]types.AttributeValue, error) {
+	root := &projection{}
+	for _, p := range paths {
+		resolved, err := env.Resolve(p)
+		if err != nil {
+			return nil, err
+		}
+
+		v, ok := Get(item, resolved)
+		if !ok {
+			continue
+		}
+
+		root.insert(resolved, v)
+	}
+
+	if root.fields == nil {
+		return map[string]types.AttributeValue{}, nil
+	}
+
+	return root.value().(*types.AttributeValueMemberM).Value, nil
+}
+
+// projection the tree of the projected values.
+type projection struct {
+	leaf    types.AttributeValue
+	fields  map[string]*projection
+	indexes map[int]*projection
+}
+
+func (n *projection) insert(p Path, v types.AttributeValue) {
+	if len(p) == 0 {
+		n.leaf = Copy(v)
+		return
+	}
+
+	if n.leaf != nil {
+		// the parent is already projected as a whole
+		return
+	}
+
+	var child *projection
+	if p[0].IsIndex {
+		if n.indexes == nil {
+			n.indexes = make(map[int]*projection)
+		}
+
+		if child = n.indexes[p[0].Index]; child == nil {
+			child = &projection{}
+			n.indexes[p[0].Index] = child
+		}
+	} else {
+		if n.fields == nil {
+			n.fields = make(map[string]*projection)
+		}
+
+		if child = n.fields[p[0].Name]; child == nil {
+			child = &projection{}
+			n.fields[p[0].Name] = child
+		}
+	}
+
+	child.insert(p[1:], v)
+}
+
+func (n *projection) value() types.AttributeValue {
+	if n.leaf != nil {
+		return n.leaf
+	}
+
+	if n.indexes != nil {
+		indexes := make([]int, 0, len(n.indexes))
+		for i := range n.indexes {
+			indexes = append(indexes, i)
+		}
+		sort.Ints(indexes)
+
+		list := make([]types.AttributeValue, 0, len(indexes))
+		for _, i := range indexes {
+			list = append(list, n.indexes[i].value())
+		}
+
+		return &types.AttributeValueMemberL{Value: list}
+	}
+
+	m := make(map[string]types.AttributeValue, len(n.fields))
+	for name, child := range n.fields {
+		m[name] = child.value()
+	}
+
+	return &types.AttributeValueMemberM{Value: m}
+}
@@ -0,0 +1,118 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/expr"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func testItem() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk":    &types.AttributeValueMemberS{Value: "user#1"},
+		"age":   &types.AttributeValueMemberN{Value: "42"},
+		"tags":  &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"empty": &types.AttributeValueMemberNULL{Value: true},
+		"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"city": &types.AttributeValueMemberS{Value: "Paris"},
+		}},
+		"list": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberN{Value: "1"},
+			&types.AttributeValueMemberS{Value: "two"},
+		}},
+	}
+}
+
+func TestEval(t *testing.T) {
+	env := expr.Env{
+		Names: map[string]string{"#age": "age", "#addr": "address"},
+		Values: map[string]types.AttributeValue{
+			":n40":   &types.AttributeValueMemberN{Value: "40"},
+			":n42":   &types.AttributeValueMemberN{Value: "42.0"},
+			":n50":   &types.AttributeValueMemberN{Value: "5e1"},
+			":user":  &types.AttributeValueMemberS{Value: "user#"},
+			":paris": &types.AttributeValueMemberS{Value: "Paris"},
+			":a":     &types.AttributeValueMemberS{Value: "a"},
+			":two":   &types.AttributeValueMemberS{Value: "two"},
+			":SS":    &types.AttributeValueMemberS{Value: "SS"},
+			":2":     &types.AttributeValueMemberN{Value: "2"},
+		},
+	}
+
+	cases := []struct {
+		in  string
+		out bool
+	}{
+		{in: "#age = :n42", out: true},
+		{in: "#age <> :n42", out: false},
+		{in: "#age > :n40 AND #age < :n50", out: true},
+		{in: "#age BETWEEN :n40 AND :n50", out: true},
+		{in: "#age IN (:n40, :n50)", out: false},
+		{in: "begins_with(pk, :user)", out: true},
+		{in: "#addr.city = :paris", out: true},
+		{in: "contains(tags, :a)", out: true},
+		{in: "contains(list, :two)", out: true},
+		{in: "attribute_type(tags, :SS)", out: true},
+		{in: "size(list) = :2 AND size(tags) = :2", out: true},
+		{in: "attribute_exists(list[1]) AND attribute_not_exists(list[2])", out: true},
+		{in: "missing = :a", out: false},
+		{in: "missing <> :a", out: true},
+		{in: "NOT (pk < :a OR #age >= :n50)", out: true},
+		{in: "pk > :n40", out: false},
+	}
+
+	for _, tc := range cases {
+		c, err := expr.ParseCondition(tc.in)
+		if !assert.NoError(t, err, tc.in) {
+			continue
+		}
+
+		ok, err := expr.Eval(c, testItem(), env)
+		assert.NoError(t, err, tc.in)
+		assert.Equal(t, tc.out, ok, tc.in)
+	}
+
+	for _, in := range []string{"#unknown = :n40", "age = :unknown", "age BETWEEN :n50 AND :n40"} {
+		c, err := expr.ParseCondition(in)
+		if assert.NoError(t, err, in) {
+			_, err = expr.Eval(c, testItem(), env)
+			assert.Error(t, err, in)
+		}
+	}
+}
+
+func TestProject(t *testing.T) {
+	paths, err := expr.ParseProjection("pk, #addr.city, list[1], missing")
+	assert.NoError(t, err)
+
+	out, err := expr.Project(testItem(), paths, expr.Env{Names: map[string]string{"#addr": "address"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "user#1"},
+		"address": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"city": &types.AttributeValueMemberS{Value: "Paris"},
+		}},
+		"list": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "two"},
+		}},
+	}, out)
+}
+
+func TestValues(t *testing.T) {
+	assert.True(t, expr.Equal(&types.AttributeValueMemberN{Value: "1.50"}, &types.AttributeValueMemberN{Value: "1.5"}))
+	assert.True(t, expr.Equal(&types.AttributeValueMemberSS{Value: []string{"a", "b"}}, &types.AttributeValueMemberSS{Value: []string{"b", "a"}}))
+	assert.False(t, expr.Equal(&types.AttributeValueMemberS{Value: "1"}, &types.AttributeValueMemberN{Value: "1"}))
+
+	n, err := expr.ParseNumber("1.1")
+	assert.NoError(t, err)
+	m, err := expr.ParseNumber("2.2")
+	assert.NoError(t, err)
+	assert.Equal(t, "3.3", expr.FormatNumber(n.Add(n, m)))
+
+	item := testItem()
+	c := expr.CopyItem(item)
+	c["address"].(*types.AttributeValueMemberM).Value["city"] = &types.AttributeValueMemberS{Value: "Lyon"}
+	assert.True(t, expr.Equal(&types.AttributeValueMemberS{Value: "Paris"}, item["address"].(*types.AttributeValueMemberM).Value["city"]))
+}
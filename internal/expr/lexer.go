@@ -0,0 +1,113 @@
+// Package expr parses and evaluates the dynamodb expressions
+// (key condition, filter, condition, projection and update expressions).
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TokenKind the kind of a lexical token.
+type TokenKind int
+
+const (
+	// TokenEOF the end of the expression.
+	TokenEOF TokenKind = iota
+	// TokenIdent an attribute name, a keyword or a function name.
+	TokenIdent
+	// TokenNameRef an expression attribute name placeholder (e.g. #name).
+	TokenNameRef
+	// TokenValueRef an expression attribute value placeholder (e.g. :value).
+	TokenValueRef
+	// TokenNumber a list index.
+	TokenNumber
+	// TokenSymbol a punctuation or an operator (e.g. "(", "<=", "+").
+	TokenSymbol
+)
+
+// Token a lexical token.
+type Token struct {
+	Kind TokenKind
+	Text string
+	// Pos the byte offset of the token in the expression.
+	Pos int
+}
+
+// SyntaxError an invalid expression.
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// Lex splits an expression into tokens. The last token is always a TokenEOF.
+func Lex(s string) ([]Token, error) {
+	var tokens []Token
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || c == ':':
+			j := i + 1
+			for j < len(s) && isIdentChar(s[j]) {
+				j++
+			}
+
+			if j == i+1 {
+				return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("empty placeholder %q", c)}
+			}
+
+			kind := TokenNameRef
+			if c == ':' {
+				kind = TokenValueRef
+			}
+
+			tokens = append(tokens, Token{Kind: kind, Text: s[i:j], Pos: i})
+			i = j
+		case isDigit(c):
+			j := i
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+
+			tokens = append(tokens, Token{Kind: TokenNumber, Text: s[i:j], Pos: i})
+			i = j
+		case isIdentStart(c):
+			j := i
+			for j < len(s) && isIdentChar(s[j]) {
+				j++
+			}
+
+			tokens = append(tokens, Token{Kind: TokenIdent, Text: s[i:j], Pos: i})
+			i = j
+		case strings.HasPrefix(s[i:], "<>") || strings.HasPrefix(s[i:], "<=") || strings.HasPrefix(s[i:], ">="):
+			tokens = append(tokens, Token{Kind: TokenSymbol, Text: s[i : i+2], Pos: i})
+			i += 2
+		case strings.IndexByte("()[],.=<>+-", c) >= 0:
+			tokens = append(tokens, Token{Kind: TokenSymbol, Text: s[i : i+1], Pos: i})
+			i++
+		default:
+			return nil, &SyntaxError{Pos: i, Msg: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+
+	return append(tokens, Token{Kind: TokenEOF, Pos: len(s)}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
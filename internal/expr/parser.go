@@ -0,0 +1,504 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the number of arguments of the functions
+var conditionFunctions = map[string]int{
+	"attribute_exists":     1,
+	"attribute_not_exists": 1,
+	"attribute_type":       2,
+	"begins_with":          2,
+	"contains":             2,
+}
+
+var comparators = map[string]bool{"=": true, "<>": true, "<": true, "<=": true, ">": true, ">=": true}
+
+type parser struct {
+	tokens []Token
+	pos    int
+}
+
+// ParseCondition parses a key condition, a filter or a condition expression.
+func ParseCondition(s string) (Condition, error) {
+	p, err := newParser(s)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expectEOF(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ParseProjection parses a projection expression.
+func ParseProjection(s string) ([]Path, error) {
+	p, err := newParser(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []Path
+	for {
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if err := p.expectEOF(); err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// ParseUpdate parses an update expression.
+func ParseUpdate(s string) (*Update, error) {
+	p, err := newParser(s)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &Update{}
+	seen := make(map[string]bool)
+
+	for p.peek().Kind != TokenEOF {
+		tok := p.next()
+		keyword := strings.ToUpper(tok.Text)
+		if tok.Kind != TokenIdent || (keyword != "SET" && keyword != "REMOVE" && keyword != "ADD" && keyword != "DELETE") {
+			return nil, p.errorf(tok, "expected SET, REMOVE, ADD or DELETE, got %q", tok.Text)
+		}
+
+		if seen[keyword] {
+			return nil, p.errorf(tok, "the %s clause is specified more than once", keyword)
+		}
+		seen[keyword] = true
+
+		for {
+			if err := p.parseAction(u, keyword); err != nil {
+				return nil, err
+			}
+
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+
+	if len(seen) == 0 {
+		return nil, &SyntaxError{Pos: 0, Msg: "empty update expression"}
+	}
+
+	return u, nil
+}
+
+func newParser(s string) (*parser, error) {
+	tokens, err := Lex(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parser{tokens: tokens}, nil
+}
+
+func (p *parser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) Token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() Token {
+	tok := p.tokens[p.pos]
+	if tok.Kind != TokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+func (p *parser) acceptSymbol(symbol string) bool {
+	if tok := p.peek(); tok.Kind == TokenSymbol && tok.Text == symbol {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if tok := p.peek(); tok.Kind == TokenIdent && strings.EqualFold(tok.Text, keyword) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return p.errorf(p.peek(), "expected %q, got %q", symbol, p.peek().Text)
+	}
+
+	return nil
+}
+
+func (p *parser) expectEOF() error {
+	if tok := p.peek(); tok.Kind != TokenEOF {
+		return p.errorf(tok, "unexpected token %q", tok.Text)
+	}
+
+	return nil
+}
+
+func (p *parser) errorf(tok Token, format string, args ...interface{}) error {
+	return &SyntaxError{Pos: tok.Pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (Condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = And{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseNot() (Condition, error) {
+	if p.acceptKeyword("NOT") {
+		c, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return Not{Condition: c}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Condition, error) {
+	if p.acceptSymbol("(") {
+		c, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+
+		return c, nil
+	}
+
+	tok := p.peek()
+	if tok.Kind == TokenIdent && p.peekAt(1).Text == "(" {
+		if n, ok := conditionFunctions[strings.ToLower(tok.Text)]; ok {
+			p.next()
+			args, err := p.parseArgs(strings.ToLower(tok.Text), n, p.parseOperand)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := args[0].(PathOperand); !ok {
+				return nil, p.errorf(tok, "the first argument of %s must be a document path", tok.Text)
+			}
+
+			return FuncCondition{Name: strings.ToLower(tok.Text), Args: args}, nil
+		}
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.peek()
+	switch {
+	case op.Kind == TokenSymbol && comparators[op.Text]:
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		return Compare{Op: op.Text, Left: left, Right: right}, nil
+	case p.acceptKeyword("BETWEEN"):
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.acceptKeyword("AND") {
+			return nil, p.errorf(p.peek(), "expected AND, got %q", p.peek().Text)
+		}
+
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		return Between{Operand: left, Low: low, High: high}, nil
+	case p.acceptKeyword("IN"):
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+
+		var list []Operand
+		for {
+			o, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, o)
+
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+
+		return In{Operand: left, List: list}, nil
+	}
+
+	return nil, p.errorf(op, "expected a comparator, BETWEEN or IN, got %q", op.Text)
+}
+
+// parseOperand parses a condition operand: a path, a value or size(path).
+func (p *parser) parseOperand() (Operand, error) {
+	tok := p.peek()
+
+	switch {
+	case tok.Kind == TokenValueRef:
+		p.next()
+		return ValueOperand{Name: tok.Text}, nil
+	case tok.Kind == TokenIdent && strings.EqualFold(tok.Text, "size") && p.peekAt(1).Text == "(":
+		p.next()
+		args, err := p.parseArgs("size", 1, p.parsePathOperand)
+		if err != nil {
+			return nil, err
+		}
+
+		return FuncOperand{Name: "size", Args: args}, nil
+	}
+
+	return p.parsePathOperand()
+}
+
+func (p *parser) parsePathOperand() (Operand, error) {
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+
+	return PathOperand{Path: path}, nil
+}
+
+func (p *parser) parseArgs(name string, n int, parse func() (Operand, error)) ([]Operand, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	args := make([]Operand, 0, n)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			if err := p.expectSymbol(","); err != nil {
+				return nil, err
+			}
+		}
+
+		arg, err := parse()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, fmt.Errorf("%s expects %d argument(s): %w", name, n, err)
+	}
+
+	return args, nil
+}
+
+func (p *parser) parsePath() (Path, error) {
+	tok := p.next()
+	if tok.Kind != TokenIdent && tok.Kind != TokenNameRef {
+		return nil, p.errorf(tok, "expected an attribute name, got %q", tok.Text)
+	}
+
+	path := Path{{Name: tok.Text}}
+	for {
+		switch {
+		case p.acceptSymbol("."):
+			tok := p.next()
+			if tok.Kind != TokenIdent && tok.Kind != TokenNameRef {
+				return nil, p.errorf(tok, "expected an attribute name, got %q", tok.Text)
+			}
+			path = append(path, PathElement{Name: tok.Text})
+		case p.acceptSymbol("["):
+			tok := p.next()
+			if tok.Kind != TokenNumber {
+				return nil, p.errorf(tok, "expected a list index, got %q", tok.Text)
+			}
+
+			index, err := strconv.Atoi(tok.Text)
+			if err != nil {
+				return nil, p.errorf(tok, "invalid list index %q", tok.Text)
+			}
+
+			if err := p.expectSymbol("]"); err != nil {
+				return nil, err
+			}
+			path = append(path, PathElement{Index: index, IsIndex: true})
+		default:
+			return path, nil
+		}
+	}
+}
+
+func (p *parser) parseAction(u *Update, keyword string) error {
+	path, err := p.parsePath()
+	if err != nil {
+		return err
+	}
+
+	switch keyword {
+	case "SET":
+		if err := p.expectSymbol("="); err != nil {
+			return err
+		}
+
+		value, err := p.parseSetValue()
+		if err != nil {
+			return err
+		}
+		u.Set = append(u.Set, SetAction{Path: path, Value: value})
+	case "REMOVE":
+		u.Remove = append(u.Remove, path)
+	default:
+		tok := p.next()
+		if tok.Kind != TokenValueRef {
+			return p.errorf(tok, "%s expects a value, got %q", keyword, tok.Text)
+		}
+
+		action := PathValueAction{Path: path, Value: ValueOperand{Name: tok.Text}}
+		if keyword == "ADD" {
+			u.Add = append(u.Add, action)
+		} else {
+			u.Delete = append(u.Delete, action)
+		}
+	}
+
+	return nil
+}
+
+// parseSetValue parses the value of a SET action: operand [(+|-) operand].
+func (p *parser) parseSetValue() (Operand, error) {
+	left, err := p.parseSetOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"+", "-"} {
+		if p.acceptSymbol(op) {
+			right, err := p.parseSetOperand()
+			if err != nil {
+				return nil, err
+			}
+
+			return ArithOperand{Op: op, Left: left, Right: right}, nil
+		}
+	}
+
+	return left, nil
+}
+
+// parseSetOperand parses a path, a value, if_not_exists(path, operand) or list_append(operand, operand).
+func (p *parser) parseSetOperand() (Operand, error) {
+	tok := p.peek()
+
+	if tok.Kind == TokenIdent && p.peekAt(1).Text == "(" {
+		name := strings.ToLower(tok.Text)
+		switch name {
+		case "if_not_exists":
+			p.next()
+			args, err := p.parseArgs(name, 2, p.parseSetOperand)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := args[0].(PathOperand); !ok {
+				return nil, p.errorf(tok, "the first argument of if_not_exists must be a document path")
+			}
+
+			return FuncOperand{Name: name, Args: args}, nil
+		case "list_append":
+			p.next()
+			args, err := p.parseArgs(name, 2, p.parseSetOperand)
+			if err != nil {
+				return nil, err
+			}
+
+			return FuncOperand{Name: name, Args: args}, nil
+		}
+
+		return nil, p.errorf(tok, "invalid function %q in update expression", tok.Text)
+	}
+
+	if tok.Kind == TokenValueRef {
+		p.next()
+		return ValueOperand{Name: tok.Text}, nil
+	}
+
+	return p.parsePathOperand()
+}
@@ -0,0 +1,82 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/expr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLex(t *testing.T) {
+	tokens, err := expr.Lex("#0 = :0 AND a.b[1] <> :v1")
+	assert.NoError(t, err)
+
+	kinds := make([]expr.TokenKind, 0, len(tokens))
+	texts := make([]string, 0, len(tokens))
+	for _, tok := range tokens {
+		kinds = append(kinds, tok.Kind)
+		texts = append(texts, tok.Text)
+	}
+
+	assert.Equal(t, []expr.TokenKind{
+		expr.TokenNameRef, expr.TokenSymbol, expr.TokenValueRef, expr.TokenIdent,
+		expr.TokenIdent, expr.TokenSymbol, expr.TokenIdent, expr.TokenSymbol, expr.TokenNumber, expr.TokenSymbol,
+		expr.TokenSymbol, expr.TokenValueRef, expr.TokenEOF,
+	}, kinds)
+	assert.Equal(t, []string{"#0", "=", ":0", "AND", "a", ".", "b", "[", "1", "]", "<>", ":v1", ""}, texts)
+
+	_, err = expr.Lex("a = :")
+	assert.Error(t, err)
+
+	_, err = expr.Lex("a ; b")
+	assert.Error(t, err)
+}
+
+func TestParseCondition(t *testing.T) {
+	cases := []struct {
+		in  string
+		out string
+	}{
+		{in: "#0 = :0", out: "#0 = :0"},
+		{in: "(#0 = :0) AND (#1 BETWEEN :1 AND :2)", out: "(#0 = :0 AND #1 BETWEEN :1 AND :2)"},
+		{in: "a = :a OR b = :b AND c = :c", out: "(a = :a OR (b = :b AND c = :c))"},
+		{in: "NOT attribute_exists(#a.b[2])", out: "NOT attribute_exists(#a.b[2])"},
+		{in: "begins_with(#sk, :p) and size(#l) >= :n", out: "(begins_with(#sk, :p) AND size(#l) >= :n)"},
+		{in: "#s IN (:a, :b, :c)", out: "#s IN (:a, :b, :c)"},
+		{in: "contains(tags, :t)", out: "contains(tags, :t)"},
+	}
+
+	for _, tc := range cases {
+		c, err := expr.ParseCondition(tc.in)
+		if assert.NoError(t, err, tc.in) {
+			assert.Equal(t, tc.out, c.String())
+		}
+	}
+
+	for _, in := range []string{"", "#0 =", "#0 = :0 AND", "(#0 = :0", "#0 BETWEEN :1", "attribute_exists(:v)", "#0 :0", "size(#a)"} {
+		_, err := expr.ParseCondition(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParseProjection(t *testing.T) {
+	paths, err := expr.ParseProjection("#0, a.b, c[1].d")
+	assert.NoError(t, err)
+	assert.Len(t, paths, 3)
+	assert.Equal(t, "c[1].d", paths[2].String())
+
+	_, err = expr.ParseProjection("a,")
+	assert.Error(t, err)
+}
+
+func TestParseUpdate(t *testing.T) {
+	u, err := expr.ParseUpdate("SET #0 = :0, #1 = if_not_exists(#1, :1) + :2, l = list_append(l, :l)\nREMOVE a, b[0] ADD n :n DELETE s :s")
+	assert.NoError(t, err)
+	assert.Equal(t, "SET #0 = :0, #1 = if_not_exists(#1, :1) + :2, l = list_append(l, :l) REMOVE a, b[0] ADD n :n DELETE s :s", u.String())
+
+	for _, in := range []string{"", "SET a", "SET a = :a SET b = :b", "ADD a b", "UPSERT a = :a", "SET a = foo(:a)"} {
+		_, err := expr.ParseUpdate(in)
+		assert.Error(t, err, in)
+	}
+}
@@ -0,0 +1,207 @@
+package expr
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// substituter substitutes the placeholders of an expression: the #name placeholders by the attribute names
+// and the :value placeholders by the rendering of the values.
+type substituter struct {
+	env   Env
+	value func(types.AttributeValue) string
+}
+
+// SubstituteCondition returns a copy of a condition with the placeholders substituted, the values being
+// rendered by value. The String of the result is a canonical form of the condition.
+func SubstituteCondition(c Condition, env Env, value func(types.AttributeValue) string) (Condition, error) {
+	s := substituter{env: env, value: value}
+	return s.condition(c)
+}
+
+// SubstitutePaths returns a copy of projection paths with the #name placeholders substituted.
+func SubstitutePaths(paths []Path, env Env) ([]Path, error) {
+	resolved := make([]Path, 0, len(paths))
+	for _, p := range paths {
+		r, err := env.Resolve(p)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, r)
+	}
+
+	return resolved, nil
+}
+
+// SubstituteUpdate returns a copy of an update with the placeholders substituted, the values being rendered by value.
+func SubstituteUpdate(u *Update, env Env, value func(types.AttributeValue) string) (*Update, error) {
+	s := substituter{env: env, value: value}
+
+	var err error
+	result := &Update{}
+	for _, a := range u.Set {
+		var set SetAction
+		if set.Path, err = env.Resolve(a.Path); err != nil {
+			return nil, err
+		}
+		if set.Value, err = s.operand(a.Value); err != nil {
+			return nil, err
+		}
+		result.Set = append(result.Set, set)
+	}
+
+	if result.Remove, err = SubstitutePaths(u.Remove, env); err != nil {
+		return nil, err
+	}
+	if len(result.Remove) == 0 {
+		result.Remove = nil
+	}
+
+	if result.Add, err = s.actions(u.Add); err != nil {
+		return nil, err
+	}
+
+	if result.Delete, err = s.actions(u.Delete); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s substituter) actions(actions []PathValueAction) ([]PathValueAction, error) {
+	var result []PathValueAction
+	for _, a := range actions {
+		p, err := s.env.Resolve(a.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := s.operand(a.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, PathValueAction{Path: p, Value: v})
+	}
+
+	return result, nil
+}
+
+func (s substituter) condition(c Condition) (Condition, error) {
+	switch c := c.(type) {
+	case Compare:
+		left, err := s.operand(c.Left)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := s.operand(c.Right)
+		if err != nil {
+			return nil, err
+		}
+
+		return Compare{Op: c.Op, Left: left, Right: right}, nil
+
+	case Between:
+		operands, err := s.operands([]Operand{c.Operand, c.Low, c.High})
+		if err != nil {
+			return nil, err
+		}
+
+		return Between{Operand: operands[0], Low: operands[1], High: operands[2]}, nil
+
+	case In:
+		operands, err := s.operands(append([]Operand{c.Operand}, c.List...))
+		if err != nil {
+			return nil, err
+		}
+
+		return In{Operand: operands[0], List: operands[1:]}, nil
+
+	case FuncCondition:
+		args, err := s.operands(c.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		return FuncCondition{Name: c.Name, Args: args}, nil
+
+	case And:
+		left, right, err := s.pair(c.Left, c.Right)
+		return And{Left: left, Right: right}, err
+
+	case Or:
+		left, right, err := s.pair(c.Left, c.Right)
+		return Or{Left: left, Right: right}, err
+
+	case Not:
+		inner, err := s.condition(c.Condition)
+		return Not{Condition: inner}, err
+	}
+
+	return c, nil
+}
+
+func (s substituter) pair(l, r Condition) (Condition, Condition, error) {
+	left, err := s.condition(l)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	right, err := s.condition(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return left, right, nil
+}
+
+func (s substituter) operands(operands []Operand) ([]Operand, error) {
+	result := make([]Operand, 0, len(operands))
+	for _, o := range operands {
+		r, err := s.operand(o)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+
+	return result, nil
+}
+
+func (s substituter) operand(o Operand) (Operand, error) {
+	switch o := o.(type) {
+	case PathOperand:
+		p, err := s.env.Resolve(o.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return PathOperand{Path: p}, nil
+
+	case ValueOperand:
+		v, err := s.env.Value(o.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		return ValueOperand{Name: s.value(v)}, nil
+
+	case FuncOperand:
+		args, err := s.operands(o.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		return FuncOperand{Name: o.Name, Args: args}, nil
+
+	case ArithOperand:
+		operands, err := s.operands([]Operand{o.Left, o.Right})
+		if err != nil {
+			return nil, err
+		}
+
+		return ArithOperand{Op: o.Op, Left: operands[0], Right: operands[1]}, nil
+	}
+
+	return o, nil
+}
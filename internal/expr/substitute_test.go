@@ -0,0 +1,63 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/expr"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubstitute(t *testing.T) {
+	env := expr.Env{
+		Names: map[string]string{"#a": "age", "#t": "tags"},
+		Values: map[string]types.AttributeValue{
+			":lo": &types.AttributeValueMemberN{Value: "1"},
+			":hi": &types.AttributeValueMemberN{Value: "9"},
+			":s":  &types.AttributeValueMemberS{Value: "x"},
+		},
+	}
+	render := func(av types.AttributeValue) string {
+		switch av := av.(type) {
+		case *types.AttributeValueMemberN:
+			return av.Value
+		case *types.AttributeValueMemberS:
+			return "'" + av.Value + "'"
+		}
+		return "?"
+	}
+
+	c, err := expr.ParseCondition("#a BETWEEN :lo AND :hi AND (contains(#t, :s) OR NOT name IN (:s, :lo)) AND size(#t) > :lo")
+	require.NoError(t, err)
+
+	c, err = expr.SubstituteCondition(c, env, render)
+	require.NoError(t, err)
+	assert.Equal(t, "((age BETWEEN 1 AND 9 AND (contains(tags, 'x') OR NOT name IN ('x', 1))) AND size(tags) > 1)", c.String())
+
+	u, err := expr.ParseUpdate("SET #a = #a + :lo, n = if_not_exists(n, :s) REMOVE #t[0] ADD c :lo DELETE #t :s")
+	require.NoError(t, err)
+
+	u, err = expr.SubstituteUpdate(u, env, render)
+	require.NoError(t, err)
+	assert.Equal(t, "SET age = age + 1, n = if_not_exists(n, 'x') REMOVE tags[0] ADD c 1 DELETE tags 'x'", u.String())
+
+	paths, err := expr.ParseProjection("#a, #t[1].x")
+	require.NoError(t, err)
+
+	paths, err = expr.SubstitutePaths(paths, env)
+	require.NoError(t, err)
+	assert.Equal(t, "age", paths[0].String())
+	assert.Equal(t, "tags[1].x", paths[1].String())
+
+	c, err = expr.ParseCondition("#missing = :s")
+	require.NoError(t, err)
+	_, err = expr.SubstituteCondition(c, env, render)
+	assert.Error(t, err)
+
+	c, err = expr.ParseCondition("age = :missing")
+	require.NoError(t, err)
+	_, err = expr.SubstituteCondition(c, env, render)
+	assert.Error(t, err)
+}
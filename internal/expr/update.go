@@ -0,0 +1,292 @@
+package expr
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Apply applies an update expression to a copy of the item and returns the updated copy.
+// The operands are evaluated against the original item.
+func Apply(u *Update, item map[string]types.AttributeValue, env Env) (map[string]types.AttributeValue, error) {
+	updated := CopyItem(item)
+	if updated == nil {
+		updated = make(map[string]types.AttributeValue)
+	}
+
+	type assignment struct {
+		path  Path
+		value types.AttributeValue
+	}
+
+	// evaluate every operand before modifying the item
+	sets := make([]assignment, 0, len(u.Set))
+	for _, a := range u.Set {
+		p, err := env.Resolve(a.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		v, ok, err := evalOperand(a.Value, item, env)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, evalErrorf("The provided expression refers to an attribute that does not exist in the item")
+		}
+
+		sets = append(sets, assignment{path: p, value: v})
+	}
+
+	for _, a := range sets {
+		if err := set(updated, a.path, Copy(a.value)); err != nil {
+			return nil, err
+		}
+	}
+
+	removes := make([]Path, 0, len(u.Remove))
+	for _, p := range u.Remove {
+		resolved, err := env.Resolve(p)
+		if err != nil {
+			return nil, err
+		}
+		removes = append(removes, resolved)
+	}
+
+	// remove the list elements from the last one so that the indexes remain valid
+	sort.SliceStable(removes, func(i, j int) bool {
+		a, b := removes[i], removes[j]
+		if len(a) != len(b) || !a[len(a)-1].IsIndex || !b[len(b)-1].IsIndex {
+			return false
+		}
+
+		return a[len(a)-1].Index > b[len(b)-1].Index
+	})
+
+	for _, p := range removes {
+		remove(updated, p)
+	}
+
+	for _, a := range u.Add {
+		if err := applyAdd(updated, a, env); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, a := range u.Delete {
+		if err := applyDelete(updated, a, env); err != nil {
+			return nil, err
+		}
+	}
+
+	return updated, nil
+}
+
+// UpdatedPaths returns the top level attributes modified by an update expression.
+func UpdatedPaths(u *Update, env Env) ([]string, error) {
+	var paths []Path
+	for _, a := range u.Set {
+		paths = append(paths, a.Path)
+	}
+	paths = append(paths, u.Remove...)
+	for _, a := range u.Add {
+		paths = append(paths, a.Path)
+	}
+	for _, a := range u.Delete {
+		paths = append(paths, a.Path)
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range paths {
+		name, err := env.Name(p[0].Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+func invalidPath() error {
+	return evalErrorf("The document path provided in the update expression is invalid for update")
+}
+
+// parent returns the container of the last element of a path.
+func parent(item map[string]types.AttributeValue, p Path) (types.AttributeValue, bool) {
+	return Get(item, p[:len(p)-1])
+}
+
+func set(item map[string]types.AttributeValue, p Path, v types.AttributeValue) error {
+	container, ok := parent(item, p)
+	if !ok {
+		return invalidPath()
+	}
+
+	last := p[len(p)-1]
+	switch c := container.(type) {
+	case *types.AttributeValueMemberM:
+		if last.IsIndex {
+			return invalidPath()
+		}
+		c.Value[last.Name] = v
+	case *types.AttributeValueMemberL:
+		if !last.IsIndex {
+			return invalidPath()
+		}
+
+		// setting an index past the end of the list appends the value
+		if last.Index >= len(c.Value) {
+			c.Value = append(c.Value, v)
+			return nil
+		}
+		c.Value[last.Index] = v
+	default:
+		return invalidPath()
+	}
+
+	return nil
+}
+
+func remove(item map[string]types.AttributeValue, p Path) {
+	container, ok := parent(item, p)
+	if !ok {
+		return
+	}
+
+	last := p[len(p)-1]
+	switch c := container.(type) {
+	case *types.AttributeValueMemberM:
+		if !last.IsIndex {
+			delete(c.Value, last.Name)
+		}
+	case *types.AttributeValueMemberL:
+		if last.IsIndex && last.Index < len(c.Value) {
+			c.Value = append(c.Value[:last.Index], c.Value[last.Index+1:]...)
+		}
+	}
+}
+
+func applyAdd(item map[string]types.AttributeValue, a PathValueAction, env Env) error {
+	p, err := env.Resolve(a.Path)
+	if err != nil {
+		return err
+	}
+
+	v, _, err := evalOperand(a.Value, item, env)
+	if err != nil {
+		return err
+	}
+
+	current, exists := Get(item, p)
+	if !exists {
+		switch v.(type) {
+		case *types.AttributeValueMemberN, *types.AttributeValueMemberSS,
+			*types.AttributeValueMemberNS, *types.AttributeValueMemberBS:
+			return set(item, p, Copy(v))
+		}
+
+		return evalErrorf("Incorrect operand type for operator or function; operator: ADD, operand type: %s", TypeOf(v))
+	}
+
+	if TypeOf(current) != TypeOf(v) {
+		return evalErrorf("An operand in the update expression has an incorrect data type")
+	}
+
+	switch x := current.(type) {
+	case *types.AttributeValueMemberN:
+		nx, err := ParseNumber(x.Value)
+		if err != nil {
+			return evalErrorf("%s", err)
+		}
+
+		ny, err := ParseNumber(v.(*types.AttributeValueMemberN).Value)
+		if err != nil {
+			return evalErrorf("%s", err)
+		}
+
+		return set(item, p, &types.AttributeValueMemberN{Value: FormatNumber(nx.Add(nx, ny))})
+	case *types.AttributeValueMemberSS, *types.AttributeValueMemberNS, *types.AttributeValueMemberBS:
+		elements := setElements(current)
+		for _, e := range setElements(v) {
+			if !containsValue(elements, e) {
+				elements = append(elements, e)
+			}
+		}
+
+		return set(item, p, newSet(TypeOf(current), elements))
+	}
+
+	return evalErrorf("Incorrect operand type for operator or function; operator: ADD, operand type: %s", TypeOf(v))
+}
+
+func applyDelete(item map[string]types.AttributeValue, a PathValueAction, env Env) error {
+	p, err := env.Resolve(a.Path)
+	if err != nil {
+		return err
+	}
+
+	v, _, err := evalOperand(a.Value, item, env)
+	if err != nil {
+		return err
+	}
+
+	switch v.(type) {
+	case *types.AttributeValueMemberSS, *types.AttributeValueMemberNS, *types.AttributeValueMemberBS:
+	default:
+		return evalErrorf("Incorrect operand type for operator or function; operator: DELETE, operand type: %s", TypeOf(v))
+	}
+
+	current, exists := Get(item, p)
+	if !exists {
+		return nil
+	}
+
+	if TypeOf(current) != TypeOf(v) {
+		return evalErrorf("An operand in the update expression has an incorrect data type")
+	}
+
+	removed := setElements(v)
+	var elements []types.AttributeValue
+	for _, e := range setElements(current) {
+		if !containsValue(removed, e) {
+			elements = append(elements, e)
+		}
+	}
+
+	// empty sets are not allowed
+	if len(elements) == 0 {
+		remove(item, p)
+		return nil
+	}
+
+	return set(item, p, newSet(TypeOf(current), elements))
+}
+
+func newSet(setType string, elements []types.AttributeValue) types.AttributeValue {
+	switch setType {
+	case "SS":
+		s := make([]string, 0, len(elements))
+		for _, e := range elements {
+			s = append(s, e.(*types.AttributeValueMemberS).Value)
+		}
+		return &types.AttributeValueMemberSS{Value: s}
+	case "NS":
+		s := make([]string, 0, len(elements))
+		for _, e := range elements {
+			s = append(s, e.(*types.AttributeValueMemberN).Value)
+		}
+		return &types.AttributeValueMemberNS{Value: s}
+	default:
+		s := make([][]byte, 0, len(elements))
+		for _, e := range elements {
+			s = append(s, e.(*types.AttributeValueMemberB).Value)
+		}
+		return &types.AttributeValueMemberBS{Value: s}
+	}
+}
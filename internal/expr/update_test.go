@@ -0,0 +1,63 @@
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/expr"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	env := expr.Env{
+		Names: map[string]string{"#age": "age", "#new": "new"},
+		Values: map[string]types.AttributeValue{
+			":one":  &types.AttributeValueMemberN{Value: "1"},
+			":x":    &types.AttributeValueMemberS{Value: "x"},
+			":l":    &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "three"}}},
+			":tags": &types.AttributeValueMemberSS{Value: []string{"b", "c"}},
+			":del":  &types.AttributeValueMemberSS{Value: []string{"a", "b", "c"}},
+		},
+	}
+
+	u, err := expr.ParseUpdate("SET #age = #age + :one, #new = if_not_exists(#new, :x), address.zip = :x, list = list_append(list, :l) REMOVE empty, list[0] ADD tags :tags, counter :one")
+	assert.NoError(t, err)
+
+	item := testItem()
+	out, err := expr.Apply(u, item, env)
+	assert.NoError(t, err)
+
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "43"}, out["age"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "x"}, out["new"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "x"}, out["address"].(*types.AttributeValueMemberM).Value["zip"])
+	assert.Equal(t, &types.AttributeValueMemberL{Value: []types.AttributeValue{
+		&types.AttributeValueMemberS{Value: "two"},
+		&types.AttributeValueMemberS{Value: "three"},
+	}}, out["list"])
+	assert.NotContains(t, out, "empty")
+	assert.True(t, expr.Equal(&types.AttributeValueMemberSS{Value: []string{"a", "b", "c"}}, out["tags"]))
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, out["counter"])
+
+	// the original item is not modified
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "42"}, item["age"])
+	assert.Contains(t, item, "empty")
+
+	names, err := expr.UpdatedPaths(u, env)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"age", "new", "address", "list", "empty", "tags", "counter"}, names)
+
+	u, err = expr.ParseUpdate("DELETE tags :del")
+	assert.NoError(t, err)
+	out, err = expr.Apply(u, item, env)
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "tags")
+
+	for _, in := range []string{"SET a = missing", "SET a = pk + :one", "SET missing.a = :x", "ADD pk :one", "DELETE tags :x"} {
+		u, err := expr.ParseUpdate(in)
+		if assert.NoError(t, err, in) {
+			_, err = expr.Apply(u, testItem(), env)
+			assert.Error(t, err, in)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package expr
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TypeOf returns the dynamodb type descriptor of a value (S, N, B, SS, NS, BS, BOOL, NULL, L or M).
+func TypeOf(av types.AttributeValue) string {
+	switch av.(type) {
+	case *types.AttributeValueMemberS:
+		return "S"
+	case *types.AttributeValueMemberN:
+		return "N"
+	case *types.AttributeValueMemberB:
+		return "B"
+	case *types.AttributeValueMemberSS:
+		return "SS"
+	case *types.AttributeValueMemberNS:
+		return "NS"
+	case *types.AttributeValueMemberBS:
+		return "BS"
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL"
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberL:
+		return "L"
+	case *types.AttributeValueMemberM:
+		return "M"
+	}
+
+	return ""
+}
+
+// ParseNumber parses a dynamodb number.
+func ParseNumber(n string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(n))
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", n)
+	}
+
+	return r, nil
+}
+
+// FormatNumber formats a number with the least digits.
+func FormatNumber(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	// the numbers are sums of decimals: their denominator divides a power of 10
+	for prec := 1; prec < 64; prec++ {
+		s := r.FloatString(prec)
+		if back, ok := new(big.Rat).SetString(s); ok && back.Cmp(r) == 0 {
+			return s
+		}
+	}
+
+	return r.FloatString(64)
+}
+
+// CompareValues compares two scalar values of the same type (S, N or B).
+// ok is false if the values are not comparable.
+func CompareValues(a, b types.AttributeValue) (cmp int, ok bool) {
+	switch x := a.(type) {
+	case *types.AttributeValueMemberS:
+		if y, ok := b.(*types.AttributeValueMemberS); ok {
+			return strings.Compare(x.Value, y.Value), true
+		}
+	case *types.AttributeValueMemberN:
+		if y, ok := b.(*types.AttributeValueMemberN); ok {
+			nx, errX := ParseNumber(x.Value)
+			ny, errY := ParseNumber(y.Value)
+			if errX != nil || errY != nil {
+				return 0, false
+			}
+
+			return nx.Cmp(ny), true
+		}
+	case *types.AttributeValueMemberB:
+		if y, ok := b.(*types.AttributeValueMemberB); ok {
+			return bytes.Compare(x.Value, y.Value), true
+		}
+	}
+
+	return 0, false
+}
+
+// Equal checks whether two values are equal. Numbers are compared by value and sets regardless of their order.
+func Equal(a, b types.AttributeValue) bool {
+	if TypeOf(a) != TypeOf(b) {
+		return false
+	}
+
+	switch x := a.(type) {
+	case *types.AttributeValueMemberS, *types.AttributeValueMemberN, *types.AttributeValueMemberB:
+		cmp, ok := CompareValues(a, b)
+		return ok && cmp == 0
+	case *types.AttributeValueMemberBOOL:
+		return x.Value == b.(*types.AttributeValueMemberBOOL).Value
+	case *types.AttributeValueMemberNULL:
+		return true
+	case *types.AttributeValueMemberSS:
+		return sameSet(setElements(a), setElements(b))
+	case *types.AttributeValueMemberNS:
+		return sameSet(setElements(a), setElements(b))
+	case *types.AttributeValueMemberBS:
+		return sameSet(setElements(a), setElements(b))
+	case *types.AttributeValueMemberL:
+		y := b.(*types.AttributeValueMemberL)
+		if len(x.Value) != len(y.Value) {
+			return false
+		}
+
+		for i := range x.Value {
+			if !Equal(x.Value[i], y.Value[i]) {
+				return false
+			}
+		}
+
+		return true
+	case *types.AttributeValueMemberM:
+		return EqualItems(x.Value, b.(*types.AttributeValueMemberM).Value)
+	}
+
+	return false
+}
+
+// EqualItems checks whether two items are equal.
+func EqualItems(a, b map[string]types.AttributeValue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k, v := range a {
+		w, ok := b[k]
+		if !ok || !Equal(v, w) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setElements returns the elements of a set as scalar values.
+func setElements(av types.AttributeValue) []types.AttributeValue {
+	var elements []types.AttributeValue
+
+	switch s := av.(type) {
+	case *types.AttributeValueMemberSS:
+		for _, v := range s.Value {
+			elements = append(elements, &types.AttributeValueMemberS{Value: v})
+		}
+	case *types.AttributeValueMemberNS:
+		for _, v := range s.Value {
+			elements = append(elements, &types.AttributeValueMemberN{Value: v})
+		}
+	case *types.AttributeValueMemberBS:
+		for _, v := range s.Value {
+			elements = append(elements, &types.AttributeValueMemberB{Value: v})
+		}
+	}
+
+	return elements
+}
+
+func sameSet(a, b []types.AttributeValue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, x := range a {
+		if !containsValue(b, x) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsValue(values []types.AttributeValue, v types.AttributeValue) bool {
+	for _, x := range values {
+		if Equal(x, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Copy deep copies a value.
+func Copy(av types.AttributeValue) types.AttributeValue {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: v.Value}
+	case *types.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: v.Value}
+	case *types.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: append([]byte{}, v.Value...)}
+	case *types.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: append([]string{}, v.Value...)}
+	case *types.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: append([]string{}, v.Value...)}
+	case *types.AttributeValueMemberBS:
+		bs := make([][]byte, 0, len(v.Value))
+		for _, b := range v.Value {
+			bs = append(bs, append([]byte{}, b...))
+		}
+		return &types.AttributeValueMemberBS{Value: bs}
+	case *types.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: v.Value}
+	case *types.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: v.Value}
+	case *types.AttributeValueMemberL:
+		l := make([]types.AttributeValue, 0, len(v.Value))
+		for _, e := range v.Value {
+			l = append(l, Copy(e))
+		}
+		return &types.AttributeValueMemberL{Value: l}
+	case *types.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: CopyItem(v.Value)}
+	}
+
+	return av
+}
+
+// CopyItem deep copies an item.
+func CopyItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+
+	c := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		c[k] = Copy(v)
+	}
+
+	return c
+}
@@ -0,0 +1,221 @@
+package mocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AhmedBenCharrada/awsgo/internal/ddbjson"
+	"github.com/AhmedBenCharrada/awsgo/internal/expr"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// TestingT the subset of testing.T reporting the input mismatches.
+type TestingT interface {
+	Logf(format string, args ...interface{})
+}
+
+// MatchInput returns a function comparing a DynamoClient input (e.g. *dynamodb.QueryInput) to want semantically,
+// to use with mock.MatchedBy:
+//
+//	client.On("Query", mock.Anything, mock.MatchedBy(mocks.MatchInput(t, want))).Return(out, nil)
+//
+// The expression placeholders are resolved, so inputs only differing by the placeholder naming, the expression
+// spacing or the map ordering match. The mismatches are logged to t as a diff, unless t is nil.
+func MatchInput[In any](t TestingT, want In) func(In) bool {
+	return func(got In) bool {
+		diff := InputDiff(want, got)
+		if diff != "" && t != nil {
+			t.Logf("%T mismatch (-want +got):\n%s", got, diff)
+		}
+
+		return diff == ""
+	}
+}
+
+// InputDiff returns a unified diff between the canonical forms of two DynamoClient inputs, empty if they match.
+func InputDiff(want, got interface{}) string {
+	wantDoc, gotDoc := canonical(want), canonical(got)
+	if wantDoc == gotDoc {
+		return ""
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(wantDoc),
+		B:        difflib.SplitLines(gotDoc),
+		FromFile: "want",
+		ToFile:   "got",
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Sprintf("want:\n%s\ngot:\n%s", wantDoc, gotDoc)
+	}
+
+	return diff
+}
+
+// canonical renders an input as indented DynamoDB JSON with sorted members and resolved expressions.
+func canonical(input interface{}) string {
+	data, err := ddbjson.Marshal(input)
+	if err != nil {
+		return fmt.Sprintf("%#v", input)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return string(data)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(resolve(doc)); err != nil {
+		return fmt.Sprintf("%#v", input)
+	}
+
+	return buf.String()
+}
+
+// resolve substitutes the expression placeholders of the input objects. The objects having an invalid expression
+// or an undefined placeholder are kept as is.
+func resolve(doc interface{}) interface{} {
+	switch doc := doc.(type) {
+	case []interface{}:
+		for i, elem := range doc {
+			doc[i] = resolve(elem)
+		}
+		return doc
+
+	case map[string]interface{}:
+		for name, value := range doc {
+			if name != "ExpressionAttributeNames" && name != "ExpressionAttributeValues" {
+				doc[name] = resolve(value)
+			}
+		}
+
+		env, ok := newEnv(doc)
+		if !ok {
+			return doc
+		}
+
+		resolved := make(map[string]interface{}, len(doc))
+		for name, value := range doc {
+			s, isString := value.(string)
+			if !isString || !isExpression(name) {
+				resolved[name] = value
+				continue
+			}
+
+			expression, err := resolveExpression(name, s, env)
+			if err != nil {
+				return doc
+			}
+			resolved[name] = expression
+		}
+
+		delete(resolved, "ExpressionAttributeNames")
+		delete(resolved, "ExpressionAttributeValues")
+
+		return resolved
+	}
+
+	return doc
+}
+
+func isExpression(name string) bool {
+	switch name {
+	case "ConditionExpression", "FilterExpression", "KeyConditionExpression", "ProjectionExpression", "UpdateExpression":
+		return true
+	}
+
+	return false
+}
+
+func newEnv(doc map[string]interface{}) (expr.Env, bool) {
+	env := expr.Env{Names: map[string]string{}, Values: map[string]types.AttributeValue{}}
+
+	if names, ok := doc["ExpressionAttributeNames"].(map[string]interface{}); ok {
+		for placeholder, name := range names {
+			s, ok := name.(string)
+			if !ok {
+				return env, false
+			}
+			env.Names[placeholder] = s
+		}
+	}
+
+	if values, ok := doc["ExpressionAttributeValues"].(map[string]interface{}); ok {
+		for placeholder, value := range values {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return env, false
+			}
+
+			av, err := ddbjson.UnmarshalAttributeValue(data)
+			if err != nil {
+				return env, false
+			}
+			env.Values[placeholder] = av
+		}
+	}
+
+	return env, true
+}
+
+// resolveExpression returns the canonical form of an expression, the values being rendered in DynamoDB JSON.
+func resolveExpression(kind, s string, env expr.Env) (string, error) {
+	switch kind {
+	case "ProjectionExpression":
+		paths, err := expr.ParseProjection(s)
+		if err != nil {
+			return "", err
+		}
+
+		if paths, err = expr.SubstitutePaths(paths, env); err != nil {
+			return "", err
+		}
+
+		names := make([]string, 0, len(paths))
+		for _, p := range paths {
+			names = append(names, p.String())
+		}
+		return strings.Join(names, ", "), nil
+
+	case "UpdateExpression":
+		u, err := expr.ParseUpdate(s)
+		if err != nil {
+			return "", err
+		}
+
+		if u, err = expr.SubstituteUpdate(u, env, renderValue); err != nil {
+			return "", err
+		}
+		return u.String(), nil
+	}
+
+	c, err := expr.ParseCondition(s)
+	if err != nil {
+		return "", err
+	}
+
+	if c, err = expr.SubstituteCondition(c, env, renderValue); err != nil {
+		return "", err
+	}
+	return c.String(), nil
+}
+
+func renderValue(av types.AttributeValue) string {
+	data, err := ddbjson.MarshalAttributeValue(av)
+	if err != nil {
+		return fmt.Sprintf("%#v", av)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,136 @@
+package mocks_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	dy "github.com/AhmedBenCharrada/awsgo/dynamodb"
+	"github.com/AhmedBenCharrada/awsgo/mocks"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID   string `dynamodbav:"id"`
+	Name string `dynamodbav:"name"`
+}
+
+func (u user) IsEmpty() bool {
+	return u.ID == ""
+}
+
+type logger struct {
+	logs []string
+}
+
+func (l *logger) Logf(format string, args ...interface{}) {
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+}
+
+func TestInputDiff(t *testing.T) {
+	want := &dynamodb.QueryInput{
+		TableName:                aws.String("users"),
+		KeyConditionExpression:   aws.String("id = :id"),
+		FilterExpression:         aws.String("(#n = :name) AND (age > :age)"),
+		ProjectionExpression:     aws.String("id, #n"),
+		ExpressionAttributeNames: map[string]string{"#n": "name"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":id":   &types.AttributeValueMemberS{Value: "u1"},
+			":name": &types.AttributeValueMemberS{Value: "john"},
+			":age":  &types.AttributeValueMemberN{Value: "18"},
+		},
+	}
+
+	got := &dynamodb.QueryInput{
+		TableName:                aws.String("users"),
+		KeyConditionExpression:   aws.String("#0 = :0"),
+		FilterExpression:         aws.String("#1 = :1 AND #2 > :2"),
+		ProjectionExpression:     aws.String("#0,#1"),
+		ExpressionAttributeNames: map[string]string{"#0": "id", "#1": "name", "#2": "age"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":0": &types.AttributeValueMemberS{Value: "u1"},
+			":1": &types.AttributeValueMemberS{Value: "john"},
+			":2": &types.AttributeValueMemberN{Value: "18"},
+		},
+	}
+
+	assert.Empty(t, mocks.InputDiff(want, got))
+
+	got.ExpressionAttributeValues[":2"] = &types.AttributeValueMemberN{Value: "21"}
+	diff := mocks.InputDiff(want, got)
+	assert.Contains(t, diff, `-  "FilterExpression": "(name = {\"S\":\"john\"} AND age > {\"N\":\"18\"})",`)
+	assert.Contains(t, diff, `+  "FilterExpression": "(name = {\"S\":\"john\"} AND age > {\"N\":\"21\"})",`)
+
+	// the undefined placeholders are compared as is
+	got.ExpressionAttributeNames = nil
+	diff = mocks.InputDiff(want, got)
+	assert.Contains(t, diff, `+  "FilterExpression": "#1 = :1 AND #2 > :2",`)
+}
+
+func TestInputDiff_Update(t *testing.T) {
+	want := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("users"),
+		Key:                       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "u1"}},
+		UpdateExpression:          aws.String("SET name = :name REMOVE age"),
+		ConditionExpression:       aws.String("attribute_exists(id)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":name": &types.AttributeValueMemberS{Value: "john"}},
+	}
+
+	got := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("users"),
+		Key:                       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "u1"}},
+		UpdateExpression:          aws.String("set #0 = :0 remove #1"),
+		ConditionExpression:       aws.String("attribute_exists (#2)"),
+		ExpressionAttributeNames:  map[string]string{"#0": "name", "#1": "age", "#2": "id"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":0": &types.AttributeValueMemberS{Value: "john"}},
+	}
+
+	assert.Empty(t, mocks.InputDiff(want, got))
+
+	got.Key["id"] = &types.AttributeValueMemberS{Value: "u2"}
+	assert.NotEmpty(t, mocks.InputDiff(want, got))
+}
+
+func TestMatchInput(t *testing.T) {
+	client := mocks.NewDynamoClient(t)
+	log := &logger{}
+
+	want := &dynamodb.ScanInput{
+		TableName:                 aws.String("users"),
+		Limit:                     aws.Int32(10),
+		FilterExpression:          aws.String("name = :name"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":name": &types.AttributeValueMemberS{Value: "john"}},
+	}
+
+	client.On("Scan", mock.Anything, mock.MatchedBy(mocks.MatchInput(log, want))).Return(&dynamodb.ScanOutput{
+		Items: []map[string]types.AttributeValue{{
+			"id":   &types.AttributeValueMemberS{Value: "u1"},
+			"name": &types.AttributeValueMemberS{Value: "john"},
+		}},
+	}, nil)
+
+	db := dy.NewClient[user](client, dy.DBConfig{
+		TableInfo: dy.TableInfo{
+			TableName:  "users",
+			PrimaryKey: dy.DBPrimaryKeyNames{PartitionKey: dy.DynamoKeyMetadata{Name: "id", Type: dy.String}},
+		},
+	})
+
+	page, err := db.Find(context.Background(), dy.Request{Size: 10, Conditions: []dy.Criteria{*dy.NewCriteria().And("name", "john", dy.EQUAL)}})
+	require.NoError(t, err)
+	assert.Equal(t, []user{{ID: "u1", Name: "john"}}, page.Items)
+	assert.Empty(t, log.logs)
+
+	match := mocks.MatchInput(log, want)
+	assert.False(t, match(&dynamodb.ScanInput{TableName: aws.String("other")}))
+	if assert.Len(t, log.logs, 1) {
+		assert.Contains(t, log.logs[0], "*dynamodb.ScanInput mismatch (-want +got)")
+		assert.Contains(t, log.logs[0], `+  "TableName": "other"`)
+	}
+}